@@ -0,0 +1,268 @@
+// Command burn-tui is a terminal client for playing Burn on the game server over WebSocket.
+// It redraws the duel with ANSI escape codes after each update and reads commands from stdin:
+//
+//	g <n>   play hand card n with Gain
+//	i <n>   play hand card n with Inflict
+//	c <n>   play hand card n with its Continuous effect
+//	e       end turn
+//	q       quit
+//
+// Create a duel (optionally against a server bot) or join one:
+//
+//	go run ./cmd/burn-tui -player alice -opponent bob -bot GREEDY
+//	go run ./cmd/burn-tui -player bob -duel <duel ID>
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
+	"github.com/daominah/turn_based_game/internal/driver/httpsvr"
+	"github.com/daominah/turn_based_game/internal/driver/wsclient"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// logLines is the number of last duel log entries shown
+const logLines = 10
+
+// ANSI escape codes
+const (
+	clearScreen = "\033[H\033[2J"
+	bold        = "\033[1m"
+	red         = "\033[31m"
+	green       = "\033[32m"
+	yellow      = "\033[33m"
+	reset       = "\033[0m"
+)
+
+func main() {
+	url := flag.String("url", "ws://localhost:11995/ws", "WebSocket URL of the game server")
+	player := flag.String("player", "", "your player ID (required)")
+	opponent := flag.String("opponent", "", "opponent player ID, to create a duel")
+	bot := flag.String("bot", "", "let the server play the opponent: RANDOM, GREEDY or MCTS")
+	duelID := flag.String("duel", "", "duel ID to join, instead of creating a duel")
+	flag.Parse()
+	if *player == "" || (*duelID == "" && *opponent == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	ui := &terminalUI{out: os.Stdout, player: *player}
+	client, err := wsclient.Dial(ctx, *url, wsclient.Options{Reconnect: true, OnMessage: ui.handle})
+	if err != nil {
+		log.Fatalf("error Dial: %v", err)
+	}
+	defer client.Close()
+
+	if *duelID != "" {
+		err = client.JoinDuel(ctx, card_game_burn.GameName, *duelID, *player)
+	} else {
+		var bots map[string]string
+		if *bot != "" {
+			bots = map[string]string{*opponent: *bot}
+		}
+		err = client.CreateDuel(ctx, card_game_burn.GameName, []string{*player, *opponent}, bots)
+	}
+	if err != nil {
+		log.Fatalf("error start duel: %v", err)
+	}
+
+	commands := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			commands <- scanner.Text()
+		}
+		close(commands)
+	}()
+	for {
+		select {
+		case <-client.Done():
+			if err := client.Err(); err != nil {
+				log.Fatalf("connection lost: %v", err)
+			}
+			return
+		case line, ok := <-commands:
+			if !ok {
+				return
+			}
+			action, quit, err := ui.parseCommand(line)
+			if quit {
+				return
+			}
+			if err != nil {
+				ui.setStatus(err.Error())
+				continue
+			}
+			if err := client.Act(ctx, action); err != nil {
+				ui.setStatus(fmt.Sprintf("error send action: %v", err))
+			}
+		}
+	}
+}
+
+// terminalUI keeps the last duel state and redraws it
+type terminalUI struct {
+	out    io.Writer
+	player string
+
+	mu     sync.Mutex
+	duel   *model.SerializableDuel
+	state  model.BurnGameState
+	status string // last error or hint, shown under the duel
+}
+
+// handle is the client's OnMessage callback
+func (ui *terminalUI) handle(msg httpsvr.ServerMessage) {
+	ui.mu.Lock()
+	switch msg.Type {
+	case httpsvr.MessageTypeStateUpdate:
+		state, err := wsclient.DecodeGameState[model.BurnGameState](msg)
+		if err != nil {
+			ui.status = err.Error()
+			break
+		}
+		ui.duel, ui.state, ui.status = msg.Duel, state, ""
+	case httpsvr.MessageTypeError:
+		ui.status = "server: " + msg.Error
+	}
+	ui.mu.Unlock()
+	ui.render()
+}
+
+func (ui *terminalUI) setStatus(status string) {
+	ui.mu.Lock()
+	ui.status = status
+	ui.mu.Unlock()
+	ui.render()
+}
+
+// parseCommand converts a command line to an action, quit is true for "q"
+func (ui *terminalUI) parseCommand(line string) (action model.ActionData, quit bool, err error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return action, false, fmt.Errorf("commands: g|i|c <card number>, e, q")
+	}
+	options := map[string]card_game_burn.PlayCardOption{
+		"g": card_game_burn.PlayCardOptionGain,
+		"i": card_game_burn.PlayCardOptionInflict,
+		"c": card_game_burn.PlayCardOptionContinuous,
+	}
+	switch command := strings.ToLower(fields[0]); command {
+	case "q":
+		return action, true, nil
+	case "e":
+		return wsclient.EndTurn(), false, nil
+	case "g", "i", "c":
+		if len(fields) != 2 {
+			return action, false, fmt.Errorf("usage: %s <card number>", command)
+		}
+		n, err := strconv.Atoi(fields[1])
+		ui.mu.Lock()
+		hand := ui.state.Players[ui.player].Hand
+		ui.mu.Unlock()
+		if err != nil || n < 1 || n > len(hand) {
+			return action, false, fmt.Errorf("card number must be 1 to %d", len(hand))
+		}
+		return wsclient.PlayCard(hand[n-1].UniqueCardID, string(options[command])), false, nil
+	default:
+		return action, false, fmt.Errorf("unknown command %q, commands: g|i|c <card number>, e, q", command)
+	}
+}
+
+func (ui *terminalUI) render() {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	if ui.duel == nil {
+		b.WriteString("waiting for the duel...\n")
+		ui.writeStatus(&b)
+		fmt.Fprint(ui.out, b.String())
+		return
+	}
+	duel := ui.duel
+	fmt.Fprintf(&b, "%sBurn%s duel %s, turn %d\n", bold, reset, duel.ID, duel.Turn)
+	switch {
+	case duel.Winner == ui.player:
+		fmt.Fprintf(&b, "%sYou win!%s %s\n", green, reset, duel.EndReason)
+	case duel.Winner != "":
+		fmt.Fprintf(&b, "%s%s wins.%s %s\n", red, duel.Winner, reset, duel.EndReason)
+	case duel.TurnPlayer == ui.player:
+		fmt.Fprintf(&b, "%sYour turn%s\n", yellow, reset)
+	default:
+		fmt.Fprintf(&b, "%s's turn\n", duel.TurnPlayer)
+	}
+
+	// you last, closest to your hand
+	players := make([]string, 0, len(duel.Players))
+	for _, pid := range duel.Players {
+		if pid != ui.player {
+			players = append(players, pid)
+		}
+	}
+	players = append(players, ui.player)
+	for _, pid := range players {
+		ps := ui.state.Players[pid]
+		name := pid
+		if pid == ui.player {
+			name += " (you)"
+		}
+		fmt.Fprintf(&b, "\n%s%s%s  LP %s%.0f%s  hand %d  deck %d  graveyard %d\n",
+			bold, name, reset, bold, ps.LifePoint, reset, ps.HandSize, ps.DeckSize, len(ps.Graveyard))
+		for _, card := range ps.Field {
+			fmt.Fprintf(&b, "  field: %s %.0f, %d turn(s) left\n",
+				card.Continuous.Type, card.Continuous.Amount, card.TurnsLeft)
+		}
+	}
+
+	b.WriteString("\nYour hand:\n")
+	for i, card := range ui.state.Players[ui.player].Hand {
+		fmt.Fprintf(&b, "  %d. gain %4.0f | inflict %4.0f", i+1, card.Gain, card.Inflict)
+		if card.Continuous != nil {
+			fmt.Fprintf(&b, " | continuous %s %.0f for %d turns",
+				card.Continuous.Type, card.Continuous.Amount, card.Continuous.Duration)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\nLog:\n")
+	start := max(0, len(duel.ActionLog)-logLines)
+	for _, entry := range duel.ActionLog[start:] {
+		fmt.Fprintf(&b, "  #%d %s %s%s\n", entry.Seq, entry.PlayerID, entry.Action, formatLogData(entry.Data))
+	}
+	ui.writeStatus(&b)
+	b.WriteString("\ncommand (g|i|c <card number>, e to end turn, q to quit): ")
+	fmt.Fprint(ui.out, b.String())
+}
+
+func (ui *terminalUI) writeStatus(b *strings.Builder) {
+	if ui.status != "" {
+		fmt.Fprintf(b, "\n%s%s%s\n", red, ui.status, reset)
+	}
+}
+
+// formatLogData formats a log entry data as " key=value ...", sorted by key
+func formatLogData(data map[string]interface{}) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, data[k])
+	}
+	return b.String()
+}
@@ -6,7 +6,12 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/daominah/turn_based_game/internal/core/battleship"
 	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
+	"github.com/daominah/turn_based_game/internal/core/chess"
+	"github.com/daominah/turn_based_game/internal/core/connect_four"
+	"github.com/daominah/turn_based_game/internal/core/rock_paper_scissors"
+	"github.com/daominah/turn_based_game/internal/core/spades"
 	"github.com/daominah/turn_based_game/internal/core/turnbased"
 	"github.com/daominah/turn_based_game/internal/driver/httpsvr"
 )
@@ -20,7 +25,12 @@ func main() {
 	// init DuelsManager for each game,
 	// the centralized duelsManagers is read-only after this point
 	duelsManagers := map[string]turnbased.DuelsManager{
-		card_game_burn.GameName: turnbased.NewInMemoryDuelsManager(),
+		card_game_burn.GameName:      turnbased.NewInMemoryDuelsManager(),
+		connect_four.GameName:        turnbased.NewInMemoryDuelsManager(),
+		chess.GameName:               turnbased.NewInMemoryDuelsManager(),
+		battleship.GameName:          turnbased.NewInMemoryDuelsManager(),
+		spades.GameName:              turnbased.NewInMemoryDuelsManager(),
+		rock_paper_scissors.GameName: turnbased.NewInMemoryDuelsManager(),
 		// Add more games here as needed
 	}
 
@@ -28,11 +38,13 @@ func main() {
 	if err != nil {
 		log.Fatalf("error NewHandlerGUI: %v", err)
 	}
-	apiHandler := httpsvr.NewHandlerAPI(duelsManagers)
 
 	// Setup WebSocket handler
 	connectionMgr := httpsvr.NewConnectionManager()
 	wsHandler := httpsvr.NewWebSocketHandler(duelsManagers, connectionMgr)
+	// the API shares the WebSocket processors and connections, so its actions are fanned out
+	// to WebSocket clients, and its SSE and long-poll clients get the WebSocket actions
+	apiHandler := httpsvr.NewHandlerAPI(duelsManagers, wsHandler.ActionProcessors(), connectionMgr)
 
 	mux := http.NewServeMux()
 	mux.Handle("/api/", apiHandler)
@@ -0,0 +1,373 @@
+// Command simulate runs many bot-vs-bot Burn duels in-process and reports statistics,
+// e.g. how much the coin toss winner (the player of turn 1) is advantaged.
+// Duels are reproducible: the same flags give the same report.
+//
+// Usage:
+//
+//	go run ./cmd/simulate -n 10000 -bot1 GREEDY -bot2 GREEDY -format text
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/daominah/turn_based_game/internal/core/ai"
+	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// the two seats of every simulated duel, bot1 plays player1 and bot2 plays player2
+const (
+	player1 turnbased.PlayerID = "BOT1"
+	player2 turnbased.PlayerID = "BOT2"
+)
+
+// lpBucketSize is the width of the final life points histogram buckets
+const lpBucketSize = 1000
+
+func main() {
+	n := flag.Int("n", 1000, "number of duels to simulate")
+	seed := flag.Uint64("seed", 1, "base seed, each duel and bot seed is derived from it")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of duels simulated in parallel")
+	bot1 := flag.String("bot1", string(card_game_burn.BotKindGreedy), "kind of the first bot: RANDOM, GREEDY or MCTS")
+	bot2 := flag.String("bot2", string(card_game_burn.BotKindGreedy), "kind of the second bot: RANDOM, GREEDY or MCTS")
+	mctsIterations := flag.Int("mcts-iterations", 200, "playouts per action of MCTS bots")
+	format := flag.String("format", "text", "report format: text, csv or json")
+	flag.Parse()
+
+	if *n <= 0 || *workers <= 0 {
+		log.Fatalf("n and workers must be positive")
+	}
+	writeReport, ok := reportWriters[*format]
+	if !ok {
+		log.Fatalf("unknown format %q, must be text, csv or json", *format)
+	}
+	kinds := map[turnbased.PlayerID]card_game_burn.BotKind{
+		player1: card_game_burn.BotKind(*bot1),
+		player2: card_game_burn.BotKind(*bot2),
+	}
+	sim := simulator{kinds: kinds, mctsIterations: *mctsIterations, seed: *seed}
+	// fail fast on a bad bot kind instead of in every duel
+	if _, err := sim.newBots(0); err != nil {
+		log.Fatalf("error newBots: %v", err)
+	}
+
+	results := make([]duelResult, *n)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result, err := sim.run(i)
+				if err != nil {
+					errOnce.Do(func() { firstErr = fmt.Errorf("duel %d: %w", i, err) })
+					continue
+				}
+				results[i] = result
+			}
+		}()
+	}
+	for i := 0; i < *n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	if firstErr != nil {
+		log.Fatalf("error simulate: %v", firstErr)
+	}
+
+	if err := writeReport(summarize(results, kinds), os.Stdout); err != nil {
+		log.Fatalf("error write report: %v", err)
+	}
+}
+
+// reportWriters writes a Report in each format of the -format flag
+var reportWriters = map[string]func(r Report, w io.Writer) error{
+	"text": Report.writeText,
+	"csv":  Report.writeCSV,
+	"json": Report.writeJSON,
+}
+
+// simulator creates the duels and bots, all seeds derive from seed and the duel index
+type simulator struct {
+	kinds          map[turnbased.PlayerID]card_game_burn.BotKind
+	mctsIterations int
+	seed           uint64
+}
+
+// deriveSeed mixes the base seed with a duel index and a stream number (SplitMix64),
+// so that nearby indexes give unrelated seeds
+func (s simulator) deriveSeed(index int, stream uint64) uint64 {
+	z := s.seed + uint64(index)*0x9E3779B97F4A7C15 + stream*0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+func (s simulator) newBots(index int) (map[turnbased.PlayerID]card_game_burn.Bot, error) {
+	bots := make(map[turnbased.PlayerID]card_game_burn.Bot)
+	for stream, player := range []turnbased.PlayerID{player1, player2} {
+		botSeed := s.deriveSeed(index, uint64(stream)+1)
+		var bot card_game_burn.Bot
+		var err error
+		if s.kinds[player] == ai.BotKindMCTS {
+			bot, err = ai.NewBurnBot(ai.Config{Iterations: s.mctsIterations, Seed: botSeed})
+		} else {
+			bot, err = card_game_burn.NewBotWithSeed(s.kinds[player], botSeed)
+		}
+		if err != nil {
+			return nil, err
+		}
+		bots[player] = bot
+	}
+	return bots, nil
+}
+
+// duelResult is the outcome of one simulated duel
+type duelResult struct {
+	FirstPlayer turnbased.PlayerID // the coin toss winner, who plays turn 1
+	Winner      turnbased.PlayerID // a player ID or "DRAW"
+	EndReason   string
+	Turns       int
+	LifePoints  map[turnbased.PlayerID]float64 // final life points
+}
+
+// run simulates the duel of the index to its end
+func (s simulator) run(index int) (duelResult, error) {
+	bots, err := s.newBots(index)
+	if err != nil {
+		return duelResult{}, err
+	}
+	duel := card_game_burn.NewBurnDuelWithSeed(
+		[]turnbased.PlayerID{player1, player2}, s.deriveSeed(index, 0))
+	result := duelResult{FirstPlayer: duel.Duel.TurnPlayer}
+	if err := duel.PlayBotTurns(bots); err != nil {
+		return duelResult{}, err
+	}
+	if duel.Duel.State != turnbased.DuelStateEnd {
+		return duelResult{}, fmt.Errorf("duel did not end")
+	}
+	result.Winner = duel.Duel.Winner
+	result.EndReason = duel.Duel.EndReason
+	result.Turns = duel.Duel.Turn
+	result.LifePoints = map[turnbased.PlayerID]float64{
+		player1: duel.Players[player1].LifePoint,
+		player2: duel.Players[player2].LifePoint,
+	}
+	return result, nil
+}
+
+// Report is the statistics of all simulated duels
+type Report struct {
+	Duels int               `json:"duels"`
+	Bots  map[string]string `json:"bots"` // player ID -> bot kind
+
+	FirstPlayerWins  int     `json:"first_player_wins"`
+	SecondPlayerWins int     `json:"second_player_wins"`
+	Draws            int     `json:"draws"`
+	FirstPlayerRate  float64 `json:"first_player_win_rate"`
+	SecondPlayerRate float64 `json:"second_player_win_rate"`
+	// FirstPlayerRateCI95 is the half width of the 95% confidence interval of FirstPlayerRate
+	FirstPlayerRateCI95 float64 `json:"first_player_win_rate_ci95"`
+
+	WinsByPlayer map[string]int `json:"wins_by_player"`
+
+	AverageTurns float64 `json:"average_turns"`
+	MinTurns     int     `json:"min_turns"`
+	MaxTurns     int     `json:"max_turns"`
+
+	EndReasons   map[string]int `json:"end_reasons"`
+	DeckOutRate  float64        `json:"deck_out_rate"`
+	WinnerLP     LPStats        `json:"winner_lp"`
+	LoserLP      LPStats        `json:"loser_lp"`
+	LPBucketSize int            `json:"lp_bucket_size"`
+}
+
+// LPStats is the distribution of final life points,
+// Histogram maps a bucket lower bound (multiple of Report.LPBucketSize) to the number of duels
+type LPStats struct {
+	Min       float64     `json:"min"`
+	Average   float64     `json:"average"`
+	Max       float64     `json:"max"`
+	Histogram map[int]int `json:"histogram"`
+}
+
+func summarize(results []duelResult, kinds map[turnbased.PlayerID]card_game_burn.BotKind) Report {
+	report := Report{
+		Duels:        len(results),
+		Bots:         make(map[string]string),
+		WinsByPlayer: make(map[string]int),
+		EndReasons:   make(map[string]int),
+		MinTurns:     math.MaxInt,
+		LPBucketSize: lpBucketSize,
+	}
+	for player, kind := range kinds {
+		report.Bots[string(player)] = string(kind)
+		report.WinsByPlayer[string(player)] = 0
+	}
+	var winnerLPs, loserLPs []float64
+	totalTurns := 0
+	for _, r := range results {
+		totalTurns += r.Turns
+		report.MinTurns = min(report.MinTurns, r.Turns)
+		report.MaxTurns = max(report.MaxTurns, r.Turns)
+		report.EndReasons[r.EndReason]++
+		if r.Winner == "DRAW" {
+			report.Draws++
+			continue
+		}
+		report.WinsByPlayer[string(r.Winner)]++
+		if r.Winner == r.FirstPlayer {
+			report.FirstPlayerWins++
+		} else {
+			report.SecondPlayerWins++
+		}
+		for player, lp := range r.LifePoints {
+			if player == r.Winner {
+				winnerLPs = append(winnerLPs, lp)
+			} else {
+				loserLPs = append(loserLPs, lp)
+			}
+		}
+	}
+	if report.Duels > 0 {
+		n := float64(report.Duels)
+		report.FirstPlayerRate = float64(report.FirstPlayerWins) / n
+		report.SecondPlayerRate = float64(report.SecondPlayerWins) / n
+		p := report.FirstPlayerRate
+		report.FirstPlayerRateCI95 = 1.96 * math.Sqrt(p*(1-p)/n)
+		report.AverageTurns = float64(totalTurns) / n
+		report.DeckOutRate = float64(report.EndReasons[card_game_burn.EndReasonDeckOut]) / n
+	} else {
+		report.MinTurns = 0
+	}
+	report.WinnerLP = newLPStats(winnerLPs)
+	report.LoserLP = newLPStats(loserLPs)
+	return report
+}
+
+func newLPStats(lps []float64) LPStats {
+	stats := LPStats{Histogram: make(map[int]int)}
+	if len(lps) == 0 {
+		return stats
+	}
+	stats.Min, stats.Max = lps[0], lps[0]
+	sum := 0.0
+	for _, lp := range lps {
+		sum += lp
+		stats.Min = math.Min(stats.Min, lp)
+		stats.Max = math.Max(stats.Max, lp)
+		bucket := int(math.Floor(lp/lpBucketSize)) * lpBucketSize
+		stats.Histogram[bucket]++
+	}
+	stats.Average = sum / float64(len(lps))
+	return stats
+}
+
+func (r Report) writeText(w io.Writer) error {
+	pct := func(rate float64) string { return fmt.Sprintf("%.1f%%", 100*rate) }
+	lines := []string{
+		fmt.Sprintf("duels: %d, %s: %s, %s: %s", r.Duels,
+			player1, r.Bots[string(player1)], player2, r.Bots[string(player2)]),
+		fmt.Sprintf("first player wins: %d (%s ± %s)", r.FirstPlayerWins,
+			pct(r.FirstPlayerRate), pct(r.FirstPlayerRateCI95)),
+		fmt.Sprintf("second player wins: %d (%s)", r.SecondPlayerWins, pct(r.SecondPlayerRate)),
+		fmt.Sprintf("draws: %d", r.Draws),
+		fmt.Sprintf("wins by player: %s %d, %s %d",
+			player1, r.WinsByPlayer[string(player1)], player2, r.WinsByPlayer[string(player2)]),
+		fmt.Sprintf("turns: average %.2f, min %d, max %d", r.AverageTurns, r.MinTurns, r.MaxTurns),
+		fmt.Sprintf("deck out: %s", pct(r.DeckOutRate)),
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	for _, s := range []struct {
+		name  string
+		stats LPStats
+	}{{"winner", r.WinnerLP}, {"loser", r.LoserLP}} {
+		_, err := fmt.Fprintf(w, "%s LP: min %.0f, average %.0f, max %.0f\n",
+			s.name, s.stats.Min, s.stats.Average, s.stats.Max)
+		if err != nil {
+			return err
+		}
+		for _, bucket := range sortedKeys(s.stats.Histogram) {
+			_, err := fmt.Fprintf(w, "  [%6d, %6d): %d\n", bucket, bucket+r.LPBucketSize, s.stats.Histogram[bucket])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeCSV writes the report as metric,value rows, histogram rows are named like "winner_lp_bucket_3000"
+func (r Report) writeCSV(w io.Writer) error {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	rows := [][]string{
+		{"metric", "value"},
+		{"duels", strconv.Itoa(r.Duels)},
+		{"bot_" + string(player1), r.Bots[string(player1)]},
+		{"bot_" + string(player2), r.Bots[string(player2)]},
+		{"first_player_wins", strconv.Itoa(r.FirstPlayerWins)},
+		{"second_player_wins", strconv.Itoa(r.SecondPlayerWins)},
+		{"draws", strconv.Itoa(r.Draws)},
+		{"first_player_win_rate", f(r.FirstPlayerRate)},
+		{"first_player_win_rate_ci95", f(r.FirstPlayerRateCI95)},
+		{"second_player_win_rate", f(r.SecondPlayerRate)},
+		{"wins_" + string(player1), strconv.Itoa(r.WinsByPlayer[string(player1)])},
+		{"wins_" + string(player2), strconv.Itoa(r.WinsByPlayer[string(player2)])},
+		{"average_turns", f(r.AverageTurns)},
+		{"min_turns", strconv.Itoa(r.MinTurns)},
+		{"max_turns", strconv.Itoa(r.MaxTurns)},
+		{"deck_out_rate", f(r.DeckOutRate)},
+	}
+	for _, s := range []struct {
+		name  string
+		stats LPStats
+	}{{"winner_lp", r.WinnerLP}, {"loser_lp", r.LoserLP}} {
+		rows = append(rows,
+			[]string{s.name + "_min", f(s.stats.Min)},
+			[]string{s.name + "_average", f(s.stats.Average)},
+			[]string{s.name + "_max", f(s.stats.Max)})
+		for _, bucket := range sortedKeys(s.stats.Histogram) {
+			rows = append(rows, []string{
+				s.name + "_bucket_" + strconv.Itoa(bucket), strconv.Itoa(s.stats.Histogram[bucket])})
+		}
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func (r Report) writeJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+func sortedKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
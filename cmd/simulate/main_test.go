@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// testResults are 4 duels: the first player wins twice, the second player once, and a draw
+var testResults = []duelResult{
+	{FirstPlayer: player1, Winner: player1, EndReason: card_game_burn.EndReasonLifePoints, Turns: 5,
+		LifePoints: map[turnbased.PlayerID]float64{player1: 3500, player2: 0}},
+	{FirstPlayer: player2, Winner: player1, EndReason: card_game_burn.EndReasonLifePoints, Turns: 7,
+		LifePoints: map[turnbased.PlayerID]float64{player1: 1200, player2: -500}},
+	{FirstPlayer: player1, Winner: "DRAW", EndReason: card_game_burn.EndReasonDeckOut, Turns: 20,
+		LifePoints: map[turnbased.PlayerID]float64{player1: 1000, player2: 1000}},
+	{FirstPlayer: player2, Winner: player2, EndReason: card_game_burn.EndReasonDeckOut, Turns: 21,
+		LifePoints: map[turnbased.PlayerID]float64{player1: 500, player2: 8000}},
+}
+
+var testKinds = map[turnbased.PlayerID]card_game_burn.BotKind{
+	player1: card_game_burn.BotKindGreedy,
+	player2: card_game_burn.BotKindRandom,
+}
+
+func TestSummarize(t *testing.T) {
+	r := summarize(testResults, testKinds)
+	if r.Duels != 4 || r.FirstPlayerWins != 2 || r.SecondPlayerWins != 1 || r.Draws != 1 {
+		t.Errorf("unexpected counts: %+v", r)
+	}
+	if r.FirstPlayerRate != 0.5 || r.SecondPlayerRate != 0.25 {
+		t.Errorf("unexpected win rates %v and %v", r.FirstPlayerRate, r.SecondPlayerRate)
+	}
+	// 1.96 * sqrt(0.5 * 0.5 / 4)
+	if math.Abs(r.FirstPlayerRateCI95-0.49) > 1e-9 {
+		t.Errorf("expected a CI95 of 0.49, got %v", r.FirstPlayerRateCI95)
+	}
+	if r.WinsByPlayer[string(player1)] != 2 || r.WinsByPlayer[string(player2)] != 1 {
+		t.Errorf("unexpected wins by player %v", r.WinsByPlayer)
+	}
+	if r.AverageTurns != 13.25 || r.MinTurns != 5 || r.MaxTurns != 21 || r.DeckOutRate != 0.5 {
+		t.Errorf("unexpected turns %v %d %d or deck out rate %v", r.AverageTurns, r.MinTurns, r.MaxTurns, r.DeckOutRate)
+	}
+	wantWinner := LPStats{Min: 1200, Average: 12700.0 / 3, Max: 8000, Histogram: map[int]int{1000: 1, 3000: 1, 8000: 1}}
+	if !reflect.DeepEqual(r.WinnerLP, wantWinner) {
+		t.Errorf("winner LP %+v, want %+v", r.WinnerLP, wantWinner)
+	}
+	// -500 is in the bucket [-1000, 0)
+	wantLoser := LPStats{Min: -500, Average: 0, Max: 500, Histogram: map[int]int{-1000: 1, 0: 2}}
+	if !reflect.DeepEqual(r.LoserLP, wantLoser) {
+		t.Errorf("loser LP %+v, want %+v", r.LoserLP, wantLoser)
+	}
+
+	empty := summarize(nil, testKinds)
+	if empty.Duels != 0 || empty.MinTurns != 0 || empty.FirstPlayerRate != 0 {
+		t.Errorf("unexpected report without duels: %+v", empty)
+	}
+}
+
+func TestReportWriters(t *testing.T) {
+	report := summarize(testResults, testKinds)
+	write := func(format string) string {
+		var out bytes.Buffer
+		if err := reportWriters[format](report, &out); err != nil {
+			t.Fatalf("error write %s: %v", format, err)
+		}
+		return out.String()
+	}
+
+	text := write("text")
+	for _, line := range []string{
+		"first player wins: 2 (50.0% ± 49.0%)",
+		"turns: average 13.25, min 5, max 21",
+		"loser LP: min -500, average 0, max 500",
+		"  [ -1000,      0): 1",
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("text report misses %q:\n%s", line, text)
+		}
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(write("csv"))).ReadAll()
+	if err != nil {
+		t.Fatalf("error read csv: %v", err)
+	}
+	metrics := make(map[string]string)
+	for _, row := range rows {
+		metrics[row[0]] = row[1]
+	}
+	for metric, want := range map[string]string{
+		"duels": "4", "bot_BOT1": "GREEDY", "first_player_win_rate": "0.5", "first_player_win_rate_ci95": "0.49",
+		"deck_out_rate": "0.5", "winner_lp_bucket_8000": "1", "loser_lp_bucket_-1000": "1",
+	} {
+		if metrics[metric] != want {
+			t.Errorf("csv %s = %q, want %q", metric, metrics[metric], want)
+		}
+	}
+
+	var decoded Report
+	if err := json.Unmarshal([]byte(write("json")), &decoded); err != nil {
+		t.Fatalf("error decode json: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, report) {
+		t.Errorf("json report %+v, want %+v", decoded, report)
+	}
+}
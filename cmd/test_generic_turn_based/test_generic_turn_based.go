@@ -4,12 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 )
 
 // This script requires the server (main.go) to be running.
-// It calls the API to play a simple generic turn-based game.
+// It calls the REST API to play a Connect Four duel: alice stacks discs in column 0
+// while bob plays column 1, so alice wins with their 4th disc.
 func main() {
 
 	baseURL := "http://localhost:11995/api"
@@ -18,39 +18,61 @@ func main() {
 	createReq := map[string]any{
 		"players": []string{"alice", "bob"},
 	}
-	duelID, err := apiPost(baseURL+"/duel", createReq)
+	created, err := apiPost(baseURL+"/duel?game=CONNECT_FOUR", createReq)
 	if err != nil {
 		fmt.Println("Error creating duel:", err)
 		return
 	}
+	duelID := created.Duel.ID
 	fmt.Println("Created duel with ID:", duelID)
 
-	// 2. Simulate a few turns (this is a placeholder, adjust to your API)
-	for i := 0; i < 3; i++ {
+	// 2. Play turns until the duel ends
+	for i := 0; ; i++ {
+		player, column := "alice", 0
+		if i%2 == 1 {
+			player, column = "bob", 1
+		}
 		turnReq := map[string]any{
-			"duel_id": duelID,
-			"action":  "next_turn",
+			"player_id": player,
+			"action":    map[string]any{"column": column},
 		}
 		resp, err := apiPost(baseURL+"/duel/"+duelID+"/action", turnReq)
 		if err != nil {
 			fmt.Println("Error performing turn:", err)
 			return
 		}
-		fmt.Printf("Turn %d response: %v\n", i+1, resp)
+		fmt.Printf("Turn %d: %s dropped in column %d\n", i+1, player, column)
+		if resp.Duel.Winner != "" {
+			fmt.Println("Winner:", resp.Duel.Winner)
+			return
+		}
 	}
+}
 
-	// 3. End the duel (placeholder)
-	// Removed: duel ends automatically based on state, no manual end request needed
+// apiResponse is the part of the server response this script uses
+type apiResponse struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+	Duel  struct {
+		ID     string `json:"id"`
+		Winner string `json:"winner"`
+	} `json:"duel"`
 }
 
-// apiPost sends a POST request with JSON and returns the response as a string.
-func apiPost(url string, data map[string]any) (string, error) {
+// apiPost sends a POST request with JSON and decodes the JSON response, an error response is returned as error.
+func apiPost(url string, data map[string]any) (apiResponse, error) {
 	body, _ := json.Marshal(data)
+	var result apiResponse
 	resp, err := http.Post(url, "application/json", bytes.NewBuffer(body))
 	if err != nil {
-		return "", err
+		return result, err
 	}
 	defer resp.Body.Close()
-	respBody, _ := io.ReadAll(resp.Body)
-	return string(respBody), nil
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return result, err
+	}
+	if result.Type == "error" {
+		return result, fmt.Errorf("%d %s", resp.StatusCode, result.Error)
+	}
+	return result, nil
 }
@@ -3,6 +3,6 @@ module github.com/daominah/turn_based_game
 go 1.23.10
 
 require (
-	github.com/coder/websocket v1.8.14 // indirect
-	github.com/mywrap/gofast v0.1.6 // indirect
+	github.com/coder/websocket v1.8.14
+	github.com/mywrap/gofast v0.1.6
 )
@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// Ensure BurnGame implements Game with hidden information, and BurnBot implements card_game_burn.Bot
+var (
+	_ Game               = (*BurnGame)(nil)
+	_ Determinizer       = (*BurnGame)(nil)
+	_ card_game_burn.Bot = (*BurnBot)(nil)
+)
+
+// BurnGame adapts a BurnDuel to Game. The search does not see the opponent's hand,
+// the deck order or the duel's RNG, see card_game_burn.BurnDuel.Determinize.
+type BurnGame struct {
+	Duel *card_game_burn.BurnDuel
+}
+
+func (g *BurnGame) Clone() Game {
+	return &BurnGame{Duel: g.Duel.Clone()}
+}
+
+func (g *BurnGame) Determinize(player turnbased.PlayerID, random *rand.Rand) Game {
+	return &BurnGame{Duel: g.Duel.Determinize(player, random)}
+}
+
+func (g *BurnGame) CurrentPlayer() turnbased.PlayerID {
+	return g.Duel.Duel.TurnPlayer
+}
+
+func (g *BurnGame) LegalActions() []any {
+	return g.Duel.LegalActions(g.CurrentPlayer())
+}
+
+func (g *BurnGame) Apply(action any) error {
+	return g.Duel.HandleActionWithPlayer(action, g.CurrentPlayer())
+}
+
+func (g *BurnGame) IsTerminal() bool {
+	return g.Duel.Duel.State == turnbased.DuelStateEnd
+}
+
+func (g *BurnGame) Winner() turnbased.PlayerID {
+	return g.Duel.Duel.Winner
+}
+
+// BotKindMCTS is the bot kind of BurnBot, besides the built-in card_game_burn bots
+const BotKindMCTS card_game_burn.BotKind = "MCTS"
+
+// BurnBot is a Burn bot choosing each action with a Monte-Carlo tree search
+type BurnBot struct {
+	mcts *MCTS
+	// TurnBudget bounds the search time of the bot in one turn, 0 for no limit.
+	// When it is spent the bot ends its turn, e.g. so that a server playing the bot
+	// while the duel is locked answers the next action in time.
+	TurnBudget time.Duration
+
+	turn  int           // the turn of the last search
+	spent time.Duration // the search time in turn
+}
+
+// NewBurnBot creates a Burn bot searching with the config
+func NewBurnBot(config Config) (*BurnBot, error) {
+	mcts, err := NewMCTS(config)
+	if err != nil {
+		return nil, err
+	}
+	return &BurnBot{mcts: mcts}, nil
+}
+
+// ChooseAction implements card_game_burn.Bot, it ends the turn if the search fails
+// or the TurnBudget is spent
+func (b *BurnBot) ChooseAction(duel *card_game_burn.BurnDuel, player turnbased.PlayerID) any {
+	if duel.Duel.Turn != b.turn {
+		b.turn, b.spent = duel.Duel.Turn, 0
+	}
+	if b.TurnBudget > 0 && b.spent >= b.TurnBudget {
+		return card_game_burn.ActionEndTurn{}
+	}
+	start := time.Now()
+	action, err := b.mcts.Search(&BurnGame{Duel: duel})
+	b.spent += time.Since(start)
+	if err != nil {
+		return card_game_burn.ActionEndTurn{}
+	}
+	return action
+}
@@ -0,0 +1,35 @@
+// Package ai plays any game through a generic interface with Monte-Carlo tree search,
+// so that each game gets a computer opponent without game-specific AI.
+// A game only needs an adapter implementing Game (see BurnGame).
+package ai
+
+import (
+	"math/rand/v2"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// Game is the view of a duel the AI needs to search it
+type Game interface {
+	// Clone returns an independent copy, applying actions to it must not change the original
+	Clone() Game
+	// CurrentPlayer returns the player who acts next
+	CurrentPlayer() turnbased.PlayerID
+	// LegalActions returns the actions the current player can take, actions must be comparable
+	LegalActions() []any
+	// Apply performs an action of the current player
+	Apply(action any) error
+	// IsTerminal returns true if the duel has ended
+	IsTerminal() bool
+	// Winner returns the winner of an ended duel, "DRAW" for a draw, empty if not ended
+	Winner() turnbased.PlayerID
+}
+
+// Determinizer is implemented by games with hidden information (e.g. the cards in the opponent's hand).
+// The search then plays each iteration on a copy sampled from what the searching player can see
+// instead of on the real state, so that it cannot use what the player does not know.
+type Determinizer interface {
+	// Determinize returns an independent copy where the information hidden from the player
+	// is sampled with random
+	Determinize(player turnbased.PlayerID, random *rand.Rand) Game
+}
@@ -0,0 +1,187 @@
+package ai
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// Default values of Config fields left zero
+const (
+	DefaultExploration       = math.Sqrt2
+	DefaultMaxRolloutActions = 1000
+)
+
+// Config of a Monte-Carlo tree search, the search stops at the first reached limit
+// of Iterations and TimeBudget, at least one of them must be set
+type Config struct {
+	Iterations int           // number of simulated playouts per search, 0 for no limit
+	TimeBudget time.Duration // time per search, 0 for no limit
+	// Exploration is the UCT constant, higher explores more, 0 means DefaultExploration
+	Exploration float64
+	// MaxRolloutActions stops a random playout that takes too long, it then counts as a draw,
+	// 0 means DefaultMaxRolloutActions
+	MaxRolloutActions int
+	// Seed makes the search reproducible for a given game state, 0 means a random seed
+	Seed uint64
+}
+
+// DefaultConfig is a search strong enough for Burn that takes at most 200ms per action
+var DefaultConfig = Config{Iterations: 2000, TimeBudget: 200 * time.Millisecond}
+
+// MCTS searches the best action with Monte-Carlo tree search (UCT):
+// select a path with the best upper confidence bound, expand one new action,
+// play randomly to the end of the duel, and credit the result along the path
+type MCTS struct {
+	config Config
+	random *rand.Rand
+}
+
+// NewMCTS creates a search with the config
+func NewMCTS(config Config) (*MCTS, error) {
+	if config.Iterations <= 0 && config.TimeBudget <= 0 {
+		return nil, fmt.Errorf("iterations or time budget required")
+	}
+	if config.Exploration == 0 {
+		config.Exploration = DefaultExploration
+	}
+	if config.MaxRolloutActions == 0 {
+		config.MaxRolloutActions = DefaultMaxRolloutActions
+	}
+	seed := config.Seed
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+	return &MCTS{config: config, random: rand.New(rand.NewPCG(seed, seed>>1))}, nil
+}
+
+// node is a state in the search tree, reached by playing action
+type node struct {
+	parent   *node
+	action   any
+	player   turnbased.PlayerID // the player who played action, rewards are from their view
+	children []*node
+	untried  []any
+	visits   int
+	reward   float64 // sum of rewards of the playouts through this node
+}
+
+// Search returns the best action for the current player of the game, the game is not changed
+func (m *MCTS) Search(game Game) (any, error) {
+	if game.IsTerminal() {
+		return nil, fmt.Errorf("game has ended")
+	}
+	root := &node{untried: game.LegalActions()}
+	if len(root.untried) == 0 {
+		return nil, fmt.Errorf("no legal action")
+	}
+	if len(root.untried) == 1 {
+		return root.untried[0], nil
+	}
+	// a game with hidden information is searched on samples of what the player can see
+	player := game.CurrentPlayer()
+	sample := game.Clone
+	if determinizer, ok := game.(Determinizer); ok {
+		sample = func() Game { return determinizer.Determinize(player, m.random) }
+	}
+	// a winning action needs no search, random playouts may rate slower wins the same
+	for _, action := range root.untried {
+		state := sample()
+		if state.Apply(action) == nil && state.IsTerminal() && state.Winner() == player {
+			return action, nil
+		}
+	}
+
+	var deadline time.Time
+	if m.config.TimeBudget > 0 {
+		deadline = time.Now().Add(m.config.TimeBudget)
+	}
+	for i := 0; m.config.Iterations <= 0 || i < m.config.Iterations; i++ {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+		if err := m.iterate(root, sample()); err != nil {
+			return nil, err
+		}
+	}
+
+	var best *node
+	for _, child := range root.children {
+		if best == nil || child.visits > best.visits {
+			best = child
+		}
+	}
+	if best == nil {
+		return root.untried[0], nil
+	}
+	return best.action, nil
+}
+
+// iterate runs one selection, expansion, playout and backpropagation on the state.
+// An action of the tree that the state rejects (e.g. a card the opponent does not hold
+// in this sample of a Determinizer) ends the selection or expansion, the playout starts there.
+func (m *MCTS) iterate(root *node, state Game) error {
+	n := root
+	for len(n.untried) == 0 && len(n.children) > 0 {
+		child := m.selectChild(n)
+		if state.Apply(child.action) != nil {
+			break
+		}
+		n = child
+	}
+
+	if len(n.untried) > 0 && !state.IsTerminal() {
+		i := m.random.IntN(len(n.untried))
+		action := n.untried[i]
+		player := state.CurrentPlayer()
+		if state.Apply(action) == nil {
+			n.untried = append(n.untried[:i], n.untried[i+1:]...)
+			child := &node{parent: n, action: action, player: player}
+			if !state.IsTerminal() {
+				child.untried = state.LegalActions()
+			}
+			n.children = append(n.children, child)
+			n = child
+		}
+	}
+
+	for i := 0; i < m.config.MaxRolloutActions && !state.IsTerminal(); i++ {
+		actions := state.LegalActions()
+		if len(actions) == 0 {
+			break
+		}
+		if err := state.Apply(actions[m.random.IntN(len(actions))]); err != nil {
+			return fmt.Errorf("rollout: %w", err)
+		}
+	}
+
+	winner := state.Winner()
+	for ; n != nil; n = n.parent {
+		n.visits++
+		switch {
+		case !state.IsTerminal() || winner == "DRAW":
+			n.reward += 0.5
+		case winner == n.player:
+			n.reward += 1
+		}
+	}
+	return nil
+}
+
+// selectChild returns the child with the best upper confidence bound
+func (m *MCTS) selectChild(n *node) *node {
+	var best *node
+	bestScore := math.Inf(-1)
+	logVisits := math.Log(float64(n.visits))
+	for _, child := range n.children {
+		score := child.reward/float64(child.visits) +
+			m.config.Exploration*math.Sqrt(logVisits/float64(child.visits))
+		if score > bestScore {
+			best, bestScore = child, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,180 @@
+package ai
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// nim is a pile of stones, players take 1 to 3 stones in turn, who takes the last stone wins.
+// Leaving a multiple of 4 stones to the opponent wins.
+type nim struct {
+	stones int
+	turn   int // index in nimPlayers
+	winner turnbased.PlayerID
+}
+
+var nimPlayers = []turnbased.PlayerID{"first", "second"}
+
+func (g *nim) Clone() Game                       { clone := *g; return &clone }
+func (g *nim) CurrentPlayer() turnbased.PlayerID { return nimPlayers[g.turn] }
+func (g *nim) IsTerminal() bool                  { return g.winner != "" }
+func (g *nim) Winner() turnbased.PlayerID        { return g.winner }
+
+func (g *nim) LegalActions() []any {
+	var actions []any
+	for take := 1; take <= 3 && take <= g.stones; take++ {
+		actions = append(actions, take)
+	}
+	return actions
+}
+
+func (g *nim) Apply(action any) error {
+	take, ok := action.(int)
+	if !ok || take < 1 || take > 3 || take > g.stones {
+		return fmt.Errorf("invalid take %v", action)
+	}
+	g.stones -= take
+	if g.stones == 0 {
+		g.winner = g.CurrentPlayer()
+	}
+	g.turn = 1 - g.turn
+	return nil
+}
+
+func TestNewMCTS(t *testing.T) {
+	if _, err := NewMCTS(Config{}); err == nil {
+		t.Error("NewMCTS should fail without iterations or time budget")
+	}
+	mcts, err := NewMCTS(Config{Iterations: 10})
+	if err != nil {
+		t.Fatalf("NewMCTS failed: %v", err)
+	}
+	if _, err := mcts.Search(&nim{stones: 0, winner: "first"}); err == nil {
+		t.Error("Search should fail on an ended game")
+	}
+}
+
+func TestMCTS_FindsWinningMove(t *testing.T) {
+	for stones, want := range map[int]int{5: 1, 6: 2, 7: 3, 10: 2} {
+		mcts, _ := NewMCTS(Config{Iterations: 3000, Seed: 1})
+		game := &nim{stones: stones}
+		action, err := mcts.Search(game)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if action != want {
+			t.Errorf("With %d stones, expected to take %d, got %v", stones, want, action)
+		}
+		if game.stones != stones {
+			t.Error("Search should not change the game")
+		}
+	}
+}
+
+func TestMCTS_PlaysImmediateWin(t *testing.T) {
+	// a single playout cannot rate the actions, the winning take is found without searching
+	mcts, _ := NewMCTS(Config{Iterations: 1, Seed: 1})
+	for i := 0; i < 10; i++ {
+		action, err := mcts.Search(&nim{stones: 3})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if action != 3 {
+			t.Fatalf("Expected to take the last 3 stones, got %v", action)
+		}
+	}
+}
+
+func TestMCTS_TimeBudget(t *testing.T) {
+	mcts, _ := NewMCTS(Config{TimeBudget: 20 * time.Millisecond})
+	start := time.Now()
+	if _, err := mcts.Search(&nim{stones: 21}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Search took %v, expected about the time budget", elapsed)
+	}
+}
+
+func TestBurnBot(t *testing.T) {
+	duel := card_game_burn.NewBurnDuel([]turnbased.PlayerID{"mcts", "greedy"})
+	// the opponent is one inflict away from losing
+	turnPlayer := duel.Duel.TurnPlayer
+	for _, ps := range duel.Players {
+		if ps.ID != turnPlayer {
+			ps.LifePoint = 100
+		}
+	}
+	bot, err := NewBurnBot(Config{Iterations: 300, Seed: 1})
+	if err != nil {
+		t.Fatalf("NewBurnBot failed: %v", err)
+	}
+	if _, ok := bot.ChooseAction(duel, turnPlayer).(card_game_burn.ActionPlayCard); !ok {
+		t.Error("Expected to play a card rather than end the turn")
+	}
+	if len(duel.Duel.ActionLog) != 0 {
+		t.Error("ChooseAction should not change the duel")
+	}
+	// any card inflicts at least 100, so the bot should win before ending its turn
+	if err := duel.PlayBotTurns(map[turnbased.PlayerID]card_game_burn.Bot{turnPlayer: bot}); err != nil {
+		t.Fatalf("PlayBotTurns failed: %v", err)
+	}
+	if duel.Duel.Winner != turnPlayer || duel.Duel.Turn != 1 {
+		t.Errorf("Expected %s to win in turn 1, got winner %q turn %d", turnPlayer, duel.Duel.Winner, duel.Duel.Turn)
+	}
+}
+
+func TestBurnBot_PlaysFullDuel(t *testing.T) {
+	duel := card_game_burn.NewBurnDuel([]turnbased.PlayerID{"mcts", "random"})
+	mctsBot, _ := NewBurnBot(Config{Iterations: 50})
+	randomBot, _ := card_game_burn.NewBot(card_game_burn.BotKindRandom)
+	bots := map[turnbased.PlayerID]card_game_burn.Bot{"mcts": mctsBot, "random": randomBot}
+	if err := duel.PlayBotTurns(bots); err != nil {
+		t.Fatalf("PlayBotTurns failed: %v", err)
+	}
+	if duel.Duel.State != turnbased.DuelStateEnd {
+		t.Errorf("Expected the duel to end, got %s", duel.Duel.State)
+	}
+}
+
+func TestBurnBot_IgnoresHiddenCards(t *testing.T) {
+	duel := card_game_burn.NewBurnDuelWithSeed([]turnbased.PlayerID{"mcts", "opponent"}, 1)
+	turnPlayer := duel.Duel.TurnPlayer
+	opponent := duel.Duel.Players[0]
+	if opponent == turnPlayer {
+		opponent = duel.Duel.Players[1]
+	}
+	// the same duel where the opponent holds other cards of their deck
+	other := duel.Clone()
+	hand, deck := other.Players[opponent].Hand, other.Players[opponent].Deck
+	for i := range hand {
+		hand[i], deck[i] = deck[i], hand[i]
+	}
+
+	for i := 0; i < 5; i++ {
+		bot, _ := NewBurnBot(Config{Iterations: 200, Seed: uint64(i + 1)})
+		otherBot, _ := NewBurnBot(Config{Iterations: 200, Seed: uint64(i + 1)})
+		if a, b := bot.ChooseAction(duel, turnPlayer), otherBot.ChooseAction(other, turnPlayer); a != b {
+			t.Errorf("Seed %d: the choice depends on the opponent's hidden cards: %v and %v", i+1, a, b)
+		}
+	}
+}
+
+func TestBurnBot_TurnBudget(t *testing.T) {
+	duel := card_game_burn.NewBurnDuelWithSeed([]turnbased.PlayerID{"mcts", "opponent"}, 1)
+	turnPlayer := duel.Duel.TurnPlayer
+	bot, _ := NewBurnBot(Config{Iterations: 50, Seed: 1})
+	bot.TurnBudget = time.Nanosecond
+	if err := duel.HandleActionWithPlayer(bot.ChooseAction(duel, turnPlayer), turnPlayer); err != nil {
+		t.Fatalf("HandleActionWithPlayer failed: %v", err)
+	}
+	if duel.Duel.TurnPlayer == turnPlayer {
+		if _, ok := bot.ChooseAction(duel, turnPlayer).(card_game_burn.ActionEndTurn); !ok {
+			t.Error("Expected the bot to end its turn once its turn budget is spent")
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package battleship
+
+import (
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// ActionPlaceFleet represents an action to place the whole fleet during setup
+type ActionPlaceFleet struct {
+	Ships []ShipPlacement
+}
+
+// ActionFire represents an action to shoot at a cell of the opponent's board
+type ActionFire struct {
+	Row    int
+	Column int
+}
+
+// Implement turnbased.Action interface for ActionPlaceFleet
+func (a ActionPlaceFleet) GameName() string {
+	return GameName
+}
+
+func (a ActionPlaceFleet) DuelID() turnbased.DuelID {
+	// DuelID will be set by the handler from the message context
+	return ""
+}
+
+func (a ActionPlaceFleet) PlayerID() turnbased.PlayerID {
+	// PlayerID will be set by the handler from the message context
+	return ""
+}
+
+// Implement turnbased.Action interface for ActionFire
+func (a ActionFire) GameName() string {
+	return GameName
+}
+
+func (a ActionFire) DuelID() turnbased.DuelID {
+	return ""
+}
+
+func (a ActionFire) PlayerID() turnbased.PlayerID {
+	return ""
+}
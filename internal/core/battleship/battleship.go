@@ -0,0 +1,260 @@
+// Package battleship implements the Battleship game on the generic turn-based engine,
+// it has a hidden setup phase in BEGIN and hides each player's ships from the opponent
+package battleship
+
+import (
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+const GameName = "BATTLESHIP"
+
+// BoardSize is the number of rows and columns of each player's board
+const BoardSize = 10
+
+// ShipSpec describes a kind of ship in the fleet
+type ShipSpec struct {
+	Name   string
+	Length int
+}
+
+// Fleet is the ships each player must place during setup
+var Fleet = []ShipSpec{
+	{Name: "CARRIER", Length: 5},
+	{Name: "BATTLESHIP", Length: 4},
+	{Name: "CRUISER", Length: 3},
+	{Name: "SUBMARINE", Length: 3},
+	{Name: "DESTROYER", Length: 2},
+}
+
+// ShipPlacement places a ship with its bow at (Row, Column),
+// it extends to the right if Horizontal, downward (increasing Row) otherwise
+type ShipPlacement struct {
+	Name       string
+	Row        int
+	Column     int
+	Horizontal bool
+}
+
+// Ship is a placed ship and which of its cells were hit
+type Ship struct {
+	ShipPlacement
+	Length int
+	Hits   []bool // Hits[i] is true if the i-th cell from the bow was hit
+}
+
+// Cells returns the (row, column) cells covered by the ship
+func (s Ship) Cells() [][2]int {
+	cells := make([][2]int, s.Length)
+	for i := range cells {
+		if s.Horizontal {
+			cells[i] = [2]int{s.Row, s.Column + i}
+		} else {
+			cells[i] = [2]int{s.Row + i, s.Column}
+		}
+	}
+	return cells
+}
+
+// IsSunk returns true if all cells of the ship were hit
+func (s Ship) IsSunk() bool {
+	for _, hit := range s.Hits {
+		if !hit {
+			return false
+		}
+	}
+	return true
+}
+
+// ShotResult is the result of firing at a cell
+type ShotResult string
+
+// ShotResult enum
+const (
+	ShotMiss ShotResult = "MISS"
+	ShotHit  ShotResult = "HIT"
+	ShotSunk ShotResult = "SUNK"
+)
+
+// PlayerState is a player's own board: their ships and the shots the opponent fired at it
+type PlayerState struct {
+	ID    turnbased.PlayerID
+	Ships []Ship
+	// ShotsReceived[row][column] is the result of the opponent's shot at the cell, empty if not shot
+	ShotsReceived [BoardSize][BoardSize]ShotResult
+}
+
+// FleetPlaced returns true if the player has placed their fleet
+func (ps *PlayerState) FleetPlaced() bool {
+	return len(ps.Ships) > 0
+}
+
+// shipAt returns the index of the ship covering the cell and the cell index in the ship, -1 if none
+func (ps *PlayerState) shipAt(row int, column int) (int, int) {
+	for i, ship := range ps.Ships {
+		for j, cell := range ship.Cells() {
+			if cell[0] == row && cell[1] == column {
+				return i, j
+			}
+		}
+	}
+	return -1, -1
+}
+
+// allSunk returns true if every ship of the player was sunk
+func (ps *PlayerState) allSunk() bool {
+	for _, ship := range ps.Ships {
+		if !ship.IsSunk() {
+			return false
+		}
+	}
+	return true
+}
+
+type BattleshipDuel struct {
+	Duel    *turnbased.Duel
+	Players map[turnbased.PlayerID]*PlayerState
+}
+
+// NewBattleshipDuel creates a duel for exactly 2 players in the BEGIN state,
+// both players place their fleets secretly, then the first player in the list fires first
+func NewBattleshipDuel(players []turnbased.PlayerID) (*BattleshipDuel, error) {
+	if len(players) != 2 {
+		return nil, fmt.Errorf("battleship needs exactly 2 players, got %d", len(players))
+	}
+	if players[0] == players[1] {
+		return nil, fmt.Errorf("players must be different")
+	}
+	duel := &BattleshipDuel{
+		Duel:    turnbased.NewDuel("", players),
+		Players: make(map[turnbased.PlayerID]*PlayerState),
+	}
+	for _, pid := range players {
+		duel.Players[pid] = &PlayerState{ID: pid}
+	}
+	// the duel stays in BEGIN without a turn player until both fleets are placed
+	return duel, nil
+}
+
+// PlaceFleet places the whole fleet of a player during setup,
+// the fleet must contain each ship of Fleet exactly once, inside the board, without overlap.
+// When both players have placed their fleets, the duel starts.
+func (bd *BattleshipDuel) PlaceFleet(player turnbased.PlayerID, placements []ShipPlacement) error {
+	if bd.Duel.State != turnbased.DuelStateBegin {
+		return fmt.Errorf("fleets can only be placed during setup")
+	}
+	ps, ok := bd.Players[player]
+	if !ok {
+		return fmt.Errorf("player %s is not in the duel", player)
+	}
+	if ps.FleetPlaced() {
+		return fmt.Errorf("fleet already placed")
+	}
+	ships, err := buildFleet(placements)
+	if err != nil {
+		return err
+	}
+	ps.Ships = ships
+	// ship positions are secret, only log that the fleet is placed
+	bd.Duel.LogAction(player, "PLACE_FLEET", map[string]interface{}{})
+
+	for _, other := range bd.Players {
+		if !other.FleetPlaced() {
+			return nil
+		}
+	}
+	bd.Duel.TurnPlayer = bd.Duel.Players[0]
+	bd.Duel.Turn = 1
+	bd.Duel.State = turnbased.DuelStateRunning
+	return nil
+}
+
+// buildFleet validates the placements and creates the ships
+func buildFleet(placements []ShipPlacement) ([]Ship, error) {
+	if len(placements) != len(Fleet) {
+		return nil, fmt.Errorf("fleet needs %d ships, got %d", len(Fleet), len(placements))
+	}
+	var occupied [BoardSize][BoardSize]bool
+	ships := make([]Ship, 0, len(Fleet))
+	for _, spec := range Fleet {
+		found := -1
+		for i, p := range placements {
+			if p.Name == spec.Name {
+				if found != -1 {
+					return nil, fmt.Errorf("ship %s placed more than once", spec.Name)
+				}
+				found = i
+			}
+		}
+		if found == -1 {
+			return nil, fmt.Errorf("ship %s is missing", spec.Name)
+		}
+		ship := Ship{ShipPlacement: placements[found], Length: spec.Length, Hits: make([]bool, spec.Length)}
+		for _, cell := range ship.Cells() {
+			if cell[0] < 0 || cell[0] >= BoardSize || cell[1] < 0 || cell[1] >= BoardSize {
+				return nil, fmt.Errorf("ship %s is outside the board", spec.Name)
+			}
+			if occupied[cell[0]][cell[1]] {
+				return nil, fmt.Errorf("ship %s overlaps another ship", spec.Name)
+			}
+			occupied[cell[0]][cell[1]] = true
+		}
+		ships = append(ships, ship)
+	}
+	return ships, nil
+}
+
+// Fire shoots at a cell of the opponent's board, the player who sinks the whole
+// opposing fleet wins, otherwise the turn passes to the opponent.
+func (bd *BattleshipDuel) Fire(player turnbased.PlayerID, row int, column int) (ShotResult, error) {
+	if bd.Duel.State != turnbased.DuelStateRunning {
+		return "", turnbased.ErrDuelNotRunning
+	}
+	if bd.Duel.TurnPlayer != player {
+		return "", turnbased.ErrNotPlayerTurn
+	}
+	if row < 0 || row >= BoardSize || column < 0 || column >= BoardSize {
+		return "", fmt.Errorf("cell (%d, %d) is outside the board", row, column)
+	}
+	target := bd.opponent(player)
+	if target.ShotsReceived[row][column] != "" {
+		return "", fmt.Errorf("cell (%d, %d) was already shot", row, column)
+	}
+
+	result := ShotMiss
+	logData := map[string]interface{}{"row": row, "column": column}
+	if shipIdx, cellIdx := target.shipAt(row, column); shipIdx != -1 {
+		ship := &target.Ships[shipIdx]
+		ship.Hits[cellIdx] = true
+		result = ShotHit
+		if ship.IsSunk() {
+			result = ShotSunk
+			logData["ship"] = ship.Name
+		}
+	}
+	// a sunk ship's cells are all recorded as hits, the result SUNK is only in the log
+	target.ShotsReceived[row][column] = ShotHit
+	if result == ShotMiss {
+		target.ShotsReceived[row][column] = ShotMiss
+	}
+	logData["result"] = string(result)
+	bd.Duel.LogAction(player, "FIRE", logData)
+
+	if target.allSunk() {
+		bd.Duel.SetWinner(player)
+		return result, nil
+	}
+	bd.Duel.NextTurn()
+	return result, nil
+}
+
+// opponent returns the state of the other player
+func (bd *BattleshipDuel) opponent(player turnbased.PlayerID) *PlayerState {
+	for pid, ps := range bd.Players {
+		if pid != player {
+			return ps
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package battleship
+
+import (
+	"testing"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// testFleet places all ships horizontally at column 0, one ship per row starting from row 0
+func testFleet() []ShipPlacement {
+	ships := make([]ShipPlacement, len(Fleet))
+	for i, spec := range Fleet {
+		ships[i] = ShipPlacement{Name: spec.Name, Row: i, Column: 0, Horizontal: true}
+	}
+	return ships
+}
+
+func newRunningDuel(t *testing.T) *BattleshipDuel {
+	t.Helper()
+	duel, err := NewBattleshipDuel([]turnbased.PlayerID{"player1", "player2"})
+	if err != nil {
+		t.Fatalf("NewBattleshipDuel failed: %v", err)
+	}
+	for _, pid := range duel.Duel.Players {
+		if err := duel.PlaceFleet(pid, testFleet()); err != nil {
+			t.Fatalf("PlaceFleet failed: %v", err)
+		}
+	}
+	return duel
+}
+
+func TestSetupPhase(t *testing.T) {
+	duel, err := NewBattleshipDuel([]turnbased.PlayerID{"player1", "player2"})
+	if err != nil {
+		t.Fatalf("NewBattleshipDuel failed: %v", err)
+	}
+	if duel.Duel.State != turnbased.DuelStateBegin || duel.Duel.TurnPlayer != "" {
+		t.Fatalf("Expected BEGIN without turn player, got %s %s", duel.Duel.State, duel.Duel.TurnPlayer)
+	}
+	if _, err := duel.Fire("player1", 0, 0); err == nil {
+		t.Error("Fire should fail during setup")
+	}
+
+	invalid := map[string][]ShipPlacement{
+		"missing ship": testFleet()[1:],
+		"duplicated":   append(testFleet()[1:], ShipPlacement{Name: "BATTLESHIP", Row: 9}),
+		"outside":      append(testFleet()[1:], ShipPlacement{Name: "CARRIER", Row: 9, Column: 6, Horizontal: true}),
+		"overlap":      append(testFleet()[1:], ShipPlacement{Name: "CARRIER", Row: 0, Column: 1}),
+	}
+	for name, fleet := range invalid {
+		if err := duel.PlaceFleet("player1", fleet); err == nil {
+			t.Errorf("PlaceFleet should fail for %s", name)
+		}
+	}
+
+	if err := duel.PlaceFleet("player1", testFleet()); err != nil {
+		t.Fatalf("PlaceFleet failed: %v", err)
+	}
+	if err := duel.PlaceFleet("player1", testFleet()); err == nil {
+		t.Error("PlaceFleet should fail when the fleet is already placed")
+	}
+	if duel.Duel.State != turnbased.DuelStateBegin {
+		t.Errorf("Duel should wait in BEGIN for the other fleet")
+	}
+	if err := duel.PlaceFleet("player2", testFleet()); err != nil {
+		t.Fatalf("PlaceFleet failed: %v", err)
+	}
+	if duel.Duel.State != turnbased.DuelStateRunning || duel.Duel.TurnPlayer != "player1" {
+		t.Errorf("Expected RUNNING with player1 to fire, got %s %s", duel.Duel.State, duel.Duel.TurnPlayer)
+	}
+	for _, entry := range duel.Duel.ActionLog {
+		if entry.Action != "PLACE_FLEET" || len(entry.Data) != 0 {
+			t.Errorf("Log must not reveal ship positions, got %+v", entry)
+		}
+	}
+}
+
+func TestFire(t *testing.T) {
+	duel := newRunningDuel(t)
+
+	if _, err := duel.Fire("player2", 0, 0); err == nil {
+		t.Error("Fire should fail for non-turn player")
+	}
+	result, err := duel.Fire("player1", 9, 9)
+	if err != nil || result != ShotMiss {
+		t.Errorf("Expected miss, got %s %v", result, err)
+	}
+	if duel.Duel.TurnPlayer != "player2" {
+		t.Errorf("Turn should pass to player2")
+	}
+	result, _ = duel.Fire("player2", 4, 0)
+	if result != ShotHit {
+		t.Errorf("Expected hit on destroyer, got %s", result)
+	}
+	if _, err := duel.Fire("player1", 9, 9); err == nil {
+		t.Error("Fire should fail on a cell already shot")
+	}
+	duel.Fire("player1", 8, 8)
+	result, _ = duel.Fire("player2", 4, 1)
+	if result != ShotSunk {
+		t.Errorf("Expected destroyer sunk, got %s", result)
+	}
+	last := duel.Duel.ActionLog[len(duel.Duel.ActionLog)-1]
+	if last.Data["ship"] != "DESTROYER" || last.Data["result"] != "SUNK" {
+		t.Errorf("Unexpected log entry %+v", last)
+	}
+}
+
+func TestFire_Win(t *testing.T) {
+	duel := newRunningDuel(t)
+	miss := 0
+	for i, spec := range Fleet {
+		for column := 0; column < spec.Length; column++ {
+			if _, err := duel.Fire("player1", i, column); err != nil {
+				t.Fatalf("Fire failed: %v", err)
+			}
+			if duel.Duel.State == turnbased.DuelStateEnd {
+				break
+			}
+			// player2 only fires at empty water in the bottom rows
+			if _, err := duel.Fire("player2", BoardSize-1-miss/BoardSize, miss%BoardSize); err != nil {
+				t.Fatalf("Fire failed: %v", err)
+			}
+			miss++
+		}
+	}
+	if duel.Duel.State != turnbased.DuelStateEnd || duel.Duel.Winner != "player1" {
+		t.Errorf("player1 should win, got %s %s", duel.Duel.State, duel.Duel.Winner)
+	}
+}
+
+func TestGetStateForPlayer_HidesShips(t *testing.T) {
+	duel := newRunningDuel(t)
+	duel.Fire("player1", 4, 0) // hit player2's destroyer
+
+	state := duel.GetStateForPlayer("player1").(model.BattleshipGameState)
+	own := state.Players["player1"]
+	if len(own.Ships) != len(Fleet) || own.Cells[0][0] != model.BattleshipCellShip {
+		t.Errorf("Player should see their own ships, got %+v", own.Ships)
+	}
+	opp := state.Players["player2"]
+	if len(opp.Ships) != 0 {
+		t.Errorf("Player must not see unsunk opponent ships, got %+v", opp.Ships)
+	}
+	if opp.Cells[4][0] != string(ShotHit) || opp.Cells[0][0] != "" || opp.Cells[4][1] != "" {
+		t.Errorf("Player should only see hit cells of the opponent, got %v", opp.Cells[4])
+	}
+
+	duel.Fire("player2", 9, 9)
+	duel.Fire("player1", 4, 1) // sink the destroyer
+	opp = duel.GetStateForPlayer("player1").(model.BattleshipGameState).Players["player2"]
+	if len(opp.Ships) != 1 || opp.Ships[0].Name != "DESTROYER" || !opp.Ships[0].Sunk {
+		t.Errorf("Sunk ship should be revealed, got %+v", opp.Ships)
+	}
+	if opp.ShipsRemaining != len(Fleet)-1 {
+		t.Errorf("Expected %d ships remaining, got %d", len(Fleet)-1, opp.ShipsRemaining)
+	}
+
+	public := duel.GetState().(model.BattleshipGameState)
+	for pid, board := range public.Players {
+		for _, row := range board.Cells {
+			for _, cell := range row {
+				if cell == model.BattleshipCellShip {
+					t.Errorf("Public state must not reveal ships of %s", pid)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package battleship
+
+import (
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// Ensure BattleshipDuel implements GameLogic and PlayerStateViewer interfaces
+var (
+	_ turnbased.GameLogic         = (*BattleshipDuel)(nil)
+	_ turnbased.PlayerStateViewer = (*BattleshipDuel)(nil)
+)
+
+// GetState returns the public state, without any unhit ship position
+func (bd *BattleshipDuel) GetState() any {
+	return bd.GetStateForPlayer("")
+}
+
+// GetStateForPlayer returns the state as seen by the viewer:
+// the viewer sees all their own ships, but only the hit cells and sunk ships of the opponent
+func (bd *BattleshipDuel) GetStateForPlayer(viewer turnbased.PlayerID) any {
+	return bd.ToModelBattleshipGameState(viewer)
+}
+
+// HandleAction processes a game action, the turn player is assumed to be the actor
+func (bd *BattleshipDuel) HandleAction(action any) error {
+	return bd.HandleActionWithPlayer(action, bd.Duel.TurnPlayer)
+}
+
+// HandleActionWithPlayer processes a game action with player context
+func (bd *BattleshipDuel) HandleActionWithPlayer(action any, playerID turnbased.PlayerID) error {
+	switch a := action.(type) {
+	case ActionPlaceFleet:
+		return bd.PlaceFleet(playerID, a.Ships)
+	case ActionFire:
+		_, err := bd.Fire(playerID, a.Row, a.Column)
+		return err
+	default:
+		return fmt.Errorf("unknown action type: %T", action)
+	}
+}
+
+// ToModelBattleshipGameState converts a BattleshipDuel to model.BattleshipGameState as seen by the viewer
+func (bd *BattleshipDuel) ToModelBattleshipGameState(viewer turnbased.PlayerID) model.BattleshipGameState {
+	fleet := make([]model.BattleshipShipSpec, len(Fleet))
+	for i, spec := range Fleet {
+		fleet[i] = model.BattleshipShipSpec{Name: spec.Name, Length: spec.Length}
+	}
+	players := make(map[string]model.BattleshipBoard)
+	for pid, ps := range bd.Players {
+		isOwner := pid == viewer
+		board := model.BattleshipBoard{
+			ID:          string(pid),
+			FleetPlaced: ps.FleetPlaced(),
+			Ships:       []model.BattleshipShip{},
+			Cells:       make([][]string, BoardSize),
+		}
+		for row := range board.Cells {
+			board.Cells[row] = make([]string, BoardSize)
+			for column := range board.Cells[row] {
+				board.Cells[row][column] = string(ps.ShotsReceived[row][column])
+			}
+		}
+		for _, ship := range ps.Ships {
+			if !ship.IsSunk() {
+				board.ShipsRemaining++
+			}
+			if !isOwner && !ship.IsSunk() {
+				continue // hidden from the opponent until sunk
+			}
+			board.Ships = append(board.Ships, model.BattleshipShip{
+				Name:       ship.Name,
+				Row:        ship.Row,
+				Column:     ship.Column,
+				Horizontal: ship.Horizontal,
+				Length:     ship.Length,
+				Sunk:       ship.IsSunk(),
+			})
+			if isOwner {
+				for _, cell := range ship.Cells() {
+					if board.Cells[cell[0]][cell[1]] == "" {
+						board.Cells[cell[0]][cell[1]] = model.BattleshipCellShip
+					}
+				}
+			}
+		}
+		players[string(pid)] = board
+	}
+	return model.BattleshipGameState{
+		BoardSize: BoardSize,
+		Fleet:     fleet,
+		Players:   players,
+	}
+}
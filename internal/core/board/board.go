@@ -0,0 +1,244 @@
+// Package board is a toolkit for grid board games (Connect Four, chess, checkers, ...):
+// coordinates, directions, a generic grid of pieces with occupancy checks,
+// line and direction scans, and a JSON format for GetState
+package board
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Coord is a cell of a grid, Row 0 is the bottom row (e.g. chess rank 1),
+// Column 0 is the left column (e.g. chess file a)
+type Coord struct {
+	Row    int `json:"row"`
+	Column int `json:"column"`
+}
+
+// Add returns the coordinate moved once in the direction
+func (c Coord) Add(d Direction) Coord {
+	return Coord{Row: c.Row + d.DRow, Column: c.Column + d.DColumn}
+}
+
+// Direction is a step on the grid, it can be longer than one cell (e.g. a knight jump)
+type Direction struct {
+	DRow    int
+	DColumn int
+}
+
+// Scale returns the direction repeated n times, e.g. a pawn's 2-square advance
+func (d Direction) Scale(n int) Direction {
+	return Direction{DRow: d.DRow * n, DColumn: d.DColumn * n}
+}
+
+// Opposite returns the direction backwards
+func (d Direction) Opposite() Direction {
+	return d.Scale(-1)
+}
+
+// Common directions, Up is towards higher rows
+var (
+	Up        = Direction{DRow: 1, DColumn: 0}
+	Down      = Direction{DRow: -1, DColumn: 0}
+	Left      = Direction{DRow: 0, DColumn: -1}
+	Right     = Direction{DRow: 0, DColumn: 1}
+	UpRight   = Direction{DRow: 1, DColumn: 1}
+	UpLeft    = Direction{DRow: 1, DColumn: -1}
+	DownRight = Direction{DRow: -1, DColumn: 1}
+	DownLeft  = Direction{DRow: -1, DColumn: -1}
+)
+
+// Direction sets, in a fixed order so that scans are deterministic
+var (
+	Orthogonal = []Direction{Up, Right, Down, Left}
+	Diagonal   = []Direction{UpRight, DownRight, DownLeft, UpLeft}
+	// Adjacent is the 8 directions to the neighbor cells, e.g. a chess king's steps
+	Adjacent = []Direction{Up, UpRight, Right, DownRight, Down, DownLeft, Left, UpLeft}
+	// Knight is the 8 L-shaped jumps of a chess knight
+	Knight = []Direction{{2, 1}, {1, 2}, {-1, 2}, {-2, 1}, {-2, -1}, {-1, -2}, {1, -2}, {2, -1}}
+	// Lines is one direction per line orientation: horizontal, vertical and both diagonals,
+	// a line through a cell is scanned in a direction and its opposite
+	Lines = []Direction{Right, Up, UpRight, DownRight}
+)
+
+// Grid is a rectangular board holding a piece of type T in each cell,
+// the zero value of T means an empty cell
+type Grid[T comparable] struct {
+	rows    int
+	columns int
+	cells   []T // row-major, cells[row*columns+column]
+}
+
+// NewGrid creates an empty grid
+func NewGrid[T comparable](rows int, columns int) *Grid[T] {
+	return &Grid[T]{
+		rows:    rows,
+		columns: columns,
+		cells:   make([]T, rows*columns),
+	}
+}
+
+// Rows returns the number of rows
+func (g *Grid[T]) Rows() int { return g.rows }
+
+// Columns returns the number of columns
+func (g *Grid[T]) Columns() int { return g.columns }
+
+// InBounds returns true if the coordinate is on the grid
+func (g *Grid[T]) InBounds(c Coord) bool {
+	return c.Row >= 0 && c.Row < g.rows && c.Column >= 0 && c.Column < g.columns
+}
+
+// At returns the piece in the cell, the zero value for an empty cell or outside the grid
+func (g *Grid[T]) At(c Coord) T {
+	if !g.InBounds(c) {
+		var zero T
+		return zero
+	}
+	return g.cells[c.Row*g.columns+c.Column]
+}
+
+// Set puts a piece in the cell, setting the zero value empties it.
+// It panics if the coordinate is outside the grid, callers check InBounds first.
+func (g *Grid[T]) Set(c Coord, piece T) {
+	if !g.InBounds(c) {
+		panic(fmt.Sprintf("board: %v is outside the %dx%d grid", c, g.rows, g.columns))
+	}
+	g.cells[c.Row*g.columns+c.Column] = piece
+}
+
+// IsEmpty returns true if the cell is on the grid and empty
+func (g *Grid[T]) IsEmpty(c Coord) bool {
+	var zero T
+	return g.InBounds(c) && g.At(c) == zero
+}
+
+// IsFull returns true if no cell is empty
+func (g *Grid[T]) IsFull() bool {
+	var zero T
+	for _, piece := range g.cells {
+		if piece == zero {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns an independent copy of the grid
+func (g *Grid[T]) Clone() *Grid[T] {
+	clone := &Grid[T]{rows: g.rows, columns: g.columns, cells: make([]T, len(g.cells))}
+	copy(clone.cells, g.cells)
+	return clone
+}
+
+// Each calls f for each cell, row by row from row 0, column by column from column 0
+func (g *Grid[T]) Each(f func(c Coord, piece T)) {
+	for i, piece := range g.cells {
+		f(Coord{Row: i / g.columns, Column: i % g.columns}, piece)
+	}
+}
+
+// Ray returns the cells from the cell next to from in the direction until the edge
+// or the first occupied cell included, e.g. the squares a chess rook can move to or capture on
+func (g *Grid[T]) Ray(from Coord, d Direction) []Coord {
+	var ray []Coord
+	for c := from.Add(d); g.InBounds(c); c = c.Add(d) {
+		ray = append(ray, c)
+		if !g.IsEmpty(c) {
+			break
+		}
+	}
+	return ray
+}
+
+// FirstOccupied returns the first occupied cell from the cell next to from in the direction,
+// false if the scan reaches the edge
+func (g *Grid[T]) FirstOccupied(from Coord, d Direction) (Coord, bool) {
+	ray := g.Ray(from, d)
+	if len(ray) == 0 || g.IsEmpty(ray[len(ray)-1]) {
+		return Coord{}, false
+	}
+	return ray[len(ray)-1], true
+}
+
+// LineThrough returns the run of cells holding the same piece as c, through c
+// along the direction and its opposite, ordered in the direction. Empty if c is empty.
+func (g *Grid[T]) LineThrough(c Coord, d Direction) []Coord {
+	if g.IsEmpty(c) || !g.InBounds(c) {
+		return nil
+	}
+	piece := g.At(c)
+	start := c
+	for next := start.Add(d.Opposite()); g.InBounds(next) && g.At(next) == piece; next = next.Add(d.Opposite()) {
+		start = next
+	}
+	var line []Coord
+	for cur := start; g.InBounds(cur) && g.At(cur) == piece; cur = cur.Add(d) {
+		line = append(line, cur)
+	}
+	return line
+}
+
+// LineOfLength returns the first line through c of at least length cells of the same piece,
+// scanning the Lines directions, nil if there is none (e.g. Connect Four's 4 in a row)
+func (g *Grid[T]) LineOfLength(c Coord, length int) []Coord {
+	for _, d := range Lines {
+		if line := g.LineThrough(c, d); len(line) >= length {
+			return line
+		}
+	}
+	return nil
+}
+
+// Cells converts the grid to rows of cells for GetState: cells[row][column] = f(piece),
+// row 0 first
+func Cells[T comparable, U any](g *Grid[T], f func(piece T) U) [][]U {
+	cells := make([][]U, g.rows)
+	for row := range cells {
+		cells[row] = make([]U, g.columns)
+		for column := range cells[row] {
+			cells[row][column] = f(g.cells[row*g.columns+column])
+		}
+	}
+	return cells
+}
+
+// gridJSON is the JSON format of a grid
+type gridJSON[T any] struct {
+	Rows    int   `json:"rows"`
+	Columns int   `json:"columns"`
+	Cells   [][]T `json:"cells"` // cells[row][column], row 0 first
+}
+
+// MarshalJSON encodes the grid as {"rows": R, "columns": C, "cells": [[...], ...]}
+func (g *Grid[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gridJSON[T]{
+		Rows:    g.rows,
+		Columns: g.columns,
+		Cells:   Cells(g, func(piece T) T { return piece }),
+	})
+}
+
+// UnmarshalJSON decodes the format written by MarshalJSON
+func (g *Grid[T]) UnmarshalJSON(data []byte) error {
+	var decoded gridJSON[T]
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Rows < 0 || decoded.Columns < 0 {
+		return fmt.Errorf("invalid grid size %dx%d", decoded.Rows, decoded.Columns)
+	}
+	if len(decoded.Cells) != decoded.Rows {
+		return fmt.Errorf("expected %d rows of cells, got %d", decoded.Rows, len(decoded.Cells))
+	}
+	for row, cells := range decoded.Cells {
+		if len(cells) != decoded.Columns {
+			return fmt.Errorf("expected %d cells in row %d, got %d", decoded.Columns, row, len(cells))
+		}
+	}
+	*g = *NewGrid[T](decoded.Rows, decoded.Columns)
+	for row, cells := range decoded.Cells {
+		copy(g.cells[row*g.columns:], cells)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package board
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGridSetAndOccupancy(t *testing.T) {
+	g := NewGrid[string](3, 4)
+	if g.Rows() != 3 || g.Columns() != 4 {
+		t.Fatalf("Expected 3x4 grid, got %dx%d", g.Rows(), g.Columns())
+	}
+	g.Set(Coord{Row: 1, Column: 2}, "x")
+	if g.At(Coord{Row: 1, Column: 2}) != "x" || g.IsEmpty(Coord{Row: 1, Column: 2}) {
+		t.Error("Expected x at (1, 2)")
+	}
+	if g.IsEmpty(Coord{Row: 3, Column: 0}) || g.At(Coord{Row: -1, Column: 0}) != "" {
+		t.Error("Cells outside the grid should be neither empty nor hold a piece")
+	}
+	clone := g.Clone()
+	clone.Set(Coord{Row: 0, Column: 0}, "y")
+	if !g.IsEmpty(Coord{Row: 0, Column: 0}) {
+		t.Error("Clone should not share cells with the original")
+	}
+	if g.IsFull() {
+		t.Error("Grid should not be full")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Set outside the grid should panic")
+		}
+	}()
+	g.Set(Coord{Row: 0, Column: 4}, "x")
+}
+
+func TestRayAndFirstOccupied(t *testing.T) {
+	g := NewGrid[string](8, 8)
+	g.Set(Coord{Row: 0, Column: 5}, "blocker")
+	ray := g.Ray(Coord{Row: 0, Column: 2}, Right)
+	want := []Coord{{0, 3}, {0, 4}, {0, 5}}
+	if !reflect.DeepEqual(ray, want) {
+		t.Errorf("Expected ray %v, got %v", want, ray)
+	}
+	if c, ok := g.FirstOccupied(Coord{Row: 0, Column: 2}, Right); !ok || c != (Coord{0, 5}) {
+		t.Errorf("Expected first occupied (0, 5), got %v %v", c, ok)
+	}
+	if _, ok := g.FirstOccupied(Coord{Row: 0, Column: 2}, Up); ok {
+		t.Error("Expected no occupied cell upwards")
+	}
+	if got := len(g.Ray(Coord{Row: 7, Column: 7}, UpRight)); got != 0 {
+		t.Errorf("Expected empty ray from the corner, got %d cells", got)
+	}
+}
+
+func TestLineThrough(t *testing.T) {
+	g := NewGrid[int](6, 7)
+	for i := 0; i < 4; i++ {
+		g.Set(Coord{Row: i, Column: i + 1}, 1)
+	}
+	g.Set(Coord{Row: 1, Column: 1}, 2)
+
+	line := g.LineThrough(Coord{Row: 2, Column: 3}, UpRight)
+	want := []Coord{{0, 1}, {1, 2}, {2, 3}, {3, 4}}
+	if !reflect.DeepEqual(line, want) {
+		t.Errorf("Expected diagonal %v, got %v", want, line)
+	}
+	if got := g.LineOfLength(Coord{Row: 3, Column: 4}, 4); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected line of 4 %v, got %v", want, got)
+	}
+	if got := g.LineOfLength(Coord{Row: 1, Column: 1}, 2); got != nil {
+		t.Errorf("Expected no line of 2 through a lone piece, got %v", got)
+	}
+	if got := g.LineThrough(Coord{Row: 5, Column: 0}, Right); got != nil {
+		t.Errorf("Expected no line through an empty cell, got %v", got)
+	}
+}
+
+func TestGridJSON(t *testing.T) {
+	g := NewGrid[string](2, 3)
+	g.Set(Coord{Row: 1, Column: 0}, "a")
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `{"rows":2,"columns":3,"cells":[["","",""],["a","",""]]}`
+	if string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+	var decoded Grid[string]
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, g) {
+		t.Errorf("Round trip changed the grid: %+v", decoded)
+	}
+	for _, invalid := range []string{
+		`{"rows":2,"columns":3,"cells":[["a"]]}`,
+		`{"rows":2,"columns":-1,"cells":[[],[]]}`,
+		`{"rows":-1,"columns":2,"cells":[]}`,
+	} {
+		if err := json.Unmarshal([]byte(invalid), &decoded); err == nil {
+			t.Errorf("Unmarshal should fail for cells not matching the size: %s", invalid)
+		}
+	}
+}
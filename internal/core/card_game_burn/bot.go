@@ -0,0 +1,187 @@
+package card_game_burn
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// Bot chooses actions for a player, so that a human can play Burn against the computer
+type Bot interface {
+	// ChooseAction returns the next action of the player, who must be the turn player,
+	// one of LegalActions
+	ChooseAction(duel *BurnDuel, player turnbased.PlayerID) any
+}
+
+type BotKind string // BotKind is the strategy of a built-in bot
+
+// BotKind enum
+const (
+	// BotKindRandom plays a random legal action, including ending the turn
+	BotKindRandom BotKind = "RANDOM"
+	// BotKindGreedy plays all its cards, each time the card and option with the biggest LP swing,
+	// or inflicts if that wins or when an opponent is low
+	BotKindGreedy BotKind = "GREEDY"
+)
+
+// NewBot creates a built-in bot of the kind
+func NewBot(kind BotKind) (Bot, error) {
+	return NewBotWithSeed(kind, uint64(time.Now().UnixNano()))
+}
+
+// NewBotWithSeed creates a built-in bot whose choices only depend on the seed and the duel
+func NewBotWithSeed(kind BotKind, seed uint64) (Bot, error) {
+	switch kind {
+	case BotKindRandom:
+		return &RandomBot{random: rand.New(rand.NewPCG(seed, seed>>1))}, nil
+	case BotKindGreedy:
+		return &GreedyBot{LowLifePoint: DefaultGreedyLowLifePoint}, nil
+	default:
+		return nil, fmt.Errorf("unknown bot kind %q, must be %s or %s", kind, BotKindRandom, BotKindGreedy)
+	}
+}
+
+// maxBotActions limits the actions of bots in one PlayBotTurns call,
+// so that a bot bug cannot loop forever
+const maxBotActions = 1000
+
+// PlayBotTurns lets the bots act while the duel is running and the turn player is a bot,
+// it returns when a human has to act or the duel has ended
+func (cgb *BurnDuel) PlayBotTurns(bots map[turnbased.PlayerID]Bot) error {
+	for i := 0; i < maxBotActions; i++ {
+		if cgb.Duel.State != turnbased.DuelStateRunning {
+			return nil
+		}
+		player := cgb.Duel.TurnPlayer
+		bot, ok := bots[player]
+		if !ok {
+			return nil
+		}
+		action := bot.ChooseAction(cgb, player)
+		if err := cgb.HandleActionWithPlayer(action, player); err != nil {
+			return fmt.Errorf("bot %s: %w", player, err)
+		}
+	}
+	return fmt.Errorf("bots did not finish after %d actions", maxBotActions)
+}
+
+// LegalActions returns the actions the player can take now: playing each card in hand
+// with each valid option, and ending the turn. Empty if the player cannot act.
+func (cgb *BurnDuel) LegalActions(player turnbased.PlayerID) []any {
+	if cgb.Duel.State != turnbased.DuelStateRunning || cgb.Duel.TurnPlayer != player {
+		return nil
+	}
+	var actions []any
+	for _, card := range cgb.Players[player].Hand {
+		actions = append(actions,
+			ActionPlayCard{CardID: card.UniqueCardID, Option: PlayCardOptionGain},
+			ActionPlayCard{CardID: card.UniqueCardID, Option: PlayCardOptionInflict})
+		if card.HasContinuous() {
+			actions = append(actions, ActionPlayCard{CardID: card.UniqueCardID, Option: PlayCardOptionContinuous})
+		}
+	}
+	return append(actions, ActionEndTurn{})
+}
+
+// RandomBot plays a uniformly random legal action
+type RandomBot struct {
+	random *rand.Rand
+}
+
+// ChooseAction implements Bot
+func (b *RandomBot) ChooseAction(duel *BurnDuel, player turnbased.PlayerID) any {
+	actions := duel.LegalActions(player)
+	if len(actions) == 0 {
+		return ActionEndTurn{}
+	}
+	return actions[b.random.IntN(len(actions))]
+}
+
+// DefaultGreedyLowLifePoint is the opponent's LP from which the greedy bot only inflicts
+const DefaultGreedyLowLifePoint = 2000
+
+// GreedyBot plays its whole hand, one card at a time:
+// it inflicts if that wins or if an opponent has at most LowLifePoint,
+// otherwise it plays the card and option with the biggest LP swing
+type GreedyBot struct {
+	LowLifePoint float64
+}
+
+// ChooseAction implements Bot
+func (b *GreedyBot) ChooseAction(duel *BurnDuel, player turnbased.PlayerID) any {
+	hand := duel.Players[player].Hand
+	if len(hand) == 0 {
+		return ActionEndTurn{}
+	}
+
+	lowestOpponentLP := -1.0
+	for pid, opp := range duel.Players {
+		if pid != player && (lowestOpponentLP < 0 || opp.LifePoint < lowestOpponentLP) {
+			lowestOpponentLP = opp.LifePoint
+		}
+	}
+
+	// the card that inflicts the most damage, and whether it wins
+	var bestInflict Card
+	bestDamage, wins := -1.0, false
+	for _, card := range hand {
+		damage, lethal := 0.0, false
+		for pid, opp := range duel.Players {
+			if pid == player {
+				continue
+			}
+			d := opp.damageTaken(card.Inflict)
+			damage += d
+			lethal = lethal || d >= opp.LifePoint
+		}
+		if lethal && !wins || lethal == wins && damage > bestDamage {
+			bestInflict, bestDamage, wins = card, damage, lethal
+		}
+	}
+	if wins || (lowestOpponentLP <= b.LowLifePoint && bestDamage > 0) {
+		return ActionPlayCard{CardID: bestInflict.UniqueCardID, Option: PlayCardOptionInflict}
+	}
+
+	var best ActionPlayCard
+	bestSwing := -1.0
+	for _, action := range duel.LegalActions(player) {
+		play, ok := action.(ActionPlayCard)
+		if !ok {
+			continue
+		}
+		if swing := duel.estimateSwing(player, play); swing > bestSwing {
+			best, bestSwing = play, swing
+		}
+	}
+	return best
+}
+
+// estimateSwing estimates the LP difference between the player and their opponents
+// that playing the card with the option makes, continuous cards are valued
+// as if their effect applies once in each of the following turns they stay on the field
+func (cgb *BurnDuel) estimateSwing(player turnbased.PlayerID, play ActionPlayCard) float64 {
+	card, ok := cgb.Players[player].Hand.Find(string(play.CardID))
+	if !ok {
+		return 0
+	}
+	switch play.Option {
+	case PlayCardOptionGain:
+		return cgb.gainFor(player, card.Gain)
+	case PlayCardOptionInflict:
+		swing := 0.0
+		for pid, opp := range cgb.Players {
+			if pid != player {
+				swing += opp.damageTaken(card.Inflict)
+			}
+		}
+		return swing
+	case PlayCardOptionContinuous:
+		switch card.Continuous.Type {
+		case ContinuousEffectStandbyGain, ContinuousEffectDamageReduction:
+			return card.Continuous.Amount * float64(card.Continuous.Duration-1)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package card_game_burn
+
+import (
+	"testing"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// setHand replaces the player's hand with plain cards of the given (gain, inflict) amounts
+func setHand(duel *BurnDuel, player turnbased.PlayerID, amounts ...[2]float64) {
+	ps := duel.Players[player]
+	ps.Hand = ps.Hand[:0]
+	for _, a := range amounts {
+		ps.Hand.Add(Card{UniqueCardID: UUIDGen(), Gain: a[0], Inflict: a[1]})
+	}
+}
+
+func TestLegalActions(t *testing.T) {
+	duel := NewBurnDuel([]turnbased.PlayerID{"player1", "player2"})
+	turnPlayer := duel.Duel.TurnPlayer
+	ps := duel.Players[turnPlayer]
+	for i := range ps.Hand {
+		ps.Hand[i].Continuous = ContinuousEffect{}
+	}
+	ps.Hand[0].Continuous = ContinuousEffect{Type: ContinuousEffectStandbyGain, Amount: 100, Duration: 2}
+
+	// 5 cards with GAIN and INFLICT, 1 of them also CONTINUOUS, then END_TURN
+	if got := len(duel.LegalActions(turnPlayer)); got != 5*2+1+1 {
+		t.Errorf("Expected 12 legal actions, got %d", got)
+	}
+	if got := duel.LegalActions(opponentOf(duel, turnPlayer)); len(got) != 0 {
+		t.Errorf("Non-turn player should have no legal action, got %d", len(got))
+	}
+}
+
+func TestGreedyBot(t *testing.T) {
+	duel := NewBurnDuel([]turnbased.PlayerID{"player1", "player2"})
+	turnPlayer := duel.Duel.TurnPlayer
+	opponent := opponentOf(duel, turnPlayer)
+	bot, err := NewBot(BotKindGreedy)
+	if err != nil {
+		t.Fatalf("NewBot failed: %v", err)
+	}
+
+	setHand(duel, turnPlayer, [2]float64{900, 200}, [2]float64{100, 600})
+	action := bot.ChooseAction(duel, turnPlayer).(ActionPlayCard)
+	if action.CardID != duel.Players[turnPlayer].Hand[0].UniqueCardID || action.Option != PlayCardOptionGain {
+		t.Errorf("Expected to gain 900 as the biggest swing, got %+v", action)
+	}
+
+	duel.Players[opponent].LifePoint = 500
+	action = bot.ChooseAction(duel, turnPlayer).(ActionPlayCard)
+	if action.CardID != duel.Players[turnPlayer].Hand[1].UniqueCardID || action.Option != PlayCardOptionInflict {
+		t.Errorf("Expected to inflict the lethal 600, got %+v", action)
+	}
+
+	duel.Players[opponent].LifePoint = 1500
+	action = bot.ChooseAction(duel, turnPlayer).(ActionPlayCard)
+	if action.CardID != duel.Players[turnPlayer].Hand[1].UniqueCardID || action.Option != PlayCardOptionInflict {
+		t.Errorf("Expected to inflict the most against a low opponent, got %+v", action)
+	}
+
+	setHand(duel, turnPlayer)
+	if _, ok := bot.ChooseAction(duel, turnPlayer).(ActionEndTurn); !ok {
+		t.Error("Expected to end the turn with an empty hand")
+	}
+}
+
+func TestPlayBotTurns(t *testing.T) {
+	if _, err := NewBot("SMART"); err == nil {
+		t.Error("NewBot should fail for an unknown kind")
+	}
+	for i := 0; i < 20; i++ {
+		duel := NewBurnDuelWithSeed([]turnbased.PlayerID{"random", "greedy"}, uint64(i))
+		randomBot, _ := NewBot(BotKindRandom)
+		greedyBot, _ := NewBot(BotKindGreedy)
+		bots := map[turnbased.PlayerID]Bot{"random": randomBot, "greedy": greedyBot}
+		if err := duel.PlayBotTurns(bots); err != nil {
+			t.Fatalf("PlayBotTurns failed: %v", err)
+		}
+		if duel.Duel.State != turnbased.DuelStateEnd || duel.Duel.Winner == "" {
+			t.Fatalf("Expected the bots to finish the duel, got %s", duel.Duel.State)
+		}
+	}
+
+	// a bot stops when it is a human's turn, the seed makes the bot play first without winning
+	duel := NewBurnDuelWithSeed([]turnbased.PlayerID{"human", "greedy"}, 6)
+	greedyBot, _ := NewBot(BotKindGreedy)
+	if err := duel.PlayBotTurns(map[turnbased.PlayerID]Bot{"greedy": greedyBot}); err != nil {
+		t.Fatalf("PlayBotTurns failed: %v", err)
+	}
+	if duel.Duel.TurnPlayer != "human" || duel.Duel.Turn != 2 {
+		t.Errorf("Expected human to act after the bot, got turn %d of %s", duel.Duel.Turn, duel.Duel.TurnPlayer)
+	}
+}
@@ -1,9 +1,13 @@
 package card_game_burn
 
 import (
+	"math/rand/v2"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/core/zones"
 	"github.com/daominah/turn_based_game/internal/model"
 )
 
@@ -234,6 +238,26 @@ func TestGetState(t *testing.T) {
 	}
 }
 
+func TestGetStateForPlayer_HidesOpponentHand(t *testing.T) {
+	duel := NewBurnDuel([]turnbased.PlayerID{"player1", "player2"})
+
+	view := duel.GetStateForPlayer("player1").(model.BurnGameState)
+	if got := len(view.Players["player1"].Hand); got == 0 || got != view.Players["player1"].HandSize {
+		t.Errorf("player1 should see their own hand of %d cards, got %d", view.Players["player1"].HandSize, got)
+	}
+	if got := view.Players["player2"]; len(got.Hand) != 0 || got.HandSize != len(duel.Players["player2"].Hand) {
+		t.Errorf("player1 should only see the hand size of player2, got %d cards, size %d", len(got.Hand), got.HandSize)
+	}
+
+	public := duel.GetState().(model.BurnGameState)
+	for pid, ps := range public.Players {
+		if len(ps.Hand) != 0 || ps.HandSize == 0 {
+			t.Errorf("public state should hide the hand of %s but keep its size, got %d cards, size %d",
+				pid, len(ps.Hand), ps.HandSize)
+		}
+	}
+}
+
 func TestHandleActionWithPlayer(t *testing.T) {
 	players := []turnbased.PlayerID{"player1", "player2"}
 	duel := NewBurnDuel(players)
@@ -279,3 +303,222 @@ func TestHandleActionWithPlayer(t *testing.T) {
 		t.Error("HandleActionWithPlayer should fail for non-turn player")
 	}
 }
+
+// opponentOf returns the other player in a 2-player duel
+func opponentOf(duel *BurnDuel, player turnbased.PlayerID) turnbased.PlayerID {
+	for _, pid := range duel.Duel.Players {
+		if pid != player {
+			return pid
+		}
+	}
+	return ""
+}
+
+func TestPlayCard_ContinuousStaysOnField(t *testing.T) {
+	players := []turnbased.PlayerID{"player1", "player2"}
+	duel := NewBurnDuel(players)
+
+	turnPlayer := duel.Duel.TurnPlayer
+	ps := duel.Players[turnPlayer]
+	ps.Hand[0].Continuous = ContinuousEffect{Type: ContinuousEffectDamageReduction, Amount: 300, Duration: 2}
+	ps.Hand[1].Continuous = ContinuousEffect{}
+	card := ps.Hand[0]
+
+	// A normal card cannot be played as continuous
+	if duel.PlayCard(turnPlayer, ps.Hand[1].UniqueCardID, PlayCardOptionContinuous) {
+		t.Error("PlayCard CONTINUOUS should fail for a normal card")
+	}
+
+	if !duel.PlayCard(turnPlayer, card.UniqueCardID, PlayCardOptionContinuous) {
+		t.Fatal("PlayCard CONTINUOUS should succeed")
+	}
+	if len(ps.Field) != 1 || ps.Field[0].UniqueCardID != card.UniqueCardID {
+		t.Fatalf("Continuous card should stay on the field, field: %+v", ps.Field)
+	}
+	if ps.Field[0].TurnsLeft != 2 {
+		t.Errorf("Expected 2 turns left, got %d", ps.Field[0].TurnsLeft)
+	}
+	if len(ps.Graveyard) != 0 {
+		t.Errorf("Continuous card should not be in graveyard")
+	}
+
+	// Opponent inflicts damage, reduced by 300
+	duel.EndTurn()
+	opponent := duel.Duel.TurnPlayer
+	oppPS := duel.Players[opponent]
+	oppPS.Hand[0].Inflict = 1000
+	lpBefore := ps.LifePoint
+	if !duel.PlayCard(opponent, oppPS.Hand[0].UniqueCardID, PlayCardOptionInflict) {
+		t.Fatal("PlayCard INFLICT should succeed")
+	}
+	if ps.LifePoint != lpBefore-700 {
+		t.Errorf("Expected LP %f after reduced damage, got %f", lpBefore-700, ps.LifePoint)
+	}
+
+	// Damage cannot be reduced below 0
+	oppPS.Hand[0].Inflict = 100
+	lpBefore = ps.LifePoint
+	duel.PlayCard(opponent, oppPS.Hand[0].UniqueCardID, PlayCardOptionInflict)
+	if ps.LifePoint != lpBefore {
+		t.Errorf("Expected LP unchanged %f, got %f", lpBefore, ps.LifePoint)
+	}
+}
+
+func TestContinuous_StandbyGainAndExpire(t *testing.T) {
+	players := []turnbased.PlayerID{"player1", "player2"}
+	duel := NewBurnDuel(players)
+
+	turnPlayer := duel.Duel.TurnPlayer
+	ps := duel.Players[turnPlayer]
+	ps.Hand[0].Continuous = ContinuousEffect{Type: ContinuousEffectStandbyGain, Amount: 200, Duration: 2}
+	card := ps.Hand[0]
+	if !duel.PlayCard(turnPlayer, card.UniqueCardID, PlayCardOptionContinuous) {
+		t.Fatal("PlayCard CONTINUOUS should succeed")
+	}
+	// Playing the card does not gain LP immediately
+	if ps.LifePoint != 8000 {
+		t.Errorf("Expected LP 8000, got %f", ps.LifePoint)
+	}
+
+	duel.EndTurn() // owner's 1st turn ends, 1 turn left
+	if len(ps.Field) != 1 || ps.Field[0].TurnsLeft != 1 {
+		t.Fatalf("Expected card on field with 1 turn left, field: %+v", ps.Field)
+	}
+	duel.EndTurn() // owner's standby phase
+	if ps.LifePoint != 8200 {
+		t.Errorf("Expected LP 8200 after standby phase, got %f", ps.LifePoint)
+	}
+	duel.EndTurn() // owner's 2nd turn ends, card expires
+	if len(ps.Field) != 0 {
+		t.Errorf("Continuous card should leave the field, field: %+v", ps.Field)
+	}
+	if len(ps.Graveyard) != 1 || ps.Graveyard[0].UniqueCardID != card.UniqueCardID {
+		t.Errorf("Expired card should be in graveyard, graveyard: %+v", ps.Graveyard)
+	}
+	duel.EndTurn() // no more standby gain
+	if ps.LifePoint != 8200 {
+		t.Errorf("Expected LP 8200 after card expired, got %f", ps.LifePoint)
+	}
+	if last := duel.Duel.ActionLog[len(duel.Duel.ActionLog)-1]; last.Action != "END_TURN" {
+		t.Errorf("Expected last action END_TURN, got %s", last.Action)
+	}
+}
+
+func TestContinuous_HalveOpponentGain(t *testing.T) {
+	players := []turnbased.PlayerID{"player1", "player2"}
+	duel := NewBurnDuel(players)
+
+	turnPlayer := duel.Duel.TurnPlayer
+	ps := duel.Players[turnPlayer]
+	ps.Hand[0].Continuous = ContinuousEffect{Type: ContinuousEffectHalveOpponentGain, Duration: 3}
+	if !duel.PlayCard(turnPlayer, ps.Hand[0].UniqueCardID, PlayCardOptionContinuous) {
+		t.Fatal("PlayCard CONTINUOUS should succeed")
+	}
+
+	// The owner's own Gain is not affected
+	ps.Hand[0].Gain = 400
+	duel.PlayCard(turnPlayer, ps.Hand[0].UniqueCardID, PlayCardOptionGain)
+	if ps.LifePoint != 8400 {
+		t.Errorf("Expected LP 8400, got %f", ps.LifePoint)
+	}
+
+	duel.EndTurn()
+	opponent := opponentOf(duel, turnPlayer)
+	oppPS := duel.Players[opponent]
+	oppPS.Hand[0].Gain = 400
+	duel.PlayCard(opponent, oppPS.Hand[0].UniqueCardID, PlayCardOptionGain)
+	if oppPS.LifePoint != 8200 {
+		t.Errorf("Expected opponent LP 8200 after halved gain, got %f", oppPS.LifePoint)
+	}
+}
+
+func TestClone_Independent(t *testing.T) {
+	duel := NewBurnDuel([]turnbased.PlayerID{"player1", "player2"})
+	turnPlayer := duel.Duel.TurnPlayer
+	card := duel.Players[turnPlayer].Hand[0]
+	duel.PlayCard(turnPlayer, card.UniqueCardID, PlayCardOptionGain)
+
+	clone := duel.Clone()
+	if clone.Duel == duel.Duel || clone.Duel.Game != clone {
+		t.Fatal("Clone should have its own generic duel pointing back at the clone")
+	}
+	if len(clone.Duel.ActionLog) != 1 || clone.Duel.ActionLog[0].Data["gain"] != card.Gain {
+		t.Fatalf("Clone should copy the action log, got %+v", clone.Duel.ActionLog)
+	}
+
+	clone.Duel.ActionLog[0].Data["gain"] = -1.0
+	clone.Players[turnPlayer].Hand[0].Gain = -1
+	clone.Players[turnPlayer].LifePoint = 1
+	clone.EndTurn()
+	if duel.Duel.ActionLog[0].Data["gain"] != card.Gain || len(duel.Duel.ActionLog) != 1 {
+		t.Errorf("Changing the clone's log changed the original: %+v", duel.Duel.ActionLog)
+	}
+	if duel.Players[turnPlayer].Hand[0].Gain == -1 || duel.Players[turnPlayer].LifePoint == 1 {
+		t.Error("Changing the clone's players changed the original")
+	}
+	if duel.Duel.TurnPlayer != turnPlayer || duel.Duel.Turn != 1 {
+		t.Errorf("Playing on the clone changed the original turn: %d %s", duel.Duel.Turn, duel.Duel.TurnPlayer)
+	}
+
+	// the clone continues the same random sequence
+	clone = duel.Clone()
+	want := duel.random.Uint64()
+	if got := clone.random.Uint64(); got != want {
+		t.Errorf("Expected the clone's RNG to continue with %d, got %d", want, got)
+	}
+}
+
+func TestClone_DetachedDuel(t *testing.T) {
+	duel := NewBurnDuelWithSeed([]turnbased.PlayerID{"player1", "player2"}, 1)
+	other := NewBurnDuelWithSeed([]turnbased.PlayerID{"player1", "player2"}, 2)
+	for _, game := range []turnbased.GameLogic{nil, other} {
+		duel.Duel.Game = game
+		clone := duel.Clone()
+		if clone.Duel == nil || clone.Duel.Game != clone || clone.Duel.Turn != duel.Duel.Turn {
+			t.Errorf("Expected a copy of the generic duel pointing at the clone with game %T", game)
+		}
+	}
+	duel.Duel = nil
+	if clone := duel.Clone(); clone.Duel != nil || len(clone.Players) != 2 {
+		t.Errorf("Expected a clone without generic duel, got %+v", clone)
+	}
+}
+
+func TestDeterminize(t *testing.T) {
+	duel := NewBurnDuelWithSeed([]turnbased.PlayerID{"player1", "player2"}, 1)
+	cardIDs := func(cards ...zones.Zone[Card]) []string {
+		var ids []string
+		for _, zone := range cards {
+			for _, card := range zone {
+				ids = append(ids, card.ItemID())
+			}
+		}
+		slices.Sort(ids)
+		return ids
+	}
+	me, opponent := duel.Players["player1"], duel.Players["player2"]
+	opponentHand := slices.Clone(opponent.Hand)
+	random := rand.New(rand.NewPCG(1, 2))
+	sampledHands := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		sample := duel.Determinize("player1", random)
+		mine, theirs := sample.Players["player1"], sample.Players["player2"]
+		if !slices.Equal(mine.Hand, me.Hand) || !slices.Equal(cardIDs(mine.Deck), cardIDs(me.Deck)) {
+			t.Fatalf("Expected the viewer to keep their hand and the cards of their deck")
+		}
+		if len(theirs.Hand) != len(opponent.Hand) || len(theirs.Deck) != len(opponent.Deck) ||
+			!slices.Equal(cardIDs(theirs.Hand, theirs.Deck), cardIDs(opponent.Hand, opponent.Deck)) {
+			t.Fatalf("Expected the opponent's hand and deck to be dealt from the same cards")
+		}
+		sampledHands[strings.Join(cardIDs(theirs.Hand), ",")] = true
+		if sample.random.Uint64() == duel.Clone().random.Uint64() {
+			t.Errorf("Expected the sample to draw other random numbers than the duel")
+		}
+	}
+	if len(sampledHands) < 2 {
+		t.Errorf("Expected the opponent's hand to be sampled, got always %v", sampledHands)
+	}
+	if !slices.Equal(opponent.Hand, opponentHand) || len(duel.Duel.ActionLog) != 0 {
+		t.Errorf("Determinize should not change the duel")
+	}
+}
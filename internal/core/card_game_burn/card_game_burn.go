@@ -5,19 +5,63 @@ package card_game_burn
 import (
 	randc "crypto/rand"
 	"encoding/hex"
-	"math/rand"
+	"fmt"
+	"math/rand/v2"
 	"time"
 
 	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/core/zones"
 )
 
 const GameName = "CARD_GAME_BURN"
 
+// Reasons a Burn duel ends, see turnbased.Duel.EndReason
+const (
+	EndReasonLifePoints = "LIFE_POINTS" // a player's LP reached 0
+	EndReasonDeckOut    = "DECK_OUT"    // a player had to draw from an empty deck
+)
+
 type Card struct {
 	UniqueCardID UniqueCardID
 	Gain         float64        // amount of LP gained if player chooses option to gain LP
 	Inflict      float64        // amount of LP inflicted to opponent if player chooses option to burn
 	PlayedOption PlayCardOption // empty at first, will be set by player when playing card
+	// Continuous is empty for normal cards. If set, the card can also be played
+	// with PlayCardOptionContinuous to stay on the field and keep applying the effect
+	Continuous ContinuousEffect
+	// TurnsLeft is the number of owner's turns a continuous card still stays on the field,
+	// including the current one, only meaningful while the card is on the field
+	TurnsLeft int
+}
+
+// ContinuousEffect is an effect that keeps applying while its card stays on the field
+type ContinuousEffect struct {
+	Type     ContinuousEffectType
+	Amount   float64 // meaning depends on Type, unused by ContinuousEffectHalveOpponentGain
+	Duration int     // number of owner's turns the card stays on the field, including the turn it is played
+}
+
+type ContinuousEffectType string // ContinuousEffectType is the kind of continuous effect
+
+// ContinuousEffectType enum
+const (
+	ContinuousEffectNone ContinuousEffectType = "" // normal card, no continuous effect
+	// ContinuousEffectStandbyGain: the owner gains Amount LP at the start of each of their turns
+	ContinuousEffectStandbyGain ContinuousEffectType = "STANDBY_GAIN"
+	// ContinuousEffectDamageReduction: damage inflicted to the owner is reduced by Amount
+	ContinuousEffectDamageReduction ContinuousEffectType = "DAMAGE_REDUCTION"
+	// ContinuousEffectHalveOpponentGain: the owner's opponents only gain half LP from Gain
+	ContinuousEffectHalveOpponentGain ContinuousEffectType = "HALVE_OPPONENT_GAIN"
+)
+
+// ItemID implements zones.Item
+func (c Card) ItemID() string {
+	return string(c.UniqueCardID)
+}
+
+// HasContinuous returns true if the card can be played as a continuous card
+func (c Card) HasContinuous() bool {
+	return c.Continuous.Type != ContinuousEffectNone
 }
 
 // UniqueCardID unique everywhere, so it easier to connect action to card,
@@ -31,52 +75,72 @@ const (
 	PlayCardOptionPending PlayCardOption = "" // card is not played yet
 	PlayCardOptionGain    PlayCardOption = "GAIN"
 	PlayCardOptionInflict PlayCardOption = "INFLICT"
+	// PlayCardOptionContinuous keeps the card on the field, only valid if Card.HasContinuous
+	PlayCardOptionContinuous PlayCardOption = "CONTINUOUS"
 )
 
 type PlayerState struct {
 	ID        turnbased.PlayerID
 	LifePoint float64
-	Hand      []Card
-	Deck      []Card
-	// as current rule, field only needs 1 zone to play card,
-	// and the card probably only lasts a second on the field to choose option,
-	// then it resolves the Gain or Inflict effect, then send to the Graveyard
-	Field     []Card
-	Graveyard []Card
+	Hand      zones.Zone[Card]
+	Deck      zones.Zone[Card]
+	// a card played with Gain or Inflict only lasts a second on the field to resolve
+	// its effect, then is sent to the Graveyard. A card played with Continuous
+	// stays on the field until its duration ends
+	Field     zones.Zone[Card]
+	Graveyard zones.Zone[Card]
 }
 
 type BurnDuel struct {
 	Duel    *turnbased.Duel
 	Players map[turnbased.PlayerID]*PlayerState
+	// random is the duel's RNG, its source pcg is kept so that a clone can copy its state
+	pcg    *rand.PCG
+	random *rand.Rand
 }
 
 func NewBurnDuel(players []turnbased.PlayerID) *BurnDuel {
-	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return NewBurnDuelWithSeed(players, uint64(time.Now().UnixNano()))
+}
+
+// NewBurnDuelWithSeed creates a duel whose decks, card IDs and coin toss only depend on the seed,
+// so that a duel can be reproduced (e.g. in tests and simulations)
+func NewBurnDuelWithSeed(players []turnbased.PlayerID, seed uint64) *BurnDuel {
+	pcg := rand.NewPCG(seed, seed>>1)
+	random := rand.New(pcg)
 	genericDuel := turnbased.NewDuel("", players)
 	duel := &BurnDuel{
 		Duel:    genericDuel,
 		Players: make(map[turnbased.PlayerID]*PlayerState),
+		pcg:     pcg,
+		random:  random,
 	}
+	genericDuel.Game = duel
 	for _, pid := range players {
 		// just default deck size of 20 cards, generated on the fly,
 		// usually in a real game, the deck is predefined by players, should be arg for init duel func
-		deck := make([]Card, 20)
+		deck := make(zones.Zone[Card], 20)
 		for i := range deck {
 			deck[i] = Card{
-				UniqueCardID: UUIDGen(),
-				Gain:         float64((random.Intn(10) + 1) * 100),
-				Inflict:      float64((random.Intn(30) + 1) * 100),
+				UniqueCardID: randomCardID(random),
+				Gain:         float64((random.IntN(10) + 1) * 100),
+				Inflict:      float64((random.IntN(30) + 1) * 100),
+			}
+			// about 1 in 4 cards also has a continuous effect
+			if random.IntN(4) == 0 {
+				deck[i].Continuous = randomContinuousEffect(random)
 			}
 		}
+		deck.Shuffle(random)
 		duel.Players[pid] = &PlayerState{
 			ID:        pid,
 			LifePoint: 8000,
 			Deck:      deck,
-			Hand:      []Card{},
+			Hand:      zones.Zone[Card]{},
 		}
 	}
 	// Toss coin for first turn
-	first := players[random.Intn(len(players))]
+	first := players[random.IntN(len(players))]
 	duel.Duel.TurnPlayer = first
 	duel.Duel.Turn = 1
 	// Draw 5 cards for each player
@@ -95,15 +159,80 @@ func NewBurnDuel(players []turnbased.PlayerID) *BurnDuel {
 // drawCard draws a card from the player's deck to their hand,
 // returns the drawn card or nil if deck is empty
 func (ps *PlayerState) drawCard() *Card {
-	if len(ps.Deck) == 0 {
+	card, ok := zones.DrawTo(&ps.Deck, &ps.Hand)
+	if !ok {
 		return nil
 	}
-	card := ps.Deck[0]
-	ps.Hand = append(ps.Hand, card)
-	ps.Deck = ps.Deck[1:]
 	return &card
 }
 
+// randomContinuousEffect generates a continuous effect,
+// amounts are divisible by 100 and duration is 2 to 4 turns
+func randomContinuousEffect(random *rand.Rand) ContinuousEffect {
+	duration := random.IntN(3) + 2
+	switch random.IntN(3) {
+	case 0:
+		return ContinuousEffect{
+			Type:     ContinuousEffectStandbyGain,
+			Amount:   float64((random.IntN(5) + 1) * 100),
+			Duration: duration,
+		}
+	case 1:
+		return ContinuousEffect{
+			Type:     ContinuousEffectDamageReduction,
+			Amount:   float64((random.IntN(5) + 1) * 100),
+			Duration: duration,
+		}
+	default:
+		return ContinuousEffect{
+			Type:     ContinuousEffectHalveOpponentGain,
+			Duration: duration,
+		}
+	}
+}
+
+// fieldEffectTotal sums Amount of the continuous effects of type t on the player's field
+func (ps *PlayerState) fieldEffectTotal(t ContinuousEffectType) float64 {
+	total := 0.0
+	for _, c := range ps.Field {
+		if c.Continuous.Type == t {
+			total += c.Continuous.Amount
+		}
+	}
+	return total
+}
+
+// hasFieldEffect returns true if the player controls a continuous card of type t
+func (ps *PlayerState) hasFieldEffect(t ContinuousEffectType) bool {
+	for _, c := range ps.Field {
+		if c.Continuous.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// damageTaken returns the LP the player loses when inflicted the amount,
+// after the reduction of their continuous cards (not below 0)
+func (ps *PlayerState) damageTaken(inflict float64) float64 {
+	damage := inflict - ps.fieldEffectTotal(ContinuousEffectDamageReduction)
+	if damage < 0 {
+		return 0
+	}
+	return damage
+}
+
+// gainFor returns the LP the player gains from a card's Gain, halved if an opponent
+// controls a ContinuousEffectHalveOpponentGain card
+func (cgb *BurnDuel) gainFor(player turnbased.PlayerID, gain float64) float64 {
+	for pid, opp := range cgb.Players {
+		if pid != player && opp.hasFieldEffect(ContinuousEffectHalveOpponentGain) {
+			return gain / 2
+		}
+	}
+	return gain
+}
+
 // PlayCard plays a card from hand (by UniqueCardID), applying its effect.
 func (cgb *BurnDuel) PlayCard(
 	player turnbased.PlayerID, cardID UniqueCardID, option PlayCardOption) bool {
@@ -112,45 +241,54 @@ func (cgb *BurnDuel) PlayCard(
 		return false
 	}
 	ps := cgb.Players[player]
-	handIdx := -1
-	for i, c := range ps.Hand {
-		if c.UniqueCardID == cardID {
-			handIdx = i
-			break
-		}
+	card, found := ps.Hand.Find(string(cardID))
+	if !found {
+		return false
 	}
-	if handIdx == -1 {
+	if option == PlayCardOptionContinuous && !card.HasContinuous() {
 		return false
 	}
-	card := ps.Hand[handIdx]
 	// Set PlayedOption
 	card.PlayedOption = option
 	// Remove from hand, put to field
-	ps.Hand = append(ps.Hand[:handIdx], ps.Hand[handIdx+1:]...)
-	ps.Field = append(ps.Field, card)
+	ps.Hand.Remove(string(cardID))
+	ps.Field.Add(card)
+	logData := map[string]interface{}{
+		"option":  string(option),
+		"gain":    card.Gain,
+		"inflict": card.Inflict,
+	}
 	// Resolve effect
 	if option == PlayCardOptionInflict {
 		for pid, opp := range cgb.Players {
 			if pid != player {
-				opp.LifePoint -= card.Inflict
+				damage := opp.damageTaken(card.Inflict)
+				logData["damage"] = damage
+				opp.LifePoint -= damage
 				if opp.LifePoint <= 0 {
 					cgb.Duel.SetWinner(player)
+					cgb.Duel.EndReason = EndReasonLifePoints
 				}
 			}
 		}
 	} else if option == PlayCardOptionGain {
-		ps.LifePoint += card.Gain
+		healed := cgb.gainFor(player, card.Gain)
+		logData["healed"] = healed
+		ps.LifePoint += healed
+	}
+	if option == PlayCardOptionContinuous {
+		// the card stays on the field, its effect is applied by the other rules
+		ps.Field[len(ps.Field)-1].TurnsLeft = card.Continuous.Duration
+		logData["effect"] = string(card.Continuous.Type)
+		logData["amount"] = card.Continuous.Amount
+		logData["duration"] = card.Continuous.Duration
+	} else {
+		// Move card from field to graveyard
+		zones.Move(&ps.Field, &ps.Graveyard, string(card.UniqueCardID))
 	}
-	// Move card from field to graveyard
-	ps.Graveyard = append(ps.Graveyard, card)
-	ps.Field = ps.Field[:len(ps.Field)-1]
 
 	// Log the action in the generic duel log
-	cgb.Duel.LogAction(player, "PLAY_CARD", map[string]interface{}{
-		"option":  string(option),
-		"gain":    card.Gain,
-		"inflict": card.Inflict,
-	})
+	cgb.Duel.LogAction(player, "PLAY_CARD", logData)
 
 	return true
 }
@@ -163,6 +301,8 @@ func (cgb *BurnDuel) EndTurn() {
 	// Log the end turn action
 	cgb.Duel.LogAction(endingPlayer, "END_TURN", map[string]interface{}{})
 
+	cgb.expireFieldCards(endingPlayer)
+
 	cgb.Duel.NextTurn()
 	// Now the next player is the turn player, they draw at start of turn
 	ps := cgb.Players[cgb.Duel.TurnPlayer]
@@ -170,9 +310,50 @@ func (cgb *BurnDuel) EndTurn() {
 		// Deck is empty for the new turn player, so they lose
 		// The player who just ended their turn wins
 		cgb.Duel.SetWinner(endingPlayer)
+		cgb.Duel.EndReason = EndReasonDeckOut
+		return
+	}
+	cgb.standbyPhase(cgb.Duel.TurnPlayer)
+}
+
+// expireFieldCards counts down the continuous cards of the player whose turn is ending,
+// cards whose duration ended are sent to the graveyard
+func (cgb *BurnDuel) expireFieldCards(player turnbased.PlayerID) {
+	ps := cgb.Players[player]
+	for i := range ps.Field {
+		ps.Field[i].TurnsLeft--
+	}
+	expired := ps.Field.RemoveIf(func(c Card) bool { return c.TurnsLeft <= 0 })
+	for _, c := range expired {
+		c.TurnsLeft = 0
+		ps.Graveyard.Add(c)
+		cgb.Duel.LogAction(player, "EXPIRE_CARD", map[string]interface{}{
+			"effect": string(c.Continuous.Type),
+			"amount": c.Continuous.Amount,
+		})
 	}
 }
 
+// standbyPhase applies the start-of-turn continuous effects of the turn player
+func (cgb *BurnDuel) standbyPhase(player turnbased.PlayerID) {
+	ps := cgb.Players[player]
+	for _, c := range ps.Field {
+		if c.Continuous.Type != ContinuousEffectStandbyGain {
+			continue
+		}
+		ps.LifePoint += c.Continuous.Amount
+		cgb.Duel.LogAction(player, "CONTINUOUS_EFFECT", map[string]interface{}{
+			"effect": string(c.Continuous.Type),
+			"amount": c.Continuous.Amount,
+		})
+	}
+}
+
+// randomCardID generates a card ID from the duel's RNG, unique like UUIDGen
+func randomCardID(random *rand.Rand) UniqueCardID {
+	return UniqueCardID(fmt.Sprintf("%016x%016x", random.Uint64(), random.Uint64()))
+}
+
 func UUIDGen() UniqueCardID {
 	b := make([]byte, 16)
 	_, _ = randc.Read(b)
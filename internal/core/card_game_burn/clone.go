@@ -0,0 +1,84 @@
+package card_game_burn
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/core/zones"
+)
+
+// Ensure BurnDuel supports cloning
+var _ turnbased.GameCloner = (*BurnDuel)(nil)
+
+// Clone returns a fully independent copy of the duel that can be played on
+// without changing the original, e.g. by an AI simulating moves.
+// The copy's generic duel is a copy of Duel whose Game is the copy, whatever Duel.Game is.
+func (cgb *BurnDuel) Clone() *BurnDuel {
+	if cgb.Duel == nil {
+		return cgb.cloneGame(nil)
+	}
+	generic := *cgb.Duel
+	generic.Game = nil         // the game state is copied by cloneGame
+	duel, _ := generic.Clone() // it only fails for a game that cannot be cloned
+	clone := cgb.cloneGame(duel)
+	duel.Game = clone
+	return clone
+}
+
+// CloneGame implements turnbased.GameCloner: it copies the players' zones
+// and the RNG state, so that the copy draws the same random numbers as the original would
+func (cgb *BurnDuel) CloneGame(duel *turnbased.Duel) turnbased.GameLogic {
+	return cgb.cloneGame(duel)
+}
+
+func (cgb *BurnDuel) cloneGame(duel *turnbased.Duel) *BurnDuel {
+	pcg := *cgb.pcg
+	clone := &BurnDuel{
+		Duel:    duel,
+		Players: make(map[turnbased.PlayerID]*PlayerState, len(cgb.Players)),
+		pcg:     &pcg,
+		random:  rand.New(&pcg),
+	}
+	for pid, ps := range cgb.Players {
+		clone.Players[pid] = &PlayerState{
+			ID:        ps.ID,
+			LifePoint: ps.LifePoint,
+			Hand:      append(zones.Zone[Card](nil), ps.Hand...),
+			Deck:      append(zones.Zone[Card](nil), ps.Deck...),
+			Field:     append(zones.Zone[Card](nil), ps.Field...),
+			Graveyard: append(zones.Zone[Card](nil), ps.Graveyard...),
+		}
+	}
+	return clone
+}
+
+// Determinize returns a clone where what the viewer cannot see is sampled with random:
+// the cards in the hand and deck of each other player are shuffled together and dealt back
+// with the same zone sizes, every deck is shuffled and the clone's RNG is reseeded.
+// A search on the clone (see ai.BurnGame) cannot know the opponent's hand or the next draws.
+func (cgb *BurnDuel) Determinize(viewer turnbased.PlayerID, random *rand.Rand) *BurnDuel {
+	clone := cgb.Clone()
+	// the cards are sorted first, so that the sample does not depend on where they really are
+	byID := func(a, b Card) int { return strings.Compare(a.ItemID(), b.ItemID()) }
+	for _, pid := range clone.Duel.Players {
+		ps, ok := clone.Players[pid]
+		if !ok {
+			continue
+		}
+		if pid != viewer {
+			unseen := append(append(zones.Zone[Card](nil), ps.Hand...), ps.Deck...)
+			slices.SortFunc(unseen, byID)
+			unseen.Shuffle(random)
+			ps.Hand = append(zones.Zone[Card](nil), unseen[:len(ps.Hand)]...)
+			ps.Deck = append(zones.Zone[Card](nil), unseen[len(ps.Hand):]...)
+			continue
+		}
+		slices.SortFunc(ps.Deck, byID)
+		ps.Deck.Shuffle(random)
+	}
+	seed := random.Uint64()
+	clone.pcg.Seed(seed, seed>>1)
+	return clone
+}
@@ -8,13 +8,21 @@ import (
 	"github.com/daominah/turn_based_game/internal/model"
 )
 
-// Ensure BurnDuel implements GameLogic interface
-var _ turnbased.GameLogic = (*BurnDuel)(nil)
+// Ensure BurnDuel implements GameLogic, PlayerStateViewer and TurnPasser interfaces
+var (
+	_ turnbased.GameLogic         = (*BurnDuel)(nil)
+	_ turnbased.PlayerStateViewer = (*BurnDuel)(nil)
+	_ turnbased.TurnPasser        = (*BurnDuel)(nil)
+)
 
-// GetState returns the game-specific state as JSON-serializable data
-// Returns model.BurnGameState for type safety
+// GetState returns the public state as model.BurnGameState, without any player's hand
 func (cgb *BurnDuel) GetState() any {
-	return cgb.ToModelBurnGameState()
+	return cgb.GetStateForPlayer("")
+}
+
+// GetStateForPlayer returns the state as seen by the viewer, who only sees their own hand
+func (cgb *BurnDuel) GetStateForPlayer(viewer turnbased.PlayerID) any {
+	return cgb.ToModelBurnGameStateForPlayer(viewer)
 }
 
 // HandleAction processes a game action
@@ -36,16 +44,22 @@ func (cgb *BurnDuel) HandleAction(action any) error {
 
 // HandleActionWithPlayer processes a game action with player context
 func (cgb *BurnDuel) HandleActionWithPlayer(action any, playerID turnbased.PlayerID) error {
+	if cgb.Duel.State != turnbased.DuelStateRunning {
+		return turnbased.ErrDuelNotRunning
+	}
 	switch a := action.(type) {
 	case ActionPlayCard:
+		if cgb.Duel.TurnPlayer != playerID {
+			return turnbased.ErrNotPlayerTurn
+		}
 		success := cgb.PlayCard(playerID, a.CardID, a.Option)
 		if !success {
-			return fmt.Errorf("failed to play card: invalid action or not player's turn")
+			return fmt.Errorf("failed to play card: invalid action")
 		}
 		return nil
 	case ActionEndTurn:
 		if cgb.Duel.TurnPlayer != playerID {
-			return fmt.Errorf("not player's turn")
+			return turnbased.ErrNotPlayerTurn
 		}
 		cgb.EndTurn()
 		return nil
@@ -54,6 +68,11 @@ func (cgb *BurnDuel) HandleActionWithPlayer(action any, playerID turnbased.Playe
 	}
 }
 
+// PassTurn ends the player's turn without playing, see turnbased.TurnPasser
+func (cgb *BurnDuel) PassTurn(playerID turnbased.PlayerID) error {
+	return cgb.HandleActionWithPlayer(ActionEndTurn{}, playerID)
+}
+
 // SerializeState returns the full game state as JSON bytes
 func (cgb *BurnDuel) SerializeState() ([]byte, error) {
 	state := struct {
@@ -1,52 +1,46 @@
 package card_game_burn
 
 import (
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/core/zones"
 	"github.com/daominah/turn_based_game/internal/model"
 )
 
-// ToModelBurnGameState converts a BurnDuel to model.BurnGameState
-func (cgb *BurnDuel) ToModelBurnGameState() model.BurnGameState {
-	playersState := make(map[string]model.BurnPlayerState)
+// Visibility of the zones of a player
+const (
+	HandVisibility      = zones.VisibilityOwner
+	DeckVisibility      = zones.VisibilityHidden
+	FieldVisibility     = zones.VisibilityPublic
+	GraveyardVisibility = zones.VisibilityPublic
+)
 
-	for pid, ps := range cgb.Players {
-		// Convert cards
-		hand := make([]model.BurnCard, len(ps.Hand))
-		for i, c := range ps.Hand {
-			hand[i] = model.BurnCard{
-				UniqueCardID: string(c.UniqueCardID),
-				Gain:         c.Gain,
-				Inflict:      c.Inflict,
-				PlayedOption: string(c.PlayedOption),
-			}
-		}
+// ToModelBurnGameState converts a BurnDuel to model.BurnGameState, with all hands visible
+func (cgb *BurnDuel) ToModelBurnGameState() model.BurnGameState {
+	return cgb.toModelBurnGameState(func(turnbased.PlayerID) bool { return true })
+}
 
-		field := make([]model.BurnCard, len(ps.Field))
-		for i, c := range ps.Field {
-			field[i] = model.BurnCard{
-				UniqueCardID: string(c.UniqueCardID),
-				Gain:         c.Gain,
-				Inflict:      c.Inflict,
-				PlayedOption: string(c.PlayedOption),
-			}
-		}
+// ToModelBurnGameStateForPlayer converts a BurnDuel to model.BurnGameState as seen by the viewer,
+// who only sees their own hand, an empty viewer sees no hand
+func (cgb *BurnDuel) ToModelBurnGameStateForPlayer(viewer turnbased.PlayerID) model.BurnGameState {
+	return cgb.toModelBurnGameState(func(owner turnbased.PlayerID) bool {
+		return viewer != "" && owner == viewer
+	})
+}
 
-		graveyard := make([]model.BurnCard, len(ps.Graveyard))
-		for i, c := range ps.Graveyard {
-			graveyard[i] = model.BurnCard{
-				UniqueCardID: string(c.UniqueCardID),
-				Gain:         c.Gain,
-				Inflict:      c.Inflict,
-				PlayedOption: string(c.PlayedOption),
-			}
-		}
+// toModelBurnGameState converts the duel, isOwner tells if the viewer owns a player's zones
+func (cgb *BurnDuel) toModelBurnGameState(isOwner func(turnbased.PlayerID) bool) model.BurnGameState {
+	playersState := make(map[string]model.BurnPlayerState)
 
+	for pid, ps := range cgb.Players {
+		hand := ps.Hand.ViewFor(HandVisibility, isOwner(pid), nil)
 		playersState[string(pid)] = model.BurnPlayerState{
 			ID:        string(ps.ID),
 			LifePoint: ps.LifePoint,
-			Hand:      hand,
+			Hand:      toModelBurnCards(hand.Items),
+			HandSize:  hand.Count,
 			DeckSize:  len(ps.Deck),
-			Field:     field,
-			Graveyard: graveyard,
+			Field:     toModelBurnCards(ps.Field),
+			Graveyard: toModelBurnCards(ps.Graveyard),
 		}
 	}
 
@@ -54,3 +48,25 @@ func (cgb *BurnDuel) ToModelBurnGameState() model.BurnGameState {
 		Players: playersState,
 	}
 }
+
+// toModelBurnCards converts cards in a zone to model.BurnCard
+func toModelBurnCards(cards []Card) []model.BurnCard {
+	ret := make([]model.BurnCard, len(cards))
+	for i, c := range cards {
+		ret[i] = model.BurnCard{
+			UniqueCardID: string(c.UniqueCardID),
+			Gain:         c.Gain,
+			Inflict:      c.Inflict,
+			PlayedOption: string(c.PlayedOption),
+			TurnsLeft:    c.TurnsLeft,
+		}
+		if c.HasContinuous() {
+			ret[i].Continuous = &model.BurnContinuousEffect{
+				Type:     string(c.Continuous.Type),
+				Amount:   c.Continuous.Amount,
+				Duration: c.Continuous.Duration,
+			}
+		}
+	}
+	return ret
+}
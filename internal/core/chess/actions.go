@@ -0,0 +1,25 @@
+package chess
+
+import (
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// ActionMove represents an action to move a piece, Move is written in UCI or SAN
+type ActionMove struct {
+	Move string
+}
+
+// Implement turnbased.Action interface for ActionMove
+func (a ActionMove) GameName() string {
+	return GameName
+}
+
+func (a ActionMove) DuelID() turnbased.DuelID {
+	// DuelID will be set by the handler from the message context
+	return ""
+}
+
+func (a ActionMove) PlayerID() turnbased.PlayerID {
+	// PlayerID will be set by the handler from the message context
+	return ""
+}
@@ -0,0 +1,146 @@
+// Package chess implements standard chess on the generic turn-based engine,
+// including castling, en passant, promotion and the draw rules
+package chess
+
+import (
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+const GameName = "CHESS"
+
+// DrawReason explains why a chess duel ended in a draw
+type DrawReason string
+
+// DrawReason enum
+const (
+	DrawReasonNone                 DrawReason = ""
+	DrawReasonStalemate            DrawReason = "STALEMATE"
+	DrawReasonThreefoldRepetition  DrawReason = "THREEFOLD_REPETITION"
+	DrawReasonFiftyMoveRule        DrawReason = "FIFTY_MOVE_RULE"
+	DrawReasonInsufficientMaterial DrawReason = "INSUFFICIENT_MATERIAL"
+)
+
+// EndReasonCheckmate is the turnbased.Duel.EndReason of a duel won by checkmate,
+// a drawn duel ends with its DrawReason
+const EndReasonCheckmate = "CHECKMATE"
+
+type ChessDuel struct {
+	Duel     *turnbased.Duel
+	Position Position
+	// Colors maps each player to the color they play, the first player plays White
+	Colors map[turnbased.PlayerID]Color
+	// History is the played moves in SAN
+	History    []string
+	LastMove   *Move
+	DrawReason DrawReason
+	// repetitions counts how many times each position occurred, by Position.RepetitionKey
+	repetitions map[string]int
+}
+
+// NewChessDuel creates a duel from the standard starting position,
+// the first player plays White and moves first
+func NewChessDuel(players []turnbased.PlayerID) (*ChessDuel, error) {
+	return NewChessDuelFromFEN(players, StartFEN)
+}
+
+// NewChessDuelFromFEN creates a duel from a custom position, the first player plays White
+func NewChessDuelFromFEN(players []turnbased.PlayerID, fen string) (*ChessDuel, error) {
+	if len(players) != 2 {
+		return nil, fmt.Errorf("chess needs exactly 2 players, got %d", len(players))
+	}
+	if players[0] == players[1] {
+		return nil, fmt.Errorf("players must be different")
+	}
+	position, err := ParseFEN(fen)
+	if err != nil {
+		return nil, err
+	}
+	if position.kingSquare(White) == NoSquare || position.kingSquare(Black) == NoSquare {
+		return nil, fmt.Errorf("each side needs a king")
+	}
+	duel := &ChessDuel{
+		Duel:        turnbased.NewDuel("", players),
+		Position:    position,
+		Colors:      map[turnbased.PlayerID]Color{players[0]: White, players[1]: Black},
+		History:     []string{},
+		repetitions: map[string]int{position.RepetitionKey(): 1},
+	}
+	duel.Duel.Turn = 1
+	duel.Duel.TurnPlayer = duel.PlayerOf(position.SideToMove)
+	duel.Duel.State = turnbased.DuelStateRunning
+	duel.checkEnd()
+	return duel, nil
+}
+
+// PlayerOf returns the player who plays the color
+func (cd *ChessDuel) PlayerOf(c Color) turnbased.PlayerID {
+	for pid, color := range cd.Colors {
+		if color == c {
+			return pid
+		}
+	}
+	return ""
+}
+
+// MakeMove plays a move written in UCI or SAN for the player,
+// then checks for checkmate or draw and advances the turn if the duel goes on.
+func (cd *ChessDuel) MakeMove(player turnbased.PlayerID, moveText string) error {
+	if cd.Duel.State != turnbased.DuelStateRunning {
+		return turnbased.ErrDuelNotRunning
+	}
+	if cd.Duel.TurnPlayer != player {
+		return turnbased.ErrNotPlayerTurn
+	}
+	m, err := cd.Position.ParseMove(moveText)
+	if err != nil {
+		return err
+	}
+	san := cd.Position.SAN(m)
+	cd.Position = cd.Position.Apply(m)
+	cd.History = append(cd.History, san)
+	cd.LastMove = &m
+	cd.repetitions[cd.Position.RepetitionKey()]++
+
+	cd.Duel.LogAction(player, "MOVE", map[string]interface{}{
+		"uci": m.UCI(),
+		"san": san,
+	})
+
+	if !cd.checkEnd() {
+		cd.Duel.NextTurn()
+	}
+	return nil
+}
+
+// checkEnd ends the duel if the side to move is checkmated or a draw rule applies,
+// returns true if the duel ended
+func (cd *ChessDuel) checkEnd() bool {
+	if len(cd.Position.LegalMoves()) == 0 {
+		if cd.Position.InCheck() {
+			cd.Duel.SetWinner(cd.PlayerOf(cd.Position.SideToMove.Opponent()))
+			cd.Duel.EndReason = EndReasonCheckmate
+			return true
+		}
+		cd.setDraw(DrawReasonStalemate)
+		return true
+	}
+	switch {
+	case cd.repetitions[cd.Position.RepetitionKey()] >= 3:
+		cd.setDraw(DrawReasonThreefoldRepetition)
+	case cd.Position.HalfmoveClock >= 100:
+		cd.setDraw(DrawReasonFiftyMoveRule)
+	case cd.Position.InsufficientMaterial():
+		cd.setDraw(DrawReasonInsufficientMaterial)
+	default:
+		return false
+	}
+	return true
+}
+
+func (cd *ChessDuel) setDraw(reason DrawReason) {
+	cd.DrawReason = reason
+	cd.Duel.SetDraw()
+	cd.Duel.EndReason = string(reason)
+}
@@ -0,0 +1,240 @@
+package chess
+
+import (
+	"testing"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// perft counts the leaf nodes of the legal move tree, the standard move generator check
+func perft(p Position, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	moves := p.LegalMoves()
+	if depth == 1 {
+		return len(moves)
+	}
+	total := 0
+	for _, m := range moves {
+		total += perft(p.Apply(m), depth-1)
+	}
+	return total
+}
+
+func TestPerft(t *testing.T) {
+	// well-known results from https://www.chessprogramming.org/Perft_Results
+	tests := []struct {
+		name  string
+		fen   string
+		depth int
+		nodes int
+	}{
+		{"start", StartFEN, 3, 8902},
+		{"kiwipete castling", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 2, 2039},
+		{"en passant and pins", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 4, 43238},
+		{"promotions", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", 3, 9467},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := ParseFEN(tc.fen)
+			if err != nil {
+				t.Fatalf("ParseFEN failed: %v", err)
+			}
+			if got := perft(p, tc.depth); got != tc.nodes {
+				t.Errorf("perft(%d) = %d, want %d", tc.depth, got, tc.nodes)
+			}
+		})
+	}
+}
+
+func TestFENRoundTrip(t *testing.T) {
+	for _, fen := range []string{
+		StartFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+	} {
+		p, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatalf("ParseFEN failed: %v", err)
+		}
+		if got := p.FEN(); got != fen {
+			t.Errorf("FEN() = %q, want %q", got, fen)
+		}
+	}
+}
+
+func TestParseFEN_InvalidEnPassant(t *testing.T) {
+	for _, fen := range []string{
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f5 0 3", // not on the 6th rank
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR b KQkq f6 0 3", // black is to move, white moved last
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 3", // no black pawn on c5
+		"rnbqkbnr/pppppppp/8/4Pp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",  // f7 is not empty
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e6 0 1",   // e6 is the 6th rank, black is to move
+	} {
+		if _, err := ParseFEN(fen); err == nil {
+			t.Errorf("ParseFEN(%q) accepted an impossible en passant square", fen)
+		}
+	}
+	if _, err := ParseFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"); err != nil {
+		t.Errorf("ParseFEN failed after 1. e4: %v", err)
+	}
+}
+
+// play makes the moves alternately for the turn player
+func play(t *testing.T, duel *ChessDuel, moves ...string) {
+	t.Helper()
+	for _, move := range moves {
+		if err := duel.MakeMove(duel.Duel.TurnPlayer, move); err != nil {
+			t.Fatalf("MakeMove %s failed: %v", move, err)
+		}
+	}
+}
+
+func TestMakeMove(t *testing.T) {
+	duel, err := NewChessDuel([]turnbased.PlayerID{"white", "black"})
+	if err != nil {
+		t.Fatalf("NewChessDuel failed: %v", err)
+	}
+	if duel.Duel.TurnPlayer != "white" {
+		t.Errorf("White should move first, got %s", duel.Duel.TurnPlayer)
+	}
+	if err := duel.MakeMove("black", "e7e5"); err == nil {
+		t.Error("MakeMove should fail for non-turn player")
+	}
+	if err := duel.MakeMove("white", "e2e5"); err == nil {
+		t.Error("MakeMove should fail for an illegal move")
+	}
+
+	// UCI and SAN are both accepted
+	play(t, duel, "e2e4", "e5", "Nf3", "b8c6")
+	want := []string{"e4", "e5", "Nf3", "Nc6"}
+	for i, san := range want {
+		if duel.History[i] != san {
+			t.Errorf("History[%d] = %s, want %s", i, duel.History[i], san)
+		}
+	}
+	if duel.Duel.TurnPlayer != "white" || duel.Duel.Turn != 5 {
+		t.Errorf("Expected turn 5 of white, got %d of %s", duel.Duel.Turn, duel.Duel.TurnPlayer)
+	}
+	if last := duel.Duel.ActionLog[len(duel.Duel.ActionLog)-1]; last.Action != "MOVE" || last.Data["uci"] != "b8c6" {
+		t.Errorf("Unexpected last log entry %+v", last)
+	}
+}
+
+func TestSpecialMoves(t *testing.T) {
+	t.Run("castling", func(t *testing.T) {
+		duel, _ := NewChessDuelFromFEN([]turnbased.PlayerID{"white", "black"},
+			"r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R3K2R w KQkq - 0 1")
+		play(t, duel, "O-O", "e8c8")
+		if duel.History[0] != "O-O" || duel.History[1] != "O-O-O" {
+			t.Errorf("Unexpected castling SAN %v", duel.History)
+		}
+		if got := duel.Position.FEN(); got != "2kr3r/pppppppp/8/8/8/8/PPPPPPPP/R4RK1 w - - 2 2" {
+			t.Errorf("Unexpected position after castling %s", got)
+		}
+	})
+	t.Run("cannot castle through check", func(t *testing.T) {
+		p, _ := ParseFEN("4k3/8/8/8/8/8/5r2/R3K2R w KQ - 0 1")
+		if _, err := p.ParseMove("O-O"); err == nil {
+			t.Error("Castling through an attacked square should be illegal")
+		}
+		if _, err := p.ParseMove("O-O-O"); err != nil {
+			t.Errorf("Queen side castling should be legal: %v", err)
+		}
+	})
+	t.Run("en passant", func(t *testing.T) {
+		duel, _ := NewChessDuel([]turnbased.PlayerID{"white", "black"})
+		play(t, duel, "e4", "a6", "e5", "d5", "exd6")
+		if duel.History[4] != "exd6" {
+			t.Errorf("Expected en passant SAN exd6, got %s", duel.History[4])
+		}
+		if piece := duel.Position.Board[newSquare(3, 4)]; piece.Type != NoPiece {
+			t.Errorf("Captured pawn on d5 should be removed, got %+v", piece)
+		}
+	})
+	t.Run("promotion", func(t *testing.T) {
+		duel, _ := NewChessDuelFromFEN([]turnbased.PlayerID{"white", "black"}, "7k/P7/8/8/8/8/8/K7 w - - 0 1")
+		play(t, duel, "a8=N")
+		if piece := duel.Position.Board[newSquare(0, 7)]; piece.Type != Knight || piece.Color != White {
+			t.Errorf("Expected white knight on a8, got %+v", piece)
+		}
+		if duel.Duel.State != turnbased.DuelStateEnd || duel.DrawReason != DrawReasonInsufficientMaterial {
+			t.Errorf("K+N vs K should be a draw, got %s %s", duel.Duel.State, duel.DrawReason)
+		}
+	})
+}
+
+func TestDuelEnd(t *testing.T) {
+	t.Run("checkmate", func(t *testing.T) {
+		duel, _ := NewChessDuel([]turnbased.PlayerID{"white", "black"})
+		play(t, duel, "f3", "e5", "g4", "Qh4")
+		if duel.History[3] != "Qh4#" {
+			t.Errorf("Expected Qh4#, got %s", duel.History[3])
+		}
+		if duel.Duel.State != turnbased.DuelStateEnd || duel.Duel.Winner != "black" ||
+			duel.Duel.EndReason != EndReasonCheckmate {
+			t.Errorf("Black should win by checkmate, got %s winner %s reason %s", duel.Duel.State, duel.Duel.Winner, duel.Duel.EndReason)
+		}
+		if err := duel.MakeMove("white", "a3"); err == nil {
+			t.Error("MakeMove should fail after the duel ended")
+		}
+	})
+	t.Run("stalemate", func(t *testing.T) {
+		duel, _ := NewChessDuelFromFEN([]turnbased.PlayerID{"white", "black"}, "7k/8/6Q1/8/8/8/8/K7 w - - 0 1")
+		play(t, duel, "Qf7")
+		if duel.Duel.Winner != "DRAW" || duel.DrawReason != DrawReasonStalemate || duel.Duel.EndReason != string(DrawReasonStalemate) {
+			t.Errorf("Expected stalemate draw, got %s %s", duel.Duel.Winner, duel.DrawReason)
+		}
+	})
+	t.Run("threefold repetition", func(t *testing.T) {
+		duel, _ := NewChessDuel([]turnbased.PlayerID{"white", "black"})
+		play(t, duel, "Nf3", "Nf6", "Ng1", "Ng8", "Nf3", "Nf6", "Ng1")
+		if duel.Duel.State != turnbased.DuelStateRunning {
+			t.Fatalf("Start position occurred twice only, duel should go on")
+		}
+		play(t, duel, "Ng8")
+		if duel.Duel.Winner != "DRAW" || duel.DrawReason != DrawReasonThreefoldRepetition {
+			t.Errorf("Expected threefold repetition draw, got %s %s", duel.Duel.Winner, duel.DrawReason)
+		}
+	})
+	t.Run("fifty-move rule", func(t *testing.T) {
+		duel, _ := NewChessDuelFromFEN([]turnbased.PlayerID{"white", "black"}, "7k/8/8/8/8/8/R7/K7 w - - 99 80")
+		play(t, duel, "Ra3")
+		if duel.Duel.Winner != "DRAW" || duel.DrawReason != DrawReasonFiftyMoveRule {
+			t.Errorf("Expected fifty-move rule draw, got %s %s", duel.Duel.Winner, duel.DrawReason)
+		}
+	})
+}
+
+func TestSANDisambiguation(t *testing.T) {
+	p, _ := ParseFEN("7k/8/8/8/8/8/8/R4RK1 w - - 0 1")
+	m, err := p.ParseMove("f1d1")
+	if err != nil {
+		t.Fatalf("ParseMove failed: %v", err)
+	}
+	if san := p.SAN(m); san != "Rfd1" {
+		t.Errorf("Expected Rfd1, got %s", san)
+	}
+	p, _ = ParseFEN("4k3/8/8/8/R7/8/8/R3K3 w - - 0 1")
+	m, _ = p.ParseMove("a1a2")
+	if san := p.SAN(m); san != "R1a2" {
+		t.Errorf("Expected R1a2, got %s", san)
+	}
+}
+
+func TestGetState(t *testing.T) {
+	duel, _ := NewChessDuel([]turnbased.PlayerID{"white", "black"})
+	play(t, duel, "e4")
+	state, ok := duel.GetState().(model.ChessGameState)
+	if !ok {
+		t.Fatalf("GetState should return model.ChessGameState, got %T", duel.GetState())
+	}
+	if state.Board[3][4] != "P" || state.SideToMove != "BLACK" || len(state.LegalMoves) != 20 {
+		t.Errorf("Unexpected state %+v", state)
+	}
+	if state.Colors["white"] != "WHITE" || state.LastMove != "e2e4" {
+		t.Errorf("Unexpected colors %v or last move %s", state.Colors, state.LastMove)
+	}
+}
@@ -0,0 +1,69 @@
+package chess
+
+import (
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// Ensure ChessDuel implements GameLogic interface
+var _ turnbased.GameLogic = (*ChessDuel)(nil)
+
+// GetState returns the game-specific state as JSON-serializable data
+// Returns model.ChessGameState for type safety
+func (cd *ChessDuel) GetState() any {
+	return cd.ToModelChessGameState()
+}
+
+// HandleAction processes a game action, the turn player is assumed to be the actor
+func (cd *ChessDuel) HandleAction(action any) error {
+	return cd.HandleActionWithPlayer(action, cd.Duel.TurnPlayer)
+}
+
+// HandleActionWithPlayer processes a game action with player context
+func (cd *ChessDuel) HandleActionWithPlayer(action any, playerID turnbased.PlayerID) error {
+	switch a := action.(type) {
+	case ActionMove:
+		return cd.MakeMove(playerID, a.Move)
+	default:
+		return fmt.Errorf("unknown action type: %T", action)
+	}
+}
+
+// ToModelChessGameState converts a ChessDuel to model.ChessGameState
+func (cd *ChessDuel) ToModelChessGameState() model.ChessGameState {
+	board := make([][]string, 8)
+	for rank := range board {
+		board[rank] = make([]string, 8)
+		for file := range board[rank] {
+			board[rank][file] = cd.Position.Board[newSquare(file, rank)].Letter()
+		}
+	}
+	colors := make(map[string]string, len(cd.Colors))
+	for pid, c := range cd.Colors {
+		colors[string(pid)] = c.String()
+	}
+	legalMoves := []string{}
+	if cd.Duel.State == turnbased.DuelStateRunning {
+		for _, m := range cd.Position.LegalMoves() {
+			legalMoves = append(legalMoves, m.UCI())
+		}
+	}
+	history := make([]string, len(cd.History))
+	copy(history, cd.History)
+	state := model.ChessGameState{
+		FEN:        cd.Position.FEN(),
+		Board:      board,
+		Colors:     colors,
+		SideToMove: cd.Position.SideToMove.String(),
+		InCheck:    cd.Position.InCheck(),
+		LegalMoves: legalMoves,
+		History:    history,
+		DrawReason: string(cd.DrawReason),
+	}
+	if cd.LastMove != nil {
+		state.LastMove = cd.LastMove.UCI()
+	}
+	return state
+}
@@ -0,0 +1,309 @@
+package chess
+
+import (
+	"github.com/daominah/turn_based_game/internal/core/board"
+)
+
+// Move from a square to another, Promotion is the piece a pawn promotes to
+// when reaching the last rank, NoPiece otherwise.
+// Castling is a king move of 2 files, en passant is a pawn move onto Position.EnPassant.
+type Move struct {
+	From      Square
+	To        Square
+	Promotion PieceType
+}
+
+// UCI returns the move in UCI long algebraic notation, e.g. "e2e4" or "e7e8q"
+func (m Move) UCI() string {
+	s := m.From.String() + m.To.String()
+	if m.Promotion != NoPiece {
+		s += string(pieceLetters[m.Promotion] + 'a' - 'A')
+	}
+	return s
+}
+
+// piece movements on the board, ranks are rows and files are columns
+var (
+	knightSteps    = board.Knight
+	kingSteps      = board.Adjacent
+	bishopRays     = board.Diagonal
+	rookRays       = board.Orthogonal
+	promotionTypes = []PieceType{Queen, Rook, Bishop, Knight}
+)
+
+// offset returns the square moved in the direction, false if off the board
+func offset(sq Square, d board.Direction) (Square, bool) {
+	return squareAt(sq.Coord().Add(d))
+}
+
+// pawnStep is the direction of a pawn of color c moving df files sideways (capturing) or straight
+func pawnStep(c Color, df int) board.Direction {
+	return board.Direction{DRow: pawnDirection(c), DColumn: df}
+}
+
+// pawnDirection is +1 rank for White, -1 for Black
+func pawnDirection(c Color) int {
+	if c == White {
+		return 1
+	}
+	return -1
+}
+
+// IsAttacked returns true if a piece of color by attacks the square
+func (p *Position) IsAttacked(sq Square, by Color) bool {
+	// a pawn of color by attacks sq if it stands diagonally behind sq from its point of view
+	for _, df := range []int{-1, 1} {
+		if from, ok := offset(sq, pawnStep(by, df).Opposite()); ok {
+			if piece := p.Board[from]; piece.Type == Pawn && piece.Color == by {
+				return true
+			}
+		}
+	}
+	for _, step := range knightSteps {
+		if from, ok := offset(sq, step); ok {
+			if piece := p.Board[from]; piece.Type == Knight && piece.Color == by {
+				return true
+			}
+		}
+	}
+	for _, step := range kingSteps {
+		if from, ok := offset(sq, step); ok {
+			if piece := p.Board[from]; piece.Type == King && piece.Color == by {
+				return true
+			}
+		}
+	}
+	for _, rays := range []struct {
+		dirs   []board.Direction
+		slider PieceType
+	}{{bishopRays, Bishop}, {rookRays, Rook}} {
+		for _, dir := range rays.dirs {
+			from, ok := offset(sq, dir)
+			for ok {
+				piece := p.Board[from]
+				if piece.Type != NoPiece {
+					if piece.Color == by && (piece.Type == rays.slider || piece.Type == Queen) {
+						return true
+					}
+					break
+				}
+				from, ok = offset(from, dir)
+			}
+		}
+	}
+	return false
+}
+
+// LegalMoves returns all legal moves of the side to move
+func (p *Position) LegalMoves() []Move {
+	var legal []Move
+	for _, m := range p.pseudoLegalMoves() {
+		next := p.Apply(m)
+		king := next.kingSquare(p.SideToMove)
+		if king != NoSquare && next.IsAttacked(king, next.SideToMove) {
+			continue
+		}
+		legal = append(legal, m)
+	}
+	return legal
+}
+
+// pseudoLegalMoves returns the moves that follow piece movement rules,
+// ignoring whether the mover's king is left in check (except for castling)
+func (p *Position) pseudoLegalMoves() []Move {
+	var moves []Move
+	us := p.SideToMove
+	for i, piece := range p.Board {
+		from := Square(i)
+		if piece.Type == NoPiece || piece.Color != us {
+			continue
+		}
+		switch piece.Type {
+		case Pawn:
+			moves = p.appendPawnMoves(moves, from)
+		case Knight:
+			moves = p.appendStepMoves(moves, from, knightSteps)
+		case Bishop:
+			moves = p.appendRayMoves(moves, from, bishopRays)
+		case Rook:
+			moves = p.appendRayMoves(moves, from, rookRays)
+		case Queen:
+			moves = p.appendRayMoves(moves, from, bishopRays)
+			moves = p.appendRayMoves(moves, from, rookRays)
+		case King:
+			moves = p.appendStepMoves(moves, from, kingSteps)
+			moves = p.appendCastlingMoves(moves, from)
+		}
+	}
+	return moves
+}
+
+func (p *Position) appendPawnMoves(moves []Move, from Square) []Move {
+	us := p.SideToMove
+	lastRank := 7
+	startRank := 1
+	if us == Black {
+		lastRank, startRank = 0, 6
+	}
+	add := func(to Square) {
+		if to.Rank() == lastRank {
+			for _, promotion := range promotionTypes {
+				moves = append(moves, Move{From: from, To: to, Promotion: promotion})
+			}
+			return
+		}
+		moves = append(moves, Move{From: from, To: to})
+	}
+	if to, ok := offset(from, pawnStep(us, 0)); ok && p.Board[to].Type == NoPiece {
+		add(to)
+		if from.Rank() == startRank {
+			if to2, ok := offset(from, pawnStep(us, 0).Scale(2)); ok && p.Board[to2].Type == NoPiece {
+				add(to2)
+			}
+		}
+	}
+	for _, df := range []int{-1, 1} {
+		to, ok := offset(from, pawnStep(us, df))
+		if !ok {
+			continue
+		}
+		target := p.Board[to]
+		if (target.Type != NoPiece && target.Color != us) || to == p.EnPassant {
+			add(to)
+		}
+	}
+	return moves
+}
+
+func (p *Position) appendStepMoves(moves []Move, from Square, steps []board.Direction) []Move {
+	for _, step := range steps {
+		to, ok := offset(from, step)
+		if !ok {
+			continue
+		}
+		if target := p.Board[to]; target.Type == NoPiece || target.Color != p.SideToMove {
+			moves = append(moves, Move{From: from, To: to})
+		}
+	}
+	return moves
+}
+
+func (p *Position) appendRayMoves(moves []Move, from Square, rays []board.Direction) []Move {
+	for _, dir := range rays {
+		to, ok := offset(from, dir)
+		for ok {
+			target := p.Board[to]
+			if target.Type != NoPiece {
+				if target.Color != p.SideToMove {
+					moves = append(moves, Move{From: from, To: to})
+				}
+				break
+			}
+			moves = append(moves, Move{From: from, To: to})
+			to, ok = offset(to, dir)
+		}
+	}
+	return moves
+}
+
+// castlingRule describes a castling: the right needed, the king and rook squares,
+// the squares that must be empty and the squares the king passes that must not be attacked
+type castlingRule struct {
+	right     CastlingRights
+	kingFrom  Square
+	kingTo    Square
+	rookFrom  Square
+	rookTo    Square
+	empty     []Square
+	notAttack []Square
+}
+
+var castlingRules = []castlingRule{
+	{WhiteKingSide, 4, 6, 7, 5, []Square{5, 6}, []Square{4, 5, 6}},
+	{WhiteQueenSide, 4, 2, 0, 3, []Square{1, 2, 3}, []Square{4, 3, 2}},
+	{BlackKingSide, 60, 62, 63, 61, []Square{61, 62}, []Square{60, 61, 62}},
+	{BlackQueenSide, 60, 58, 56, 59, []Square{57, 58, 59}, []Square{60, 59, 58}},
+}
+
+func (p *Position) appendCastlingMoves(moves []Move, from Square) []Move {
+	them := p.SideToMove.Opponent()
+rules:
+	for _, rule := range castlingRules {
+		if p.Castling&rule.right == 0 || rule.kingFrom != from {
+			continue
+		}
+		if rook := p.Board[rule.rookFrom]; rook.Type != Rook || rook.Color != p.SideToMove {
+			continue
+		}
+		for _, sq := range rule.empty {
+			if p.Board[sq].Type != NoPiece {
+				continue rules
+			}
+		}
+		for _, sq := range rule.notAttack {
+			if p.IsAttacked(sq, them) {
+				continue rules
+			}
+		}
+		moves = append(moves, Move{From: rule.kingFrom, To: rule.kingTo})
+	}
+	return moves
+}
+
+// isCastling returns true if the move is a king moving 2 files
+func (p *Position) isCastling(m Move) bool {
+	diff := m.To.File() - m.From.File()
+	return p.Board[m.From].Type == King && (diff == 2 || diff == -2)
+}
+
+// isEnPassant returns true if the move is a pawn capturing en passant
+func (p *Position) isEnPassant(m Move) bool {
+	return p.Board[m.From].Type == Pawn && m.To == p.EnPassant && m.From.File() != m.To.File()
+}
+
+// Apply returns the position after the move, the move is assumed to be pseudo-legal
+func (p *Position) Apply(m Move) Position {
+	next := *p
+	piece := p.Board[m.From]
+	captured := p.Board[m.To]
+	next.Board[m.From] = Piece{}
+	next.Board[m.To] = piece
+
+	if p.isEnPassant(m) {
+		captured = p.Board[newSquare(m.To.File(), m.From.Rank())]
+		next.Board[newSquare(m.To.File(), m.From.Rank())] = Piece{}
+	}
+	if p.isCastling(m) {
+		for _, rule := range castlingRules {
+			if rule.kingFrom == m.From && rule.kingTo == m.To {
+				next.Board[rule.rookTo] = next.Board[rule.rookFrom]
+				next.Board[rule.rookFrom] = Piece{}
+			}
+		}
+	}
+	if m.Promotion != NoPiece {
+		next.Board[m.To] = Piece{Type: m.Promotion, Color: piece.Color}
+	}
+
+	next.EnPassant = NoSquare
+	if piece.Type == Pawn && (m.To.Rank()-m.From.Rank() == 2 || m.From.Rank()-m.To.Rank() == 2) {
+		next.EnPassant = newSquare(m.From.File(), (m.From.Rank()+m.To.Rank())/2)
+	}
+
+	// moving the king or a rook, or capturing a rook on its corner, loses castling rights
+	for _, rule := range castlingRules {
+		if m.From == rule.kingFrom || m.From == rule.rookFrom || m.To == rule.rookFrom {
+			next.Castling &^= rule.right
+		}
+	}
+
+	next.HalfmoveClock++
+	if piece.Type == Pawn || captured.Type != NoPiece {
+		next.HalfmoveClock = 0
+	}
+	if p.SideToMove == Black {
+		next.FullmoveNumber++
+	}
+	next.SideToMove = p.SideToMove.Opponent()
+	return next
+}
@@ -0,0 +1,96 @@
+package chess
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SAN returns the move in Standard Algebraic Notation, e.g. "Nf3", "exd5", "O-O", "e8=Q+",
+// the move must be legal in the position
+func (p *Position) SAN(m Move) string {
+	var san string
+	piece := p.Board[m.From]
+	switch {
+	case p.isCastling(m) && m.To.File() == 6:
+		san = "O-O"
+	case p.isCastling(m):
+		san = "O-O-O"
+	default:
+		isCapture := p.Board[m.To].Type != NoPiece || p.isEnPassant(m)
+		if piece.Type == Pawn {
+			if isCapture {
+				san = string(rune('a' + m.From.File()))
+			}
+		} else {
+			san = string(pieceLetters[piece.Type]) + p.disambiguation(m)
+		}
+		if isCapture {
+			san += "x"
+		}
+		san += m.To.String()
+		if m.Promotion != NoPiece {
+			san += "=" + string(pieceLetters[m.Promotion])
+		}
+	}
+	next := p.Apply(m)
+	if next.InCheck() {
+		if len(next.LegalMoves()) == 0 {
+			san += "#"
+		} else {
+			san += "+"
+		}
+	}
+	return san
+}
+
+// disambiguation returns the file, rank or square of the origin needed when another piece
+// of the same type could also move to the same square
+func (p *Position) disambiguation(m Move) string {
+	piece := p.Board[m.From]
+	sameFile, sameRank, ambiguous := false, false, false
+	for _, other := range p.LegalMoves() {
+		if other.To != m.To || other.From == m.From || p.Board[other.From] != piece {
+			continue
+		}
+		ambiguous = true
+		if other.From.File() == m.From.File() {
+			sameFile = true
+		}
+		if other.From.Rank() == m.From.Rank() {
+			sameRank = true
+		}
+	}
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameFile:
+		return string(rune('a' + m.From.File()))
+	case !sameRank:
+		return string(rune('1' + m.From.Rank()))
+	default:
+		return m.From.String()
+	}
+}
+
+// ParseMove finds the legal move written in UCI ("e2e4", "e7e8q") or SAN ("e4", "Nf3", "O-O"),
+// check and annotation symbols are optional
+func (p *Position) ParseMove(text string) (Move, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Move{}, fmt.Errorf("empty move")
+	}
+	legal := p.LegalMoves()
+	for _, m := range legal {
+		if m.UCI() == strings.ToLower(text) {
+			return m, nil
+		}
+	}
+	normalized := strings.TrimRight(text, "+#!?")
+	normalized = strings.ReplaceAll(normalized, "0", "O")
+	for _, m := range legal {
+		if strings.TrimRight(p.SAN(m), "+#") == normalized {
+			return m, nil
+		}
+	}
+	return Move{}, fmt.Errorf("illegal or unknown move %q", text)
+}
@@ -0,0 +1,334 @@
+package chess
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/daominah/turn_based_game/internal/core/board"
+)
+
+// Color of a piece or of the side to move
+type Color int
+
+// Color enum
+const (
+	White Color = 0
+	Black Color = 1
+)
+
+// Opponent returns the other color
+func (c Color) Opponent() Color {
+	return 1 - c
+}
+
+func (c Color) String() string {
+	if c == White {
+		return "WHITE"
+	}
+	return "BLACK"
+}
+
+type PieceType int // PieceType is the kind of chess piece
+
+// PieceType enum
+const (
+	NoPiece PieceType = iota
+	Pawn
+	Knight
+	Bishop
+	Rook
+	Queen
+	King
+)
+
+// Piece on a square, Type is NoPiece for an empty square
+type Piece struct {
+	Type  PieceType
+	Color Color
+}
+
+// pieceLetters are the FEN letters of white pieces, black pieces use lower case
+const pieceLetters = " PNBRQK"
+
+// Letter returns the FEN letter of the piece: upper case for white, lower case for black,
+// empty string for an empty square
+func (p Piece) Letter() string {
+	if p.Type == NoPiece {
+		return ""
+	}
+	letter := string(pieceLetters[p.Type])
+	if p.Color == Black {
+		return strings.ToLower(letter)
+	}
+	return letter
+}
+
+// Square index from 0 (a1) to 63 (h8), index = rank*8 + file
+type Square int
+
+// NoSquare is used when there is no en passant target square
+const NoSquare Square = -1
+
+func newSquare(file int, rank int) Square {
+	return Square(rank*8 + file)
+}
+
+func (s Square) File() int { return int(s) % 8 }
+func (s Square) Rank() int { return int(s) / 8 }
+
+// Coord returns the square as a board coordinate, the rank is the row and the file is the column
+func (s Square) Coord() board.Coord {
+	return board.Coord{Row: s.Rank(), Column: s.File()}
+}
+
+// squareAt returns the square at the board coordinate, false if off the board
+func squareAt(c board.Coord) (Square, bool) {
+	if c.Row < 0 || c.Row > 7 || c.Column < 0 || c.Column > 7 {
+		return NoSquare, false
+	}
+	return newSquare(c.Column, c.Row), true
+}
+
+// String returns the algebraic name of the square, e.g. "e4"
+func (s Square) String() string {
+	if s == NoSquare {
+		return "-"
+	}
+	return string(rune('a'+s.File())) + string(rune('1'+s.Rank()))
+}
+
+// ParseSquare parses an algebraic square name, e.g. "e4"
+func ParseSquare(name string) (Square, error) {
+	if len(name) != 2 || name[0] < 'a' || name[0] > 'h' || name[1] < '1' || name[1] > '8' {
+		return NoSquare, fmt.Errorf("invalid square %q", name)
+	}
+	return newSquare(int(name[0]-'a'), int(name[1]-'1')), nil
+}
+
+// CastlingRights is a bit set of the remaining castling possibilities
+type CastlingRights int
+
+// CastlingRights bits
+const (
+	WhiteKingSide CastlingRights = 1 << iota
+	WhiteQueenSide
+	BlackKingSide
+	BlackQueenSide
+)
+
+// Position is a full chess position, as described by FEN
+type Position struct {
+	Board          [64]Piece
+	SideToMove     Color
+	Castling       CastlingRights
+	EnPassant      Square // square a pawn can capture en passant onto, NoSquare if none
+	HalfmoveClock  int    // plies since the last capture or pawn move, for the 50-move rule
+	FullmoveNumber int    // starts at 1, incremented after Black moves
+}
+
+// StartFEN is the standard starting position
+const StartFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// ParseFEN parses a position in Forsyth–Edwards Notation,
+// the move counters are optional
+func ParseFEN(fen string) (Position, error) {
+	var p Position
+	fields := strings.Fields(fen)
+	if len(fields) < 4 {
+		return p, fmt.Errorf("invalid FEN %q: need at least 4 fields", fen)
+	}
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 8 {
+		return p, fmt.Errorf("invalid FEN %q: need 8 ranks", fen)
+	}
+	for i, rankText := range ranks {
+		rank := 7 - i
+		file := 0
+		for _, ch := range rankText {
+			if ch >= '1' && ch <= '8' {
+				file += int(ch - '0')
+				continue
+			}
+			idx := strings.IndexRune(pieceLetters, ch)
+			color := White
+			if idx <= 0 {
+				idx = strings.IndexRune(strings.ToLower(pieceLetters), ch)
+				color = Black
+			}
+			if idx <= 0 || file > 7 {
+				return p, fmt.Errorf("invalid FEN %q: bad rank %q", fen, rankText)
+			}
+			p.Board[newSquare(file, rank)] = Piece{Type: PieceType(idx), Color: color}
+			file++
+		}
+		if file != 8 {
+			return p, fmt.Errorf("invalid FEN %q: bad rank %q", fen, rankText)
+		}
+	}
+	switch fields[1] {
+	case "w":
+		p.SideToMove = White
+	case "b":
+		p.SideToMove = Black
+	default:
+		return p, fmt.Errorf("invalid FEN %q: bad side to move", fen)
+	}
+	if fields[2] != "-" {
+		for _, ch := range fields[2] {
+			switch ch {
+			case 'K':
+				p.Castling |= WhiteKingSide
+			case 'Q':
+				p.Castling |= WhiteQueenSide
+			case 'k':
+				p.Castling |= BlackKingSide
+			case 'q':
+				p.Castling |= BlackQueenSide
+			default:
+				return p, fmt.Errorf("invalid FEN %q: bad castling rights", fen)
+			}
+		}
+	}
+	p.EnPassant = NoSquare
+	if fields[3] != "-" {
+		sq, err := ParseSquare(fields[3])
+		if err != nil {
+			return p, fmt.Errorf("invalid FEN %q: %v", fen, err)
+		}
+		if !p.validEnPassant(sq) {
+			return p, fmt.Errorf("invalid FEN %q: no pawn just moved two squares past %v", fen, sq)
+		}
+		p.EnPassant = sq
+	}
+	p.FullmoveNumber = 1
+	if len(fields) >= 6 {
+		halfmove, err1 := strconv.Atoi(fields[4])
+		fullmove, err2 := strconv.Atoi(fields[5])
+		if err1 != nil || err2 != nil {
+			return p, fmt.Errorf("invalid FEN %q: bad move counters", fen)
+		}
+		p.HalfmoveClock, p.FullmoveNumber = halfmove, fullmove
+	}
+	return p, nil
+}
+
+// validEnPassant returns true if the pawn of the side that just moved can have passed the square
+// with a two-square move: the square is on the 3rd rank (6th if white is to move), empty,
+// between the pawn and its empty starting square
+func (p *Position) validEnPassant(sq Square) bool {
+	rank, forward := 5, -1 // black moved from the 7th rank to the 5th
+	if p.SideToMove == Black {
+		rank, forward = 2, 1
+	}
+	if sq.Rank() != rank {
+		return false
+	}
+	moved := p.SideToMove.Opponent()
+	return p.Board[sq] == Piece{} &&
+		p.Board[newSquare(sq.File(), rank-forward)] == Piece{} &&
+		p.Board[newSquare(sq.File(), rank+forward)] == Piece{Type: Pawn, Color: moved}
+}
+
+// FEN returns the position in Forsyth–Edwards Notation
+func (p *Position) FEN() string {
+	return fmt.Sprintf("%s %d %d", p.placementKey(true), p.HalfmoveClock, p.FullmoveNumber)
+}
+
+// RepetitionKey identifies the position for the threefold repetition rule:
+// same pieces, side to move, castling rights and en passant possibility
+func (p *Position) RepetitionKey() string {
+	return p.placementKey(false)
+}
+
+// placementKey is the first 4 fields of FEN, if rawEnPassant is false,
+// the en passant square is only included when an en passant capture is legal
+func (p *Position) placementKey(rawEnPassant bool) string {
+	var b strings.Builder
+	for rank := 7; rank >= 0; rank-- {
+		empty := 0
+		for file := 0; file < 8; file++ {
+			piece := p.Board[newSquare(file, rank)]
+			if piece.Type == NoPiece {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				b.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			b.WriteString(piece.Letter())
+		}
+		if empty > 0 {
+			b.WriteString(strconv.Itoa(empty))
+		}
+		if rank > 0 {
+			b.WriteByte('/')
+		}
+	}
+	if p.SideToMove == White {
+		b.WriteString(" w ")
+	} else {
+		b.WriteString(" b ")
+	}
+	castling := ""
+	for _, c := range []struct {
+		right  CastlingRights
+		letter string
+	}{{WhiteKingSide, "K"}, {WhiteQueenSide, "Q"}, {BlackKingSide, "k"}, {BlackQueenSide, "q"}} {
+		if p.Castling&c.right != 0 {
+			castling += c.letter
+		}
+	}
+	if castling == "" {
+		castling = "-"
+	}
+	b.WriteString(castling)
+	enPassant := p.EnPassant
+	if !rawEnPassant && enPassant != NoSquare && !p.hasEnPassantCapture() {
+		enPassant = NoSquare
+	}
+	b.WriteString(" " + enPassant.String())
+	return b.String()
+}
+
+func (p *Position) hasEnPassantCapture() bool {
+	for _, m := range p.LegalMoves() {
+		if m.To == p.EnPassant && p.Board[m.From].Type == Pawn {
+			return true
+		}
+	}
+	return false
+}
+
+// kingSquare returns the square of the king of the color, NoSquare if there is none
+func (p *Position) kingSquare(c Color) Square {
+	for sq, piece := range p.Board {
+		if piece.Type == King && piece.Color == c {
+			return Square(sq)
+		}
+	}
+	return NoSquare
+}
+
+// InCheck returns true if the side to move is in check
+func (p *Position) InCheck() bool {
+	king := p.kingSquare(p.SideToMove)
+	return king != NoSquare && p.IsAttacked(king, p.SideToMove.Opponent())
+}
+
+// InsufficientMaterial returns true if neither side can possibly checkmate:
+// king against king, or king and a single bishop or knight against king
+func (p *Position) InsufficientMaterial() bool {
+	minors := 0
+	for _, piece := range p.Board {
+		switch piece.Type {
+		case NoPiece, King:
+		case Knight, Bishop:
+			minors++
+		default:
+			return false
+		}
+	}
+	return minors <= 1
+}
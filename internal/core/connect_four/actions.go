@@ -0,0 +1,25 @@
+package connect_four
+
+import (
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// ActionDropDisc represents an action to drop a disc into a column (0-based)
+type ActionDropDisc struct {
+	Column int
+}
+
+// Implement turnbased.Action interface for ActionDropDisc
+func (a ActionDropDisc) GameName() string {
+	return GameName
+}
+
+func (a ActionDropDisc) DuelID() turnbased.DuelID {
+	// DuelID will be set by the handler from the message context
+	return ""
+}
+
+func (a ActionDropDisc) PlayerID() turnbased.PlayerID {
+	// PlayerID will be set by the handler from the message context
+	return ""
+}
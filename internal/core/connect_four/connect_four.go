@@ -0,0 +1,93 @@
+// Package connect_four implements the Connect Four board game on the generic turn-based engine,
+// it is the second built-in game to show that the engine is game-agnostic
+package connect_four
+
+import (
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/core/board"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+const GameName = "CONNECT_FOUR"
+
+// Board size and number of discs in a line to win
+const (
+	Columns   = 7
+	Rows      = 6
+	WinLength = 4
+)
+
+type ConnectFourDuel struct {
+	Duel *turnbased.Duel
+	// Board holds the player who owns the disc in each cell, empty string for an empty cell.
+	// Row 0 is the bottom row, discs dropped in a column fall to the lowest empty row.
+	Board *board.Grid[turnbased.PlayerID]
+	// WinningLine is the cells of the line that won the duel, empty if no winner
+	WinningLine []board.Coord
+}
+
+// NewConnectFourDuel creates a duel for exactly 2 players, the first player in the list drops first
+func NewConnectFourDuel(players []turnbased.PlayerID) (*ConnectFourDuel, error) {
+	if len(players) != 2 {
+		return nil, fmt.Errorf("connect four needs exactly 2 players, got %d", len(players))
+	}
+	if players[0] == players[1] {
+		return nil, fmt.Errorf("players must be different")
+	}
+	duel := &ConnectFourDuel{
+		Duel:  turnbased.NewDuel("", players),
+		Board: board.NewGrid[turnbased.PlayerID](Rows, Columns),
+	}
+	duel.Duel.TurnPlayer = players[0]
+	duel.Duel.Turn = 1
+	duel.Duel.State = turnbased.DuelStateRunning
+	return duel, nil
+}
+
+// DropDisc drops the player's disc into a column (0-based), then checks for win or draw
+// and advances the turn if the duel goes on.
+func (c4 *ConnectFourDuel) DropDisc(player turnbased.PlayerID, column int) error {
+	if c4.Duel.State != turnbased.DuelStateRunning {
+		return turnbased.ErrDuelNotRunning
+	}
+	if c4.Duel.TurnPlayer != player {
+		return turnbased.ErrNotPlayerTurn
+	}
+	if column < 0 || column >= Columns {
+		return fmt.Errorf("column %d out of range [0, %d)", column, Columns)
+	}
+	row := c4.lowestEmptyRow(column)
+	if row == -1 {
+		return fmt.Errorf("column %d is full", column)
+	}
+	cell := board.Coord{Row: row, Column: column}
+	c4.Board.Set(cell, player)
+
+	c4.Duel.LogAction(player, "DROP_DISC", map[string]interface{}{
+		"column": column,
+		"row":    row,
+	})
+
+	if line := c4.Board.LineOfLength(cell, WinLength); line != nil {
+		c4.WinningLine = line
+		c4.Duel.SetWinner(player)
+		return nil
+	}
+	if c4.Board.IsFull() {
+		c4.Duel.SetDraw()
+		return nil
+	}
+	c4.Duel.NextTurn()
+	return nil
+}
+
+// lowestEmptyRow returns the row a disc dropped in the column lands on, -1 if the column is full
+func (c4 *ConnectFourDuel) lowestEmptyRow(column int) int {
+	for row := 0; row < Rows; row++ {
+		if c4.Board.IsEmpty(board.Coord{Row: row, Column: column}) {
+			return row
+		}
+	}
+	return -1
+}
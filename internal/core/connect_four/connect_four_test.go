@@ -0,0 +1,131 @@
+package connect_four
+
+import (
+	"testing"
+
+	"github.com/daominah/turn_based_game/internal/core/board"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// dropAll drops discs alternately for the turn player in the given columns
+func dropAll(t *testing.T, duel *ConnectFourDuel, columns ...int) {
+	t.Helper()
+	for _, column := range columns {
+		if err := duel.DropDisc(duel.Duel.TurnPlayer, column); err != nil {
+			t.Fatalf("DropDisc column %d failed: %v", column, err)
+		}
+	}
+}
+
+func TestNewConnectFourDuel(t *testing.T) {
+	duel, err := NewConnectFourDuel([]turnbased.PlayerID{"player1", "player2"})
+	if err != nil {
+		t.Fatalf("NewConnectFourDuel failed: %v", err)
+	}
+	if duel.Duel.State != turnbased.DuelStateRunning {
+		t.Errorf("Expected state RUNNING, got %s", duel.Duel.State)
+	}
+	if duel.Duel.TurnPlayer != "player1" {
+		t.Errorf("Expected player1 to drop first, got %s", duel.Duel.TurnPlayer)
+	}
+
+	if _, err := NewConnectFourDuel([]turnbased.PlayerID{"player1"}); err == nil {
+		t.Error("NewConnectFourDuel should fail with 1 player")
+	}
+	if _, err := NewConnectFourDuel([]turnbased.PlayerID{"player1", "player1"}); err == nil {
+		t.Error("NewConnectFourDuel should fail with duplicated players")
+	}
+}
+
+func TestDropDisc(t *testing.T) {
+	duel, _ := NewConnectFourDuel([]turnbased.PlayerID{"player1", "player2"})
+
+	if err := duel.DropDisc("player2", 3); err == nil {
+		t.Error("DropDisc should fail for non-turn player")
+	}
+	if err := duel.DropDisc("player1", Columns); err == nil {
+		t.Error("DropDisc should fail for out of range column")
+	}
+
+	dropAll(t, duel, 3, 3)
+	bottom, above := board.Coord{Row: 0, Column: 3}, board.Coord{Row: 1, Column: 3}
+	if duel.Board.At(bottom) != "player1" || duel.Board.At(above) != "player2" {
+		t.Errorf("Discs should stack in column 3, got %v %v", duel.Board.At(bottom), duel.Board.At(above))
+	}
+	if duel.Duel.Turn != 3 || duel.Duel.TurnPlayer != "player1" {
+		t.Errorf("Expected turn 3 of player1, got %d of %s", duel.Duel.Turn, duel.Duel.TurnPlayer)
+	}
+	if len(duel.Duel.ActionLog) != 2 || duel.Duel.ActionLog[1].Action != "DROP_DISC" {
+		t.Errorf("Expected 2 DROP_DISC log entries, got %+v", duel.Duel.ActionLog)
+	}
+
+	dropAll(t, duel, 3, 3, 3, 3)
+	if err := duel.DropDisc(duel.Duel.TurnPlayer, 3); err == nil {
+		t.Error("DropDisc should fail for a full column")
+	}
+}
+
+func TestDropDisc_Win(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []int
+	}{
+		{"horizontal", []int{0, 0, 1, 1, 2, 2, 3}},
+		{"vertical", []int{0, 1, 0, 1, 0, 1, 0}},
+		{"diagonal up", []int{0, 1, 1, 2, 2, 3, 2, 3, 3, 6, 3}},
+		{"diagonal down", []int{6, 5, 5, 4, 4, 3, 4, 3, 3, 0, 3}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			duel, _ := NewConnectFourDuel([]turnbased.PlayerID{"player1", "player2"})
+			dropAll(t, duel, tc.columns...)
+			if duel.Duel.State != turnbased.DuelStateEnd {
+				t.Fatalf("Duel should have ended")
+			}
+			if duel.Duel.Winner != "player1" {
+				t.Errorf("Expected winner player1, got %s", duel.Duel.Winner)
+			}
+			if len(duel.WinningLine) < WinLength {
+				t.Errorf("Expected winning line of %d cells, got %v", WinLength, duel.WinningLine)
+			}
+			if err := duel.DropDisc(duel.Duel.TurnPlayer, 5); err == nil {
+				t.Error("DropDisc should fail after the duel ended")
+			}
+		})
+	}
+}
+
+func TestDropDisc_Draw(t *testing.T) {
+	duel, _ := NewConnectFourDuel([]turnbased.PlayerID{"player1", "player2"})
+	// fill columns in pairs with pattern that never makes 4 in a line:
+	// columns 0,1 then 2,3 then 4,5 alternately, shifted so colors swap every 2 rows
+	order := []int{
+		0, 1, 0, 1, 1, 0, 1, 0, 0, 1, 0, 1,
+		2, 3, 2, 3, 3, 2, 3, 2, 2, 3, 2, 3,
+		4, 5, 4, 5, 5, 4, 5, 4, 4, 5, 4, 5,
+		6, 6, 6, 6, 6, 6,
+	}
+	dropAll(t, duel, order...)
+	if duel.Duel.State != turnbased.DuelStateEnd {
+		t.Fatalf("Duel should have ended")
+	}
+	if duel.Duel.Winner != "DRAW" {
+		t.Errorf("Expected DRAW, got %s (line %v)", duel.Duel.Winner, duel.WinningLine)
+	}
+}
+
+func TestGetState(t *testing.T) {
+	duel, _ := NewConnectFourDuel([]turnbased.PlayerID{"player1", "player2"})
+	dropAll(t, duel, 2)
+	state, ok := duel.GetState().(model.ConnectFourGameState)
+	if !ok {
+		t.Fatalf("GetState should return model.ConnectFourGameState, got %T", duel.GetState())
+	}
+	if len(state.Board) != Rows || len(state.Board[0]) != Columns {
+		t.Errorf("Expected %dx%d board, got %dx%d", Rows, Columns, len(state.Board), len(state.Board[0]))
+	}
+	if state.Board[0][2] != "player1" {
+		t.Errorf("Expected player1 disc at bottom of column 2, got %q", state.Board[0][2])
+	}
+}
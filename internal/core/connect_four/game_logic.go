@@ -0,0 +1,49 @@
+package connect_four
+
+import (
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/core/board"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// Ensure ConnectFourDuel implements GameLogic interface
+var _ turnbased.GameLogic = (*ConnectFourDuel)(nil)
+
+// GetState returns the game-specific state as JSON-serializable data
+// Returns model.ConnectFourGameState for type safety
+func (c4 *ConnectFourDuel) GetState() any {
+	return c4.ToModelConnectFourGameState()
+}
+
+// HandleAction processes a game action, the turn player is assumed to be the actor
+func (c4 *ConnectFourDuel) HandleAction(action any) error {
+	return c4.HandleActionWithPlayer(action, c4.Duel.TurnPlayer)
+}
+
+// HandleActionWithPlayer processes a game action with player context
+func (c4 *ConnectFourDuel) HandleActionWithPlayer(action any, playerID turnbased.PlayerID) error {
+	switch a := action.(type) {
+	case ActionDropDisc:
+		return c4.DropDisc(playerID, a.Column)
+	default:
+		return fmt.Errorf("unknown action type: %T", action)
+	}
+}
+
+// ToModelConnectFourGameState converts a ConnectFourDuel to model.ConnectFourGameState
+func (c4 *ConnectFourDuel) ToModelConnectFourGameState() model.ConnectFourGameState {
+	winningLine := make([][2]int, len(c4.WinningLine))
+	for i, cell := range c4.WinningLine {
+		winningLine[i] = [2]int{cell.Row, cell.Column}
+	}
+	return model.ConnectFourGameState{
+		Columns: Columns,
+		Rows:    Rows,
+		Board: board.Cells(c4.Board, func(owner turnbased.PlayerID) string {
+			return string(owner)
+		}),
+		WinningLine: winningLine,
+	}
+}
@@ -0,0 +1,25 @@
+package rock_paper_scissors
+
+import (
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// ActionThrow represents an action to commit a throw for the current round
+type ActionThrow struct {
+	Throw Throw
+}
+
+// Implement turnbased.Action interface for ActionThrow
+func (a ActionThrow) GameName() string {
+	return GameName
+}
+
+func (a ActionThrow) DuelID() turnbased.DuelID {
+	// DuelID will be set by the handler from the message context
+	return ""
+}
+
+func (a ActionThrow) PlayerID() turnbased.PlayerID {
+	// PlayerID will be set by the handler from the message context
+	return ""
+}
@@ -0,0 +1,74 @@
+package rock_paper_scissors
+
+import (
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// Ensure RockPaperScissorsDuel implements GameLogic, PlayerStateViewer and PlayerWaiter interfaces
+var (
+	_ turnbased.GameLogic         = (*RockPaperScissorsDuel)(nil)
+	_ turnbased.PlayerStateViewer = (*RockPaperScissorsDuel)(nil)
+	_ turnbased.PlayerWaiter      = (*RockPaperScissorsDuel)(nil)
+)
+
+// GetState returns the public state, without any throw of the round in progress
+func (rd *RockPaperScissorsDuel) GetState() any {
+	return rd.GetStateForPlayer("")
+}
+
+// GetStateForPlayer returns the state as seen by the viewer: everyone sees who has thrown
+// in the round in progress, but only the viewer sees their own throw
+func (rd *RockPaperScissorsDuel) GetStateForPlayer(viewer turnbased.PlayerID) any {
+	return rd.ToModelRockPaperScissorsGameState(viewer)
+}
+
+// HandleAction processes a game action, the actor must be specified
+// because there is no turn player in a simultaneous game
+func (rd *RockPaperScissorsDuel) HandleAction(action any) error {
+	return fmt.Errorf("rock paper scissors actions need a player, use HandleActionWithPlayer")
+}
+
+// HandleActionWithPlayer processes a game action with player context
+func (rd *RockPaperScissorsDuel) HandleActionWithPlayer(action any, playerID turnbased.PlayerID) error {
+	switch a := action.(type) {
+	case ActionThrow:
+		return rd.Throw(playerID, a.Throw)
+	default:
+		return fmt.Errorf("unknown action type: %T", action)
+	}
+}
+
+// ToModelRockPaperScissorsGameState converts a RockPaperScissorsDuel to
+// model.RockPaperScissorsGameState as seen by the viewer
+func (rd *RockPaperScissorsDuel) ToModelRockPaperScissorsGameState(viewer turnbased.PlayerID) model.RockPaperScissorsGameState {
+	rounds := make([]model.RockPaperScissorsRound, len(rd.Rounds))
+	for i, r := range rd.Rounds {
+		throws := make(map[string]string)
+		for pid, throw := range r.Throws {
+			throws[string(pid)] = string(throw)
+		}
+		rounds[i] = model.RockPaperScissorsRound{Throws: throws, Winner: string(r.Winner)}
+	}
+	wins := make(map[string]int)
+	for pid, n := range rd.Wins {
+		wins[string(pid)] = n
+	}
+	thrown := make(map[string]bool)
+	for _, pid := range rd.Duel.Players {
+		thrown[string(pid)] = rd.current.HasCommitted(pid)
+	}
+	state := model.RockPaperScissorsGameState{
+		BestOf:     rd.BestOf,
+		WinsNeeded: rd.WinsNeeded(),
+		Rounds:     rounds,
+		Wins:       wins,
+		Thrown:     thrown,
+	}
+	if throw, ok := rd.current.Move(viewer); ok {
+		state.MyThrow = string(throw)
+	}
+	return state
+}
@@ -0,0 +1,156 @@
+// Package rock_paper_scissors implements rock-paper-scissors best-of-N,
+// a simultaneous-move game: both players commit a hidden throw each round,
+// the throws are revealed together when both have committed
+package rock_paper_scissors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+const GameName = "ROCK_PAPER_SCISSORS"
+
+// DefaultBestOf is the number of rounds in a duel if not specified,
+// the first player to win more than half of them wins the duel
+const DefaultBestOf = 3
+
+// Throw is a player's choice in a round
+type Throw string
+
+// Throw enum
+const (
+	Rock     Throw = "ROCK"
+	Paper    Throw = "PAPER"
+	Scissors Throw = "SCISSORS"
+)
+
+// beats maps each throw to the throw it beats
+var beats = map[Throw]Throw{
+	Rock:     Scissors,
+	Paper:    Rock,
+	Scissors: Paper,
+}
+
+// ParseThrow parses a throw, case-insensitive
+func ParseThrow(s string) (Throw, error) {
+	throw := Throw(strings.ToUpper(strings.TrimSpace(s)))
+	if _, ok := beats[throw]; !ok {
+		return "", fmt.Errorf("invalid throw %q, must be ROCK, PAPER or SCISSORS", s)
+	}
+	return throw, nil
+}
+
+// Round is the revealed result of a round
+type Round struct {
+	Throws map[turnbased.PlayerID]Throw
+	Winner turnbased.PlayerID // empty if both players threw the same
+}
+
+type RockPaperScissorsDuel struct {
+	Duel   *turnbased.Duel
+	BestOf int
+	// Rounds is the revealed rounds, tied rounds included
+	Rounds []Round
+	Wins   map[turnbased.PlayerID]int
+	// current is the hidden throws of the round in progress
+	current *turnbased.SimultaneousMoves[Throw]
+}
+
+// NewRockPaperScissorsDuel creates a duel for exactly 2 players, bestOf must be odd,
+// 0 means DefaultBestOf. Both players can throw right away, there is no turn player.
+func NewRockPaperScissorsDuel(players []turnbased.PlayerID, bestOf int) (*RockPaperScissorsDuel, error) {
+	if len(players) != 2 {
+		return nil, fmt.Errorf("rock paper scissors needs exactly 2 players, got %d", len(players))
+	}
+	if players[0] == players[1] {
+		return nil, fmt.Errorf("players must be different")
+	}
+	if bestOf == 0 {
+		bestOf = DefaultBestOf
+	}
+	if bestOf < 0 || bestOf%2 == 0 {
+		return nil, fmt.Errorf("best of must be a positive odd number, got %d", bestOf)
+	}
+	duel := &RockPaperScissorsDuel{
+		Duel:    turnbased.NewDuel("", players),
+		BestOf:  bestOf,
+		Wins:    map[turnbased.PlayerID]int{players[0]: 0, players[1]: 0},
+		current: turnbased.NewSimultaneousMoves[Throw](players),
+	}
+	duel.Duel.Turn = 1
+	duel.Duel.State = turnbased.DuelStateRunning
+	return duel, nil
+}
+
+// WinsNeeded is the number of round wins to win the duel
+func (rd *RockPaperScissorsDuel) WinsNeeded() int {
+	return rd.BestOf/2 + 1
+}
+
+// Throw commits the player's hidden throw for the current round.
+// The log only records that the player has thrown, when both players have thrown,
+// the round is revealed and scored, a turn is a round.
+func (rd *RockPaperScissorsDuel) Throw(player turnbased.PlayerID, throw Throw) error {
+	if rd.Duel.State != turnbased.DuelStateRunning {
+		return turnbased.ErrDuelNotRunning
+	}
+	if _, ok := beats[throw]; !ok {
+		return fmt.Errorf("invalid throw %q", throw)
+	}
+	if err := rd.current.Commit(player, throw); err != nil {
+		return err
+	}
+	rd.Duel.LogAction(player, "THROW", map[string]interface{}{})
+
+	if !rd.current.Complete() {
+		return nil
+	}
+	throws, err := rd.current.Reveal()
+	if err != nil {
+		return err
+	}
+	rd.scoreRound(throws)
+	return nil
+}
+
+// scoreRound records the revealed round, then ends the duel or starts the next round
+func (rd *RockPaperScissorsDuel) scoreRound(throws map[turnbased.PlayerID]Throw) {
+	p0, p1 := rd.Duel.Players[0], rd.Duel.Players[1]
+	round := Round{Throws: throws}
+	switch {
+	case beats[throws[p0]] == throws[p1]:
+		round.Winner = p0
+	case beats[throws[p1]] == throws[p0]:
+		round.Winner = p1
+	}
+	rd.Rounds = append(rd.Rounds, round)
+	logData := map[string]interface{}{
+		"round":  len(rd.Rounds),
+		"winner": string(round.Winner),
+	}
+	for pid, throw := range throws {
+		logData[string(pid)] = string(throw)
+	}
+	rd.Duel.LogAction("", "REVEAL", logData)
+
+	if round.Winner != "" {
+		rd.Wins[round.Winner]++
+		if rd.Wins[round.Winner] >= rd.WinsNeeded() {
+			rd.Duel.SetWinner(round.Winner)
+			return
+		}
+	}
+	rd.Duel.NextTurnTo("")
+}
+
+// HasThrown returns true if the player has committed a throw in the current round
+func (rd *RockPaperScissorsDuel) HasThrown(player turnbased.PlayerID) bool {
+	return rd.current.HasCommitted(player)
+}
+
+// WaitingFor returns the players who have not thrown in the current round, see turnbased.PlayerWaiter
+func (rd *RockPaperScissorsDuel) WaitingFor() []turnbased.PlayerID {
+	return rd.current.Waiting()
+}
@@ -0,0 +1,106 @@
+package rock_paper_scissors
+
+import (
+	"testing"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+func newTestDuel(t *testing.T, bestOf int) *RockPaperScissorsDuel {
+	t.Helper()
+	duel, err := NewRockPaperScissorsDuel([]turnbased.PlayerID{"player1", "player2"}, bestOf)
+	if err != nil {
+		t.Fatalf("NewRockPaperScissorsDuel failed: %v", err)
+	}
+	return duel
+}
+
+func playRound(t *testing.T, duel *RockPaperScissorsDuel, throw1 Throw, throw2 Throw) {
+	t.Helper()
+	if err := duel.Throw("player1", throw1); err != nil {
+		t.Fatalf("Throw player1 failed: %v", err)
+	}
+	if err := duel.Throw("player2", throw2); err != nil {
+		t.Fatalf("Throw player2 failed: %v", err)
+	}
+}
+
+func TestNewRockPaperScissorsDuel(t *testing.T) {
+	if _, err := NewRockPaperScissorsDuel([]turnbased.PlayerID{"player1"}, 3); err == nil {
+		t.Error("Should fail with 1 player")
+	}
+	if _, err := NewRockPaperScissorsDuel([]turnbased.PlayerID{"player1", "player2"}, 4); err == nil {
+		t.Error("Should fail with an even best of")
+	}
+	duel := newTestDuel(t, 0)
+	if duel.BestOf != DefaultBestOf || duel.WinsNeeded() != 2 {
+		t.Errorf("Expected best of %d needing 2 wins, got %d %d", DefaultBestOf, duel.BestOf, duel.WinsNeeded())
+	}
+	if duel.Duel.State != turnbased.DuelStateRunning || duel.Duel.TurnPlayer != "" {
+		t.Errorf("Expected RUNNING without turn player, got %s %q", duel.Duel.State, duel.Duel.TurnPlayer)
+	}
+}
+
+func TestThrow_HiddenUntilBothCommitted(t *testing.T) {
+	duel := newTestDuel(t, 3)
+	if err := duel.Throw("player2", Paper); err != nil {
+		t.Fatalf("Second player should be able to throw first: %v", err)
+	}
+	if err := duel.Throw("player2", Rock); err == nil {
+		t.Error("Should not change a committed throw")
+	}
+	if err := duel.Throw("player3", Rock); err == nil {
+		t.Error("Should not accept a throw from outside the duel")
+	}
+	if err := duel.Throw("player1", "LIZARD"); err == nil {
+		t.Error("Should not accept an invalid throw")
+	}
+
+	opponentView := duel.GetStateForPlayer("player1").(model.RockPaperScissorsGameState)
+	if opponentView.MyThrow != "" || !opponentView.Thrown["player2"] || opponentView.Thrown["player1"] {
+		t.Errorf("player1 should only know that player2 has thrown, got %+v", opponentView)
+	}
+	ownView := duel.GetStateForPlayer("player2").(model.RockPaperScissorsGameState)
+	if ownView.MyThrow != string(Paper) {
+		t.Errorf("player2 should see their own throw, got %q", ownView.MyThrow)
+	}
+	last := duel.Duel.ActionLog[len(duel.Duel.ActionLog)-1]
+	if last.Action != "THROW" || len(last.Data) != 0 {
+		t.Errorf("THROW log entry should not reveal the throw, got %+v", last)
+	}
+
+	if err := duel.Throw("player1", Scissors); err != nil {
+		t.Fatalf("Throw failed: %v", err)
+	}
+	if len(duel.Rounds) != 1 || duel.Rounds[0].Winner != "player1" || duel.Wins["player1"] != 1 {
+		t.Errorf("Expected scissors to beat paper, got %+v", duel.Rounds)
+	}
+	if duel.Duel.Turn != 2 || duel.HasThrown("player1") || duel.HasThrown("player2") {
+		t.Errorf("Expected a new round at turn 2, got turn %d", duel.Duel.Turn)
+	}
+	reveal := duel.Duel.ActionLog[len(duel.Duel.ActionLog)-1]
+	if reveal.Action != "REVEAL" || reveal.Data["player2"] != string(Paper) {
+		t.Errorf("Expected REVEAL with both throws, got %+v", reveal)
+	}
+}
+
+func TestBestOf_TiesDoNotCount(t *testing.T) {
+	duel := newTestDuel(t, 3)
+	playRound(t, duel, Rock, Rock)
+	playRound(t, duel, Rock, Paper)
+	playRound(t, duel, Scissors, Scissors)
+	if duel.Duel.IsOver() {
+		t.Fatal("Duel should not be over after 1 win")
+	}
+	playRound(t, duel, Scissors, Rock)
+	if duel.Duel.State != turnbased.DuelStateEnd || duel.Duel.Winner != "player2" {
+		t.Errorf("Expected player2 to win, got %s %s", duel.Duel.State, duel.Duel.Winner)
+	}
+	if len(duel.Rounds) != 4 || duel.Wins["player1"] != 0 {
+		t.Errorf("Unexpected rounds %+v wins %v", duel.Rounds, duel.Wins)
+	}
+	if err := duel.Throw("player1", Rock); err == nil {
+		t.Error("Should not throw after the duel ended")
+	}
+}
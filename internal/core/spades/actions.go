@@ -0,0 +1,25 @@
+package spades
+
+import (
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// ActionPlayCard represents an action to play a card to the current trick
+type ActionPlayCard struct {
+	CardID string // e.g. "QS", "TH"
+}
+
+// Implement turnbased.Action interface for ActionPlayCard
+func (a ActionPlayCard) GameName() string {
+	return GameName
+}
+
+func (a ActionPlayCard) DuelID() turnbased.DuelID {
+	// DuelID will be set by the handler from the message context
+	return ""
+}
+
+func (a ActionPlayCard) PlayerID() turnbased.PlayerID {
+	// PlayerID will be set by the handler from the message context
+	return ""
+}
@@ -0,0 +1,75 @@
+package spades
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// Suit of a playing card
+type Suit string
+
+// Suit enum, Spades is always trump
+const (
+	Clubs    Suit = "C"
+	Diamonds Suit = "D"
+	Hearts   Suit = "H"
+	Spades   Suit = "S"
+)
+
+var suits = []Suit{Clubs, Diamonds, Hearts, Spades}
+
+// rankLetters maps ranks 2..14 to their letter, T is 10 and A (14) is the highest
+const rankLetters = "23456789TJQKA"
+
+// Card is a playing card of the standard 52-card deck
+type Card struct {
+	Suit Suit
+	Rank int // 2..14, Ace is 14
+}
+
+// String returns the card ID, rank letter then suit, e.g. "AS", "TH", "2C"
+func (c Card) String() string {
+	return string(rankLetters[c.Rank-2]) + string(c.Suit)
+}
+
+// ParseCard parses a card ID, e.g. "AS", "TH" (or "10H"), "2C"
+func ParseCard(id string) (Card, error) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	id = strings.Replace(id, "10", "T", 1)
+	if len(id) != 2 {
+		return Card{}, fmt.Errorf("invalid card %q", id)
+	}
+	rank := strings.IndexByte(rankLetters, id[0])
+	suit := Suit(id[1:])
+	if rank == -1 || !strings.Contains("CDHS", string(suit)) {
+		return Card{}, fmt.Errorf("invalid card %q", id)
+	}
+	return Card{Suit: suit, Rank: rank + 2}, nil
+}
+
+// newShuffledDeck returns the 52 cards in random order
+func newShuffledDeck(random *rand.Rand) []Card {
+	deck := make([]Card, 0, 52)
+	for _, suit := range suits {
+		for rank := 2; rank <= 14; rank++ {
+			deck = append(deck, Card{Suit: suit, Rank: rank})
+		}
+	}
+	random.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+	return deck
+}
+
+// beats returns true if card a beats card b in a trick led with the suit led
+func beats(a Card, b Card, led Suit) bool {
+	if a.Suit == b.Suit {
+		return a.Rank > b.Rank
+	}
+	if a.Suit == Spades {
+		return true
+	}
+	if b.Suit == Spades {
+		return false
+	}
+	return a.Suit == led
+}
@@ -0,0 +1,97 @@
+package spades
+
+import (
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// Ensure SpadesDuel implements GameLogic and PlayerStateViewer interfaces
+var (
+	_ turnbased.GameLogic         = (*SpadesDuel)(nil)
+	_ turnbased.PlayerStateViewer = (*SpadesDuel)(nil)
+)
+
+// GetState returns the public state, without any player's hand
+func (sd *SpadesDuel) GetState() any {
+	return sd.GetStateForPlayer("")
+}
+
+// GetStateForPlayer returns the state as seen by the viewer, who only sees their own hand
+func (sd *SpadesDuel) GetStateForPlayer(viewer turnbased.PlayerID) any {
+	return sd.ToModelSpadesGameState(viewer)
+}
+
+// HandleAction processes a game action, the turn player is assumed to be the actor
+func (sd *SpadesDuel) HandleAction(action any) error {
+	return sd.HandleActionWithPlayer(action, sd.Duel.TurnPlayer)
+}
+
+// HandleActionWithPlayer processes a game action with player context
+func (sd *SpadesDuel) HandleActionWithPlayer(action any, playerID turnbased.PlayerID) error {
+	switch a := action.(type) {
+	case ActionPlayCard:
+		card, err := ParseCard(a.CardID)
+		if err != nil {
+			return err
+		}
+		return sd.PlayCard(playerID, card)
+	default:
+		return fmt.Errorf("unknown action type: %T", action)
+	}
+}
+
+// ToModelSpadesGameState converts a SpadesDuel to model.SpadesGameState as seen by the viewer
+func (sd *SpadesDuel) ToModelSpadesGameState(viewer turnbased.PlayerID) model.SpadesGameState {
+	players := make([]model.SpadesPlayer, len(sd.Duel.Players))
+	for i, pid := range sd.Duel.Players {
+		player := model.SpadesPlayer{
+			ID:         string(pid),
+			Team:       string(sd.teamOf(pid)),
+			Hand:       []string{},
+			HandSize:   len(sd.Hands[pid]),
+			LegalCards: []string{},
+		}
+		if pid == viewer {
+			player.Hand = cardIDs(sd.Hands[pid])
+			player.LegalCards = cardIDs(sd.LegalCards(pid))
+		}
+		players[i] = player
+	}
+	tricksWon := make(map[string]int)
+	for team, n := range sd.TricksWon {
+		tricksWon[string(team)] = n
+	}
+	scores := make(map[string]int)
+	for team, n := range sd.Scores {
+		scores[string(team)] = n
+	}
+	return model.SpadesGameState{
+		HandNumber:   sd.HandNumber,
+		HandsToPlay:  sd.HandsToPlay,
+		Dealer:       string(sd.Dealer),
+		Players:      players,
+		Trick:        toModelTrick(sd.Trick),
+		LastTrick:    toModelTrick(sd.LastTrick),
+		SpadesBroken: sd.SpadesBroken,
+		TricksWon:    tricksWon,
+		Scores:       scores,
+	}
+}
+
+func cardIDs(cards []Card) []string {
+	ids := make([]string, len(cards))
+	for i, c := range cards {
+		ids[i] = c.String()
+	}
+	return ids
+}
+
+func toModelTrick(trick []TrickCard) []model.SpadesTrickCard {
+	ret := make([]model.SpadesTrickCard, len(trick))
+	for i, tc := range trick {
+		ret[i] = model.SpadesTrickCard{PlayerID: string(tc.PlayerID), Card: tc.Card.String()}
+	}
+	return ret
+}
@@ -0,0 +1,264 @@
+// Package spades implements a simplified Spades on the generic turn-based engine:
+// 4 players in 2 partnerships, trick-taking with spades as trump, hidden hands,
+// points scored per hand across several hands
+package spades
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+const GameName = "SPADES"
+
+// NumPlayers is the number of players, partners sit opposite each other
+const NumPlayers = 4
+
+// DefaultHandsToPlay is the number of hands in a duel, so that every player deals once
+const DefaultHandsToPlay = 4
+
+// Team IDs: the 1st and 3rd players are partners against the 2nd and 4th
+const (
+	TeamNorthSouth turnbased.TeamID = "NORTH_SOUTH"
+	TeamEastWest   turnbased.TeamID = "EAST_WEST"
+)
+
+// TrickCard is a card played to a trick
+type TrickCard struct {
+	PlayerID turnbased.PlayerID
+	Card     Card
+}
+
+type SpadesDuel struct {
+	Duel        *turnbased.Duel
+	Hands       map[turnbased.PlayerID][]Card
+	HandsToPlay int
+	HandNumber  int // current hand, starts from 1
+	Dealer      turnbased.PlayerID
+	// Trick is the cards played to the current trick in play order, the first card leads
+	Trick     []TrickCard
+	LastTrick []TrickCard
+	// SpadesBroken is true once a spade was played in the current hand,
+	// spades cannot be led before that unless the leader only has spades
+	SpadesBroken bool
+	// TricksWon counts tricks won by each team in the current hand
+	TricksWon map[turnbased.TeamID]int
+	// Scores is the total points of each team, 1 point per trick
+	Scores map[turnbased.TeamID]int
+	random *rand.Rand
+}
+
+// NewSpadesDuel creates a duel for exactly 4 players and deals the first hand,
+// the first player deals and the player after the dealer leads the first trick
+func NewSpadesDuel(players []turnbased.PlayerID, handsToPlay int) (*SpadesDuel, error) {
+	if len(players) != NumPlayers {
+		return nil, fmt.Errorf("spades needs exactly %d players, got %d", NumPlayers, len(players))
+	}
+	seen := make(map[turnbased.PlayerID]bool)
+	for _, pid := range players {
+		if seen[pid] {
+			return nil, fmt.Errorf("players must be different")
+		}
+		seen[pid] = true
+	}
+	if handsToPlay <= 0 {
+		handsToPlay = DefaultHandsToPlay
+	}
+	seed := uint64(time.Now().UnixNano())
+	duel := &SpadesDuel{
+		Duel:        turnbased.NewDuel("", players),
+		HandsToPlay: handsToPlay,
+		Dealer:      players[0],
+		Scores:      map[turnbased.TeamID]int{TeamNorthSouth: 0, TeamEastWest: 0},
+		random:      rand.New(rand.NewPCG(seed, seed>>1)),
+	}
+	duel.Duel.Teams = []turnbased.Team{
+		{ID: TeamNorthSouth, Players: []turnbased.PlayerID{players[0], players[2]}},
+		{ID: TeamEastWest, Players: []turnbased.PlayerID{players[1], players[3]}},
+	}
+	duel.dealHand()
+	duel.Duel.Turn = 1
+	duel.Duel.TurnPlayer = duel.nextPlayer(duel.Dealer)
+	duel.Duel.State = turnbased.DuelStateRunning
+	return duel, nil
+}
+
+// dealHand shuffles and deals 13 cards to each player, starting a new hand
+func (sd *SpadesDuel) dealHand() {
+	sd.HandNumber++
+	sd.Hands = make(map[turnbased.PlayerID][]Card)
+	deck := newShuffledDeck(sd.random)
+	for i, card := range deck {
+		pid := sd.Duel.Players[i%NumPlayers]
+		sd.Hands[pid] = append(sd.Hands[pid], card)
+	}
+	for _, hand := range sd.Hands {
+		sortHand(hand)
+	}
+	sd.Trick = nil
+	sd.SpadesBroken = false
+	sd.TricksWon = map[turnbased.TeamID]int{TeamNorthSouth: 0, TeamEastWest: 0}
+}
+
+// sortHand orders cards by suit then rank, for display
+func sortHand(hand []Card) {
+	sort.Slice(hand, func(i, j int) bool {
+		if hand[i].Suit != hand[j].Suit {
+			return hand[i].Suit < hand[j].Suit
+		}
+		return hand[i].Rank < hand[j].Rank
+	})
+}
+
+// nextPlayer returns the player sitting after the given one
+func (sd *SpadesDuel) nextPlayer(player turnbased.PlayerID) turnbased.PlayerID {
+	for i, pid := range sd.Duel.Players {
+		if pid == player {
+			return sd.Duel.Players[(i+1)%NumPlayers]
+		}
+	}
+	return ""
+}
+
+// teamOf returns the team ID of the player
+func (sd *SpadesDuel) teamOf(player turnbased.PlayerID) turnbased.TeamID {
+	team, _ := sd.Duel.TeamOf(player)
+	return team.ID
+}
+
+// LegalCards returns the cards the player can play now, empty if it is not their turn
+func (sd *SpadesDuel) LegalCards(player turnbased.PlayerID) []Card {
+	if sd.Duel.State != turnbased.DuelStateRunning || sd.Duel.TurnPlayer != player {
+		return nil
+	}
+	hand := sd.Hands[player]
+	var legal []Card
+	if len(sd.Trick) > 0 {
+		// must follow the suit led if able
+		led := sd.Trick[0].Card.Suit
+		for _, c := range hand {
+			if c.Suit == led {
+				legal = append(legal, c)
+			}
+		}
+	} else if !sd.SpadesBroken {
+		// cannot lead spades before they are broken
+		for _, c := range hand {
+			if c.Suit != Spades {
+				legal = append(legal, c)
+			}
+		}
+	}
+	if len(legal) == 0 {
+		legal = append(legal, hand...)
+	}
+	return legal
+}
+
+// PlayCard plays a card from the player's hand to the current trick.
+// When the trick is complete, its winner's team scores it and the winner leads the next trick,
+// when the hand is over, a new hand is dealt or the duel ends after HandsToPlay hands.
+func (sd *SpadesDuel) PlayCard(player turnbased.PlayerID, card Card) error {
+	if sd.Duel.State != turnbased.DuelStateRunning {
+		return turnbased.ErrDuelNotRunning
+	}
+	if sd.Duel.TurnPlayer != player {
+		return turnbased.ErrNotPlayerTurn
+	}
+	legal := false
+	for _, c := range sd.LegalCards(player) {
+		if c == card {
+			legal = true
+			break
+		}
+	}
+	if !legal {
+		for _, c := range sd.Hands[player] {
+			if c == card {
+				return fmt.Errorf("card %s cannot be played: must follow suit, or spades not broken", card)
+			}
+		}
+		return fmt.Errorf("card %s is not in hand", card)
+	}
+
+	hand := sd.Hands[player]
+	for i, c := range hand {
+		if c == card {
+			sd.Hands[player] = append(hand[:i], hand[i+1:]...)
+			break
+		}
+	}
+	sd.Trick = append(sd.Trick, TrickCard{PlayerID: player, Card: card})
+	if card.Suit == Spades {
+		sd.SpadesBroken = true
+	}
+	sd.Duel.LogAction(player, "PLAY_CARD", map[string]interface{}{
+		"card": card.String(),
+	})
+
+	if len(sd.Trick) < NumPlayers {
+		sd.Duel.NextTurnTo(sd.nextPlayer(player))
+		return nil
+	}
+	winner := sd.completeTrick()
+	if len(sd.Hands[winner]) > 0 {
+		sd.Duel.NextTurnTo(winner)
+		return nil
+	}
+	sd.completeHand()
+	return nil
+}
+
+// completeTrick scores the complete trick for the winner's team and returns the winner
+func (sd *SpadesDuel) completeTrick() turnbased.PlayerID {
+	led := sd.Trick[0].Card.Suit
+	best := sd.Trick[0]
+	cards := make([]string, len(sd.Trick))
+	for i, tc := range sd.Trick {
+		cards[i] = tc.Card.String()
+		if beats(tc.Card, best.Card, led) {
+			best = tc
+		}
+	}
+	team := sd.teamOf(best.PlayerID)
+	sd.TricksWon[team]++
+	sd.Duel.LogAction(best.PlayerID, "TRICK_WON", map[string]interface{}{
+		"cards": cards,
+		"team":  string(team),
+	})
+	sd.LastTrick = sd.Trick
+	sd.Trick = nil
+	return best.PlayerID
+}
+
+// completeHand adds the hand's tricks to the scores, then deals the next hand
+// or ends the duel with the team with the most points as winner
+func (sd *SpadesDuel) completeHand() {
+	for team, tricks := range sd.TricksWon {
+		sd.Scores[team] += tricks
+	}
+	sd.Duel.LogAction(sd.Dealer, "HAND_END", map[string]interface{}{
+		"hand":                            sd.HandNumber,
+		string(TeamNorthSouth):            sd.TricksWon[TeamNorthSouth],
+		string(TeamEastWest):              sd.TricksWon[TeamEastWest],
+		"score_" + string(TeamNorthSouth): sd.Scores[TeamNorthSouth],
+		"score_" + string(TeamEastWest):   sd.Scores[TeamEastWest],
+	})
+	if sd.HandNumber >= sd.HandsToPlay {
+		switch ns, ew := sd.Scores[TeamNorthSouth], sd.Scores[TeamEastWest]; {
+		case ns > ew:
+			sd.Duel.SetWinnerTeam(TeamNorthSouth)
+		case ew > ns:
+			sd.Duel.SetWinnerTeam(TeamEastWest)
+		default:
+			sd.Duel.SetDraw()
+		}
+		return
+	}
+	sd.Dealer = sd.nextPlayer(sd.Dealer)
+	sd.dealHand()
+	sd.Duel.NextTurnTo(sd.nextPlayer(sd.Dealer))
+}
@@ -0,0 +1,200 @@
+package spades
+
+import (
+	"testing"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+var testPlayers = []turnbased.PlayerID{"north", "east", "south", "west"}
+
+func newTestDuel(t *testing.T, handsToPlay int) *SpadesDuel {
+	t.Helper()
+	duel, err := NewSpadesDuel(testPlayers, handsToPlay)
+	if err != nil {
+		t.Fatalf("NewSpadesDuel failed: %v", err)
+	}
+	return duel
+}
+
+func mustCards(t *testing.T, ids ...string) []Card {
+	t.Helper()
+	cards := make([]Card, len(ids))
+	for i, id := range ids {
+		c, err := ParseCard(id)
+		if err != nil {
+			t.Fatalf("ParseCard(%q) failed: %v", id, err)
+		}
+		cards[i] = c
+	}
+	return cards
+}
+
+func TestNewSpadesDuel(t *testing.T) {
+	if _, err := NewSpadesDuel(testPlayers[:3], 0); err == nil {
+		t.Error("NewSpadesDuel should fail with 3 players")
+	}
+	if _, err := NewSpadesDuel([]turnbased.PlayerID{"a", "b", "a", "c"}, 0); err == nil {
+		t.Error("NewSpadesDuel should fail with duplicated players")
+	}
+
+	duel := newTestDuel(t, 0)
+	if duel.HandsToPlay != DefaultHandsToPlay {
+		t.Errorf("Expected %d hands, got %d", DefaultHandsToPlay, duel.HandsToPlay)
+	}
+	seen := make(map[Card]bool)
+	for _, pid := range testPlayers {
+		if len(duel.Hands[pid]) != 13 {
+			t.Errorf("Expected 13 cards for %s, got %d", pid, len(duel.Hands[pid]))
+		}
+		for _, c := range duel.Hands[pid] {
+			seen[c] = true
+		}
+	}
+	if len(seen) != 52 {
+		t.Errorf("Expected 52 distinct cards dealt, got %d", len(seen))
+	}
+	if duel.Duel.TurnPlayer != "east" || duel.Duel.State != turnbased.DuelStateRunning {
+		t.Errorf("Expected east to lead a running duel, got %s %s", duel.Duel.TurnPlayer, duel.Duel.State)
+	}
+	team, ok := duel.Duel.TeamOf("south")
+	if !ok || team.ID != TeamNorthSouth {
+		t.Errorf("Expected south in %s, got %v", TeamNorthSouth, team)
+	}
+}
+
+func TestParseCard(t *testing.T) {
+	for id, want := range map[string]Card{
+		"AS":  {Suit: Spades, Rank: 14},
+		"th":  {Suit: Hearts, Rank: 10},
+		"10H": {Suit: Hearts, Rank: 10},
+		"2C":  {Suit: Clubs, Rank: 2},
+	} {
+		got, err := ParseCard(id)
+		if err != nil || got != want {
+			t.Errorf("ParseCard(%q) = %v, %v; want %v", id, got, err, want)
+		}
+	}
+	for _, id := range []string{"", "1S", "AX", "ASS"} {
+		if _, err := ParseCard(id); err == nil {
+			t.Errorf("ParseCard(%q) should fail", id)
+		}
+	}
+}
+
+func TestPlayCard_FollowSuitAndSpadesBroken(t *testing.T) {
+	duel := newTestDuel(t, 1)
+	duel.Hands["east"] = mustCards(t, "2S", "3H")
+	duel.Hands["south"] = mustCards(t, "4H", "5D")
+	duel.Hands["west"] = mustCards(t, "6C", "7D")
+	duel.Hands["north"] = mustCards(t, "8H", "9S")
+
+	if err := duel.PlayCard("east", mustCards(t, "2S")[0]); err == nil {
+		t.Error("Should not lead spades before they are broken")
+	}
+	if err := duel.PlayCard("south", mustCards(t, "4H")[0]); err == nil {
+		t.Error("Should not play out of turn")
+	}
+	if err := duel.PlayCard("east", mustCards(t, "AH")[0]); err == nil {
+		t.Error("Should not play a card not in hand")
+	}
+	if err := duel.PlayCard("east", mustCards(t, "3H")[0]); err != nil {
+		t.Fatalf("PlayCard failed: %v", err)
+	}
+	if err := duel.PlayCard("south", mustCards(t, "5D")[0]); err == nil {
+		t.Error("Should follow suit when able")
+	}
+	for _, play := range []struct {
+		player turnbased.PlayerID
+		card   string
+	}{{"south", "4H"}, {"west", "6C"}, {"north", "8H"}} {
+		if err := duel.PlayCard(play.player, mustCards(t, play.card)[0]); err != nil {
+			t.Fatalf("PlayCard %s %s failed: %v", play.player, play.card, err)
+		}
+	}
+
+	// north's 8H is the highest heart, west's club does not follow suit
+	if duel.Duel.TurnPlayer != "north" {
+		t.Errorf("Expected trick winner north to lead, got %s", duel.Duel.TurnPlayer)
+	}
+	if duel.TricksWon[TeamNorthSouth] != 1 || len(duel.Trick) != 0 || len(duel.LastTrick) != 4 {
+		t.Errorf("Unexpected trick result: %v trick %v last %v", duel.TricksWon, duel.Trick, duel.LastTrick)
+	}
+	// north only has spades left, so can lead a spade even if spades are not broken
+	if err := duel.PlayCard("north", mustCards(t, "9S")[0]); err != nil {
+		t.Errorf("Should lead spades when only spades are left: %v", err)
+	}
+}
+
+func TestPlayCard_TrumpWinsAndHandEnds(t *testing.T) {
+	duel := newTestDuel(t, 1)
+	duel.Hands["east"] = mustCards(t, "AH")
+	duel.Hands["south"] = mustCards(t, "KH")
+	duel.Hands["west"] = mustCards(t, "2S")
+	duel.Hands["north"] = mustCards(t, "QH")
+	for _, play := range []struct {
+		player turnbased.PlayerID
+		card   string
+	}{{"east", "AH"}, {"south", "KH"}, {"west", "2S"}, {"north", "QH"}} {
+		if err := duel.PlayCard(play.player, mustCards(t, play.card)[0]); err != nil {
+			t.Fatalf("PlayCard %s %s failed: %v", play.player, play.card, err)
+		}
+	}
+	if duel.Scores[TeamEastWest] != 1 || duel.Scores[TeamNorthSouth] != 0 {
+		t.Errorf("Expected the trumped trick to score for %s, got %v", TeamEastWest, duel.Scores)
+	}
+	if duel.Duel.State != turnbased.DuelStateEnd || duel.Duel.WinnerTeam != TeamEastWest {
+		t.Errorf("Expected %s to win, got %s %s", TeamEastWest, duel.Duel.State, duel.Duel.WinnerTeam)
+	}
+}
+
+func TestPlayFullDuel(t *testing.T) {
+	duel := newTestDuel(t, 2)
+	for duel.Duel.State == turnbased.DuelStateRunning {
+		player := duel.Duel.TurnPlayer
+		legal := duel.LegalCards(player)
+		if len(legal) == 0 {
+			t.Fatalf("No legal card for turn player %s", player)
+		}
+		if err := duel.HandleActionWithPlayer(ActionPlayCard{CardID: legal[0].String()}, player); err != nil {
+			t.Fatalf("PlayCard failed: %v", err)
+		}
+	}
+	if got := duel.Scores[TeamNorthSouth] + duel.Scores[TeamEastWest]; got != 26 {
+		t.Errorf("Expected 26 points in 2 hands, got %d", got)
+	}
+	if duel.HandNumber != 2 || duel.Dealer != "east" {
+		t.Errorf("Expected east to deal the 2nd hand, got hand %d dealer %s", duel.HandNumber, duel.Dealer)
+	}
+	if duel.Duel.Winner == "" {
+		t.Error("Expected a winner or a draw")
+	}
+	// every card play is a turn, the last one ends the duel without advancing
+	if duel.Duel.Turn != 104 {
+		t.Errorf("Expected 104 turns, got %d", duel.Duel.Turn)
+	}
+}
+
+func TestGetStateForPlayer_HidesOtherHands(t *testing.T) {
+	duel := newTestDuel(t, 1)
+	state := duel.GetStateForPlayer("east").(model.SpadesGameState)
+	for _, p := range state.Players {
+		if p.HandSize != 13 {
+			t.Errorf("Expected hand size 13 for %s, got %d", p.ID, p.HandSize)
+		}
+		if p.ID == "east" {
+			if len(p.Hand) != 13 || len(p.LegalCards) == 0 {
+				t.Errorf("Expected east to see their hand and legal cards, got %v %v", p.Hand, p.LegalCards)
+			}
+		} else if len(p.Hand) != 0 || len(p.LegalCards) != 0 {
+			t.Errorf("Hand of %s should be hidden from east, got %v", p.ID, p.Hand)
+		}
+	}
+	public := duel.GetState().(model.SpadesGameState)
+	for _, p := range public.Players {
+		if len(p.Hand) != 0 {
+			t.Errorf("Public state should not show the hand of %s", p.ID)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package turnbased
+
+import (
+	"fmt"
+)
+
+// GameCloner is implemented by games that can be copied, to simulate, preview or roll back a duel.
+// CloneGame returns an independent copy of the game state (including any RNG state)
+// bound to duel, the copy of the generic Duel already made by Duel.Clone.
+type GameCloner interface {
+	CloneGame(duel *Duel) GameLogic
+}
+
+// Clone returns a fully independent copy of the duel: players, teams, action log,
+// and the game state if the game implements GameCloner. The copy's Game points to the copied state,
+// whose generic duel is the copy. It fails if the duel has a game that cannot be cloned.
+func (d *Duel) Clone() (*Duel, error) {
+	clone := *d
+	clone.Players = append([]PlayerID(nil), d.Players...)
+	clone.Teams = nil
+	for _, team := range d.Teams {
+		clone.Teams = append(clone.Teams, Team{ID: team.ID, Players: append([]PlayerID(nil), team.Players...)})
+	}
+	clone.ActionLog = make([]ActionLogEntry, len(d.ActionLog))
+	for i, entry := range d.ActionLog {
+		entry.Data = cloneLogData(entry.Data)
+		clone.ActionLog[i] = entry
+	}
+	if d.Game != nil {
+		cloner, ok := d.Game.(GameCloner)
+		if !ok {
+			return nil, fmt.Errorf("game %T does not support cloning", d.Game)
+		}
+		clone.Game = cloner.CloneGame(&clone)
+	}
+	return &clone, nil
+}
+
+// cloneLogData deep copies action log data, made of JSON-like values
+func cloneLogData(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	clone := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		clone[k] = cloneLogValue(v)
+	}
+	return clone
+}
+
+func cloneLogValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return cloneLogData(value)
+	case []interface{}:
+		clone := make([]interface{}, len(value))
+		for i, item := range value {
+			clone[i] = cloneLogValue(item)
+		}
+		return clone
+	case []string:
+		return append([]string(nil), value...)
+	default:
+		// numbers, strings, bools and other values are immutable or not shared by games
+		return v
+	}
+}
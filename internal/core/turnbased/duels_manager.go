@@ -15,6 +15,9 @@ type DuelsManager interface {
 	GetDuel(id DuelID) *Duel
 	// UpdateDuel updates the duel state by ID.
 	UpdateDuel(duel *Duel) (*Duel, error)
+	// LockDuel serializes the changes of the duel (e.g. players acting at the same time),
+	// the returned function unlocks it. Locking an unknown duel does nothing.
+	LockDuel(id DuelID) (unlock func())
 }
 
 var _ DuelsManager = (*InMemoryDuelsManager)(nil) // ensure interface compliance
@@ -32,13 +35,15 @@ type Action interface {
 // InMemoryDuelsManager is an in-memory implementation of DuelsManager using a Go map.
 type InMemoryDuelsManager struct {
 	duels map[DuelID]*Duel
-	mu    sync.RWMutex // protects duels
+	locks map[DuelID]*sync.Mutex // one per duel, see LockDuel
+	mu    sync.RWMutex           // protects duels and locks
 }
 
 // NewInMemoryDuelsManager creates a new in-memory duels manager.
 func NewInMemoryDuelsManager() *InMemoryDuelsManager {
 	return &InMemoryDuelsManager{
 		duels: make(map[DuelID]*Duel),
+		locks: make(map[DuelID]*sync.Mutex),
 	}
 }
 
@@ -47,6 +52,7 @@ func (m *InMemoryDuelsManager) CreateDuel(duel *Duel) *Duel {
 	duel.ID = id
 	m.mu.Lock()
 	m.duels[id] = duel
+	m.locks[id] = &sync.Mutex{}
 	m.mu.Unlock()
 	return duel
 }
@@ -84,3 +90,14 @@ func (m *InMemoryDuelsManager) UpdateDuel(duel *Duel) (*Duel, error) {
 	m.duels[duel.ID] = duel
 	return duel, nil
 }
+
+func (m *InMemoryDuelsManager) LockDuel(id DuelID) func() {
+	m.mu.RLock()
+	lock, ok := m.locks[id]
+	m.mu.RUnlock()
+	if !ok {
+		return func() {}
+	}
+	lock.Lock()
+	return lock.Unlock
+}
@@ -0,0 +1,10 @@
+package turnbased
+
+import "errors"
+
+// Errors of actions that break a rule common to all games,
+// games return them (possibly wrapped) so that callers can test them with errors.Is
+var (
+	ErrNotPlayerTurn  = errors.New("not player's turn")
+	ErrDuelNotRunning = errors.New("duel is not running")
+)
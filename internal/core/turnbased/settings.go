@@ -0,0 +1,121 @@
+package turnbased
+
+import (
+	"fmt"
+	"time"
+)
+
+// Settings are the options of a duel chosen when it is created, common to all games
+type Settings struct {
+	// DisconnectGracePeriod is how long the players the duel waits for can stay disconnected
+	// before DisconnectRule applies
+	DisconnectGracePeriod time.Duration
+	DisconnectRule        DisconnectRule
+	// AllowSpectators is whether connections can watch the duel without playing
+	AllowSpectators bool
+}
+
+// DisconnectRule is what happens when a player the duel waits for stays disconnected
+// longer than the grace period.
+//
+// Possible values:
+//   - "NONE": nothing, the duel waits for the player to come back.
+//   - "PASS": the player's turns are passed (see TurnPasser) until they come back,
+//     in games that cannot pass a turn the player forfeits instead.
+//   - "FORFEIT": the player abandons the duel, see Duel.Abandon.
+type DisconnectRule string
+
+const (
+	DisconnectRuleNone    DisconnectRule = "NONE"
+	DisconnectRulePass    DisconnectRule = "PASS"
+	DisconnectRuleForfeit DisconnectRule = "FORFEIT"
+)
+
+// Default settings of a new duel
+const (
+	DefaultDisconnectGracePeriod = time.Minute
+	DefaultDisconnectRule        = DisconnectRuleNone // duels opt in to PASS or FORFEIT
+)
+
+// EndReasonAbandoned is the EndReason of a duel ended by Duel.Abandon
+const EndReasonAbandoned = "abandoned"
+
+// DefaultSettings returns the settings of a duel created without options
+func DefaultSettings() Settings {
+	return Settings{
+		DisconnectGracePeriod: DefaultDisconnectGracePeriod,
+		DisconnectRule:        DefaultDisconnectRule,
+		AllowSpectators:       true,
+	}
+}
+
+// ParseDisconnectRule parses a DisconnectRule, an empty string is DefaultDisconnectRule
+func ParseDisconnectRule(s string) (DisconnectRule, error) {
+	switch rule := DisconnectRule(s); rule {
+	case "":
+		return DefaultDisconnectRule, nil
+	case DisconnectRuleNone, DisconnectRulePass, DisconnectRuleForfeit:
+		return rule, nil
+	default:
+		return "", fmt.Errorf("unknown disconnect rule %q", s)
+	}
+}
+
+// TurnPasser is implemented by games where the turn player can give up their turn
+// (e.g. end the turn without playing a card), used by DisconnectRulePass
+type TurnPasser interface {
+	PassTurn(playerID PlayerID) error
+}
+
+// PlayerWaiter is implemented by games where the duel can wait for several players at once
+// (e.g. simultaneous moves), WaitingFor returns the players who still have to act
+type PlayerWaiter interface {
+	WaitingFor() []PlayerID
+}
+
+// WaitingFor returns the players the running duel waits for: the turn player,
+// or the players who still have to act if the game implements PlayerWaiter
+func (d *Duel) WaitingFor() []PlayerID {
+	if d.State != DuelStateRunning {
+		return nil
+	}
+	if d.TurnPlayer != "" {
+		return []PlayerID{d.TurnPlayer}
+	}
+	if w, ok := d.Game.(PlayerWaiter); ok {
+		return w.WaitingFor()
+	}
+	return nil
+}
+
+// Abandon ends the duel because the player left it: the other player wins,
+// or the other team in a team duel. With several other players (or teams) left, it is a draw.
+func (d *Duel) Abandon(playerID PlayerID) {
+	d.LogAction(playerID, "ABANDON", map[string]interface{}{})
+	if team, ok := d.TeamOf(playerID); ok {
+		var others []TeamID
+		for _, t := range d.Teams {
+			if t.ID != team.ID {
+				others = append(others, t.ID)
+			}
+		}
+		if len(others) == 1 {
+			d.SetWinnerTeam(others[0])
+		} else {
+			d.SetDraw()
+		}
+	} else {
+		var others []PlayerID
+		for _, pid := range d.Players {
+			if pid != playerID {
+				others = append(others, pid)
+			}
+		}
+		if len(others) == 1 {
+			d.SetWinner(others[0])
+		} else {
+			d.SetDraw()
+		}
+	}
+	d.EndReason = EndReasonAbandoned
+}
@@ -0,0 +1,83 @@
+package turnbased
+
+import (
+	"fmt"
+)
+
+// SimultaneousMoves collects hidden moves of several players for one round,
+// for games where players act at the same time (e.g. rock-paper-scissors).
+// Each player commits once per round, the moves are only revealed together
+// when every player has committed, so nobody can react to the others' moves.
+// During such a round, the duel is RUNNING with an empty TurnPlayer.
+type SimultaneousMoves[T any] struct {
+	players []PlayerID
+	moves   map[PlayerID]T
+}
+
+// NewSimultaneousMoves creates an empty round for the players
+func NewSimultaneousMoves[T any](players []PlayerID) *SimultaneousMoves[T] {
+	return &SimultaneousMoves[T]{
+		players: players,
+		moves:   make(map[PlayerID]T),
+	}
+}
+
+// Commit records the player's hidden move for the current round,
+// a player cannot change their move once committed
+func (s *SimultaneousMoves[T]) Commit(player PlayerID, move T) error {
+	isPlayer := false
+	for _, pid := range s.players {
+		if pid == player {
+			isPlayer = true
+			break
+		}
+	}
+	if !isPlayer {
+		return fmt.Errorf("player %s is not in the round", player)
+	}
+	if _, ok := s.moves[player]; ok {
+		return fmt.Errorf("player %s already committed this round", player)
+	}
+	s.moves[player] = move
+	return nil
+}
+
+// HasCommitted returns true if the player has committed a move in the current round
+func (s *SimultaneousMoves[T]) HasCommitted(player PlayerID) bool {
+	_, ok := s.moves[player]
+	return ok
+}
+
+// Move returns the player's committed move, only the player themselves should see it
+// before the round is revealed
+func (s *SimultaneousMoves[T]) Move(player PlayerID) (T, bool) {
+	move, ok := s.moves[player]
+	return move, ok
+}
+
+// Waiting returns the players who have not committed yet, in player order
+func (s *SimultaneousMoves[T]) Waiting() []PlayerID {
+	var waiting []PlayerID
+	for _, pid := range s.players {
+		if _, ok := s.moves[pid]; !ok {
+			waiting = append(waiting, pid)
+		}
+	}
+	return waiting
+}
+
+// Complete returns true if every player has committed
+func (s *SimultaneousMoves[T]) Complete() bool {
+	return len(s.moves) == len(s.players)
+}
+
+// Reveal returns all moves and starts a new round,
+// it fails if some players have not committed yet
+func (s *SimultaneousMoves[T]) Reveal() (map[PlayerID]T, error) {
+	if !s.Complete() {
+		return nil, fmt.Errorf("waiting for %d players", len(s.Waiting()))
+	}
+	moves := s.moves
+	s.moves = make(map[PlayerID]T)
+	return moves, nil
+}
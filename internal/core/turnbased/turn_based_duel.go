@@ -19,13 +19,26 @@ type Duel struct {
 	ID         DuelID     // Unique identifier for this duel
 	Players    []PlayerID // Player IDs (supports any number of players)
 	Turn       int        // Current turn number (starts from 1)
-	TurnPlayer PlayerID   // Player ID whose turn it is
-	Winner     PlayerID   // Player ID if someone has won, empty if ongoing, "DRAW" for draw
+	TurnPlayer PlayerID   // Player ID whose turn it is, empty if all players act simultaneously
+	Winner     PlayerID   // Player ID if someone has won, empty if ongoing, "DRAW" for draw, team ID if a team has won
 	State      DuelState  // BEGIN, RUNNING, END
 	Game       GameLogic
 	ActionLog  []ActionLogEntry // Log of all actions for replay
+	Teams      []Team           // Empty if every player plays for themselves
+	WinnerTeam TeamID           // Team ID if a team has won
+	EndReason  string           // Why the duel ended, game-specific (e.g. "CHECKMATE"), empty if not given
+	Settings   Settings         // Options chosen when the duel was created
 }
 
+// Team is a group of players who share the result of the duel
+type Team struct {
+	ID      TeamID
+	Players []PlayerID
+}
+
+// TeamID is a unique identifier for a team in a duel
+type TeamID string
+
 // GameLogic is implemented differently for each game,
 // but all implementations share some common methods
 // so that the centralized router can interact with them.
@@ -35,6 +48,23 @@ type GameLogic interface {
 	// Add more methods as needed for your engine
 }
 
+// PlayerStateViewer is implemented by games with hidden information
+// (e.g. cards in hand, ship positions). GetStateForPlayer returns the state as seen
+// by the viewer, an empty viewer is an outsider who only sees public information.
+// For such games, GetState should return the public state so that secrets never leak by default.
+type PlayerStateViewer interface {
+	GetStateForPlayer(viewer PlayerID) any
+}
+
+// StateForPlayer returns the game state as seen by the viewer,
+// games without hidden information return the same state to everyone.
+func StateForPlayer(game GameLogic, viewer PlayerID) any {
+	if v, ok := game.(PlayerStateViewer); ok {
+		return v.GetStateForPlayer(viewer)
+	}
+	return game.GetState()
+}
+
 // NewDuel creates a new Duel with the given players.
 func NewDuel(id DuelID, players []PlayerID) *Duel {
 	return &Duel{
@@ -45,6 +75,7 @@ func NewDuel(id DuelID, players []PlayerID) *Duel {
 		Winner:     "",
 		State:      DuelStateBegin,
 		ActionLog:  []ActionLogEntry{},
+		Settings:   DefaultSettings(),
 	}
 }
 
@@ -78,6 +109,26 @@ func (d *Duel) NextTurn() {
 	d.TurnPlayer = d.Players[nextIdx]
 }
 
+// NextTurnTo advances the duel to the next turn and gives it to the player,
+// for games where the next player is not simply the next one in order
+// (e.g. the winner of a trick leads the next trick).
+func (d *Duel) NextTurnTo(playerID PlayerID) {
+	d.Turn++
+	d.TurnPlayer = playerID
+}
+
+// TeamOf returns the team of the player, false if the player is not in any team.
+func (d *Duel) TeamOf(playerID PlayerID) (Team, bool) {
+	for _, team := range d.Teams {
+		for _, pid := range team.Players {
+			if pid == playerID {
+				return team, true
+			}
+		}
+	}
+	return Team{}, false
+}
+
 // IsOver returns true if the duel has ended.
 func (d *Duel) IsOver() bool {
 	return d.State == DuelStateEnd && d.Winner != ""
@@ -89,6 +140,14 @@ func (d *Duel) SetWinner(winnerID PlayerID) {
 	d.State = DuelStateEnd
 }
 
+// SetWinnerTeam sets the winning team and ends the duel,
+// Winner also holds the team ID so that code checking Winner still works.
+func (d *Duel) SetWinnerTeam(teamID TeamID) {
+	d.WinnerTeam = teamID
+	d.Winner = PlayerID(teamID)
+	d.State = DuelStateEnd
+}
+
 // SetDraw ends the duel as a draw.
 func (d *Duel) SetDraw() {
 	d.Winner = "DRAW"
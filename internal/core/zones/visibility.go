@@ -0,0 +1,66 @@
+package zones
+
+// Visibility tells who can see the items of a zone
+type Visibility string
+
+// Visibility enum
+const (
+	// VisibilityPublic: everyone sees the items (e.g. field, graveyard)
+	VisibilityPublic Visibility = "PUBLIC"
+	// VisibilityOwner: only the owner sees the items, others only see the count (e.g. hand)
+	VisibilityOwner Visibility = "OWNER"
+	// VisibilityHidden: nobody sees the items, only the count (e.g. deck)
+	VisibilityHidden Visibility = "HIDDEN"
+)
+
+// CanSee returns true if the viewer can see the items of a zone with this visibility
+func (v Visibility) CanSee(isOwner bool) bool {
+	switch v {
+	case VisibilityPublic:
+		return true
+	case VisibilityOwner:
+		return isOwner
+	default:
+		return false
+	}
+}
+
+// Reveals records items revealed to everyone despite being in a non-public zone
+// (e.g. a card shown from hand), by item ID
+type Reveals map[string]bool
+
+// Reveal makes the item visible to everyone
+func (r Reveals) Reveal(id string) {
+	r[id] = true
+}
+
+// Conceal hides a revealed item again, e.g. when it is shuffled back into the deck
+func (r Reveals) Conceal(id string) {
+	delete(r, id)
+}
+
+// IsRevealed returns true if the item was revealed, a nil Reveals has no revealed item
+func (r Reveals) IsRevealed(id string) bool {
+	return r[id]
+}
+
+// View is a zone as seen by a viewer
+type View[T Item] struct {
+	// Items is the items the viewer can see, in zone order
+	Items []T
+	// Count is the number of items in the zone, including the ones the viewer cannot see
+	Count int
+}
+
+// ViewFor returns the zone as seen by a viewer: all items if the visibility allows it,
+// otherwise only the revealed items
+func (z Zone[T]) ViewFor(visibility Visibility, isOwner bool, reveals Reveals) View[T] {
+	view := View[T]{Items: []T{}, Count: len(z)}
+	canSee := visibility.CanSee(isOwner)
+	for _, item := range z {
+		if canSee || reveals.IsRevealed(item.ItemID()) {
+			view.Items = append(view.Items, item)
+		}
+	}
+	return view
+}
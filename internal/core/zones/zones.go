@@ -0,0 +1,141 @@
+// Package zones is a toolkit for card games: ordered zones of items (deck, hand, field, graveyard)
+// with shuffle, draw, move between zones, search by ID, and visibility rules for hidden information
+package zones
+
+import (
+	"math/rand/v2"
+)
+
+// Item is anything that can be put in a zone, usually a card,
+// ItemID must be unique across all zones of a duel
+type Item interface {
+	ItemID() string
+}
+
+// Zone is an ordered list of items, index 0 is the top (e.g. the next card to draw from a deck).
+// It is a slice, so it can be indexed, ranged over and passed where a slice is expected.
+type Zone[T Item] []T
+
+// Len returns the number of items in the zone
+func (z Zone[T]) Len() int {
+	return len(z)
+}
+
+// Shuffle randomizes the order of the items, the RNG is supplied by the game
+// so that a seeded duel is reproducible
+func (z Zone[T]) Shuffle(random *rand.Rand) {
+	random.Shuffle(len(z), func(i, j int) { z[i], z[j] = z[j], z[i] })
+}
+
+// Add puts items at the bottom of the zone
+func (z *Zone[T]) Add(items ...T) {
+	*z = append(*z, items...)
+}
+
+// AddTop puts an item on the top of the zone
+func (z *Zone[T]) AddTop(item T) {
+	*z = append(Zone[T]{item}, *z...)
+}
+
+// Top returns the top item without removing it, false if the zone is empty
+func (z Zone[T]) Top() (T, bool) {
+	if len(z) == 0 {
+		var zero T
+		return zero, false
+	}
+	return z[0], true
+}
+
+// Draw removes and returns the top item, false if the zone is empty
+func (z *Zone[T]) Draw() (T, bool) {
+	item, ok := z.Top()
+	if !ok {
+		return item, false
+	}
+	*z = (*z)[1:]
+	return item, true
+}
+
+// IndexOf returns the index of the item with the ID, -1 if not found
+func (z Zone[T]) IndexOf(id string) int {
+	for i, item := range z {
+		if item.ItemID() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Find returns the item with the ID, false if not found
+func (z Zone[T]) Find(id string) (T, bool) {
+	i := z.IndexOf(id)
+	if i == -1 {
+		var zero T
+		return zero, false
+	}
+	return z[i], true
+}
+
+// Contains returns true if the zone has the item with the ID
+func (z Zone[T]) Contains(id string) bool {
+	return z.IndexOf(id) != -1
+}
+
+// Remove removes and returns the item with the ID, false if not found
+func (z *Zone[T]) Remove(id string) (T, bool) {
+	i := z.IndexOf(id)
+	if i == -1 {
+		var zero T
+		return zero, false
+	}
+	item := (*z)[i]
+	*z = append((*z)[:i], (*z)[i+1:]...)
+	return item, true
+}
+
+// RemoveIf removes all items matching the predicate, keeping the order of the others,
+// returns the removed items in order
+func (z *Zone[T]) RemoveIf(match func(T) bool) []T {
+	var removed []T
+	kept := (*z)[:0]
+	for _, item := range *z {
+		if match(item) {
+			removed = append(removed, item)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+	*z = kept
+	return removed
+}
+
+// IDs returns the IDs of the items in order
+func (z Zone[T]) IDs() []string {
+	ids := make([]string, len(z))
+	for i, item := range z {
+		ids[i] = item.ItemID()
+	}
+	return ids
+}
+
+// Move moves the item with the ID from the bottom of one zone to the bottom of another,
+// returns the moved item, false if it is not in the source zone
+func Move[T Item](from *Zone[T], to *Zone[T], id string) (T, bool) {
+	item, ok := from.Remove(id)
+	if !ok {
+		return item, false
+	}
+	to.Add(item)
+	return item, true
+}
+
+// DrawTo moves the top item of one zone to the bottom of another (e.g. deck to hand),
+// returns the moved item, false if the source zone is empty
+func DrawTo[T Item](from *Zone[T], to *Zone[T]) (T, bool) {
+	item, ok := from.Draw()
+	if !ok {
+		return item, false
+	}
+	to.Add(item)
+	return item, true
+}
@@ -0,0 +1,103 @@
+package zones
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+type testCard string
+
+func (c testCard) ItemID() string { return string(c) }
+
+func newZone(ids ...string) Zone[testCard] {
+	z := Zone[testCard]{}
+	for _, id := range ids {
+		z.Add(testCard(id))
+	}
+	return z
+}
+
+func TestDrawAndMove(t *testing.T) {
+	deck := newZone("a", "b", "c")
+	hand := Zone[testCard]{}
+
+	card, ok := DrawTo(&deck, &hand)
+	if !ok || card != "a" {
+		t.Fatalf("Expected to draw a, got %q %v", card, ok)
+	}
+	if !reflect.DeepEqual(deck.IDs(), []string{"b", "c"}) || !reflect.DeepEqual(hand.IDs(), []string{"a"}) {
+		t.Errorf("Unexpected zones after draw: deck %v hand %v", deck.IDs(), hand.IDs())
+	}
+
+	if _, ok := Move(&hand, &deck, "x"); ok {
+		t.Error("Move should fail for an item not in the source zone")
+	}
+	if _, ok := Move(&deck, &hand, "c"); !ok {
+		t.Fatal("Move failed")
+	}
+	if deck.Contains("c") || !hand.Contains("c") || hand.IndexOf("c") != 1 {
+		t.Errorf("Expected c moved to the bottom of hand: deck %v hand %v", deck.IDs(), hand.IDs())
+	}
+
+	deck.AddTop("z")
+	if top, _ := deck.Top(); top != "z" {
+		t.Errorf("Expected z on top, got %q", top)
+	}
+	deck.Draw()
+	deck.Draw()
+	if _, ok := deck.Draw(); ok || deck.Len() != 0 {
+		t.Errorf("Draw from an empty zone should fail, zone %v", deck.IDs())
+	}
+}
+
+func TestFindAndRemove(t *testing.T) {
+	z := newZone("a", "b", "c", "d")
+	if card, ok := z.Find("c"); !ok || card != "c" {
+		t.Errorf("Find c failed: %q %v", card, ok)
+	}
+	if _, ok := z.Find("x"); ok {
+		t.Error("Find should fail for a missing item")
+	}
+	if _, ok := z.Remove("b"); !ok || !reflect.DeepEqual(z.IDs(), []string{"a", "c", "d"}) {
+		t.Errorf("Remove b failed: %v", z.IDs())
+	}
+	removed := z.RemoveIf(func(c testCard) bool { return c != "c" })
+	if !reflect.DeepEqual(z.IDs(), []string{"c"}) || len(removed) != 2 || removed[1] != "d" {
+		t.Errorf("RemoveIf failed: kept %v removed %v", z.IDs(), removed)
+	}
+}
+
+func TestShuffle_SeededIsReproducible(t *testing.T) {
+	ids := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}
+	z1, z2 := newZone(ids...), newZone(ids...)
+	z1.Shuffle(rand.New(rand.NewPCG(42, 7)))
+	z2.Shuffle(rand.New(rand.NewPCG(42, 7)))
+	if !reflect.DeepEqual(z1.IDs(), z2.IDs()) {
+		t.Errorf("Same seed should give the same order: %v %v", z1.IDs(), z2.IDs())
+	}
+	if reflect.DeepEqual(z1.IDs(), ids) {
+		t.Errorf("Shuffle did not change the order: %v", z1.IDs())
+	}
+}
+
+func TestViewFor(t *testing.T) {
+	hand := newZone("a", "b", "c")
+	reveals := Reveals{}
+	reveals.Reveal("b")
+
+	if view := hand.ViewFor(VisibilityOwner, true, reveals); len(view.Items) != 3 || view.Count != 3 {
+		t.Errorf("Owner should see the whole hand, got %+v", view)
+	}
+	view := hand.ViewFor(VisibilityOwner, false, reveals)
+	if len(view.Items) != 1 || view.Items[0] != "b" || view.Count != 3 {
+		t.Errorf("Opponent should only see the revealed card and the count, got %+v", view)
+	}
+	reveals.Conceal("b")
+	if view := hand.ViewFor(VisibilityHidden, true, reveals); len(view.Items) != 0 || view.Count != 3 {
+		t.Errorf("Nobody should see a hidden zone, got %+v", view)
+	}
+	if view := hand.ViewFor(VisibilityPublic, false, nil); len(view.Items) != 3 {
+		t.Errorf("Everyone should see a public zone, got %+v", view)
+	}
+}
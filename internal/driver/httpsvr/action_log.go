@@ -0,0 +1,105 @@
+package httpsvr
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// Limits of the number of entries in an ActionLogPage
+const (
+	DefaultActionLogLimit = 100
+	MaxActionLogLimit     = 1000
+)
+
+// ActionLogPage is the response of GET /api/duel/{duelID}/log
+type ActionLogPage struct {
+	DuelID  string                             `json:"duel_id"`
+	Entries []model.SerializableActionLogEntry `json:"entries"`
+	// NextSeq is the from_seq to request the next page, 0 if there is no more matching entry
+	NextSeq int `json:"next_seq"`
+	// Total is the number of entries in the whole log, before filtering
+	Total int `json:"total"`
+}
+
+// actionLogFilter selects entries of an action log, zero values do not filter
+type actionLogFilter struct {
+	FromSeq int      // inclusive
+	ToSeq   int      // inclusive
+	Limit   int      // max number of returned entries
+	Players []string // entries by any of these players
+	Actions []string // entries of any of these action types, e.g. "PLAY_CARD"
+}
+
+// parseActionLogFilter reads the query parameters from_seq, to_seq, limit,
+// player and action (the last two can be repeated or comma-separated)
+func parseActionLogFilter(query url.Values) (actionLogFilter, error) {
+	filter := actionLogFilter{Limit: DefaultActionLogLimit}
+	for name, field := range map[string]*int{"from_seq": &filter.FromSeq, "to_seq": &filter.ToSeq, "limit": &filter.Limit} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return filter, fmt.Errorf("%s must be a non-negative integer, got %q", name, value)
+		}
+		*field = n
+	}
+	if filter.Limit == 0 {
+		return filter, fmt.Errorf("limit must be positive")
+	}
+	filter.Limit = min(filter.Limit, MaxActionLogLimit)
+	filter.Players = splitQueryValues(query["player"])
+	filter.Actions = splitQueryValues(query["action"])
+	return filter, nil
+}
+
+func splitQueryValues(values []string) []string {
+	var ret []string
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				ret = append(ret, v)
+			}
+		}
+	}
+	return ret
+}
+
+func (f actionLogFilter) match(entry model.SerializableActionLogEntry) bool {
+	if entry.Seq < f.FromSeq || (f.ToSeq > 0 && entry.Seq > f.ToSeq) {
+		return false
+	}
+	if len(f.Players) > 0 && !slices.Contains(f.Players, entry.PlayerID) {
+		return false
+	}
+	if len(f.Actions) > 0 && !slices.Contains(f.Actions, entry.Action) {
+		return false
+	}
+	return true
+}
+
+// page returns the first Limit matching entries of the duel log, in seq order
+func (f actionLogFilter) page(duel model.SerializableDuel) ActionLogPage {
+	page := ActionLogPage{
+		DuelID:  duel.ID,
+		Entries: []model.SerializableActionLogEntry{},
+		Total:   len(duel.ActionLog),
+	}
+	for _, entry := range duel.ActionLog {
+		if !f.match(entry) {
+			continue
+		}
+		if len(page.Entries) == f.Limit {
+			page.NextSeq = entry.Seq
+			break
+		}
+		page.Entries = append(page.Entries, entry)
+	}
+	return page
+}
@@ -2,12 +2,15 @@
 package httpsvr
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
 )
 
 // allowCORS is a middleware that sets CORS headers to allow requests from specified origins.
@@ -30,10 +33,35 @@ func allowCORS() func(http.Handler) http.Handler {
 	}
 }
 
+// CreateDuelRequest is the body of POST /api/duel
+type CreateDuelRequest struct {
+	Game    string   `json:"game,omitempty"` // can be given in the query instead
+	Players []string `json:"players"`
+	// Bots is optional: player ID -> bot kind, see ClientMessage.Bots
+	Bots map[string]string `json:"bots,omitempty"`
+	// Settings is optional, see ClientMessage.Settings
+	Settings *model.DuelSettings `json:"settings,omitempty"`
+}
+
+// ActionRequest is the body of POST /api/duel/{duelID}/action
+type ActionRequest struct {
+	PlayerID string           `json:"player_id"`
+	Action   model.ActionData `json:"action"`
+}
+
 // NewHandlerAPI creates an API handler that routes actions to the correct DuelsManager by game name.
+// Responses are JSON ServerMessage: a state_update as seen by the acting player, or an error.
+// State changes are fanned out to WebSocket connections by the shared processors,
+// and to the SSE and long-poll subscribers of this API (see stream.go).
 //
-// duelsManagers: this map keys are game names
-func NewHandlerAPI(duelsManagers map[string]turnbased.DuelsManager) http.Handler {
+// duelsManagers: this map keys are game names,
+// processors: the action processors of the games, e.g. WebSocketHandler.ActionProcessors,
+// connectionMgr: the connection manager of the processors
+func NewHandlerAPI(
+	duelsManagers map[string]turnbased.DuelsManager,
+	processors map[string]ActionProcessor,
+	connectionMgr *ConnectionManager,
+) http.Handler {
 	handler := http.NewServeMux()
 
 	handler.HandleFunc("/api/hello", func(w http.ResponseWriter, r *http.Request) {
@@ -42,46 +70,135 @@ func NewHandlerAPI(duelsManagers map[string]turnbased.DuelsManager) http.Handler
 		_, _ = w.Write([]byte(response))
 	})
 
-	// Example: POST /api/duel?game=GAME_NAME
+	// Example: POST /api/duel?game=GAME_NAME {"players": ["alice", "bob"]}
 	handler.HandleFunc("/api/duel", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
-			game := r.URL.Query().Get("game")
-			if game == "" {
-				w.WriteHeader(http.StatusBadRequest)
-				_, _ = w.Write([]byte("missing game name"))
-				return
-			}
-			duelsManager, ok := duelsManagers[game]
-			if !ok {
-				w.WriteHeader(http.StatusBadRequest)
-				_, _ = w.Write([]byte("unknown game: " + game))
-				return
-			}
-			_ = duelsManager
+		if r.Method != http.MethodPost {
+			writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+			return
+		}
+		var req CreateDuelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+		if game := r.URL.Query().Get("game"); game != "" {
+			req.Game = game
 		}
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		duel, err := createDuel(processors, req.Game, req.Players, req.Bots, req.Settings)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeAPIResponse(w, http.StatusCreated, connectionMgr.StateUpdateMessage(duel, duel.Players[0]))
 	})
 
-	// Example: POST /api/duel/{duelID}/action?game=GAME_NAME
+	// Example: POST /api/duel/{duelID}/action?game=GAME_NAME {"player_id": "alice", "action": {"column": 3}},
+	// the game can be omitted, it is then found from the duel ID
 	handler.HandleFunc("/api/duel/{duelID}/action", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodPost {
-			game := r.URL.Query().Get("game")
-			if game == "" {
-				w.WriteHeader(http.StatusBadRequest)
-				_, _ = w.Write([]byte("missing game name"))
-				return
-			}
-			duelsManager, ok := duelsManagers[game]
-			if !ok {
-				w.WriteHeader(http.StatusBadRequest)
-				_, _ = w.Write([]byte("unknown game: " + game))
-				return
-			}
-			_ = duelsManager
+		if r.Method != http.MethodPost {
+			writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+			return
+		}
+		duelID := turnbased.DuelID(r.PathValue("duelID"))
+		game := r.URL.Query().Get("game")
+		if game == "" {
+			_, game = findDuel(duelsManagers, duelID)
+		}
+		if manager, ok := duelsManagers[game]; !ok || manager.GetDuel(duelID) == nil {
+			writeAPIError(w, http.StatusNotFound, errorWithCode(ErrorCodeDuelNotFound, "duel not found: %s", duelID))
+			return
+		}
+		var req ActionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+		playerID := turnbased.PlayerID(req.PlayerID)
+		var response ServerMessage
+		err := processAction(duelsManagers, processors, connectionMgr, game, duelID, playerID, req.Action,
+			func(duel *turnbased.Duel) { response = connectionMgr.StateUpdateMessage(duel, playerID) })
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err)
 			return
 		}
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeAPIResponse(w, http.StatusOK, response)
 	})
 
+	// Example: GET /api/duel/{duelID}?player_id=alice returns the state_update as seen by the player,
+	// without player_id it is the public state (no hidden information)
+	handler.HandleFunc("/api/duel/{duelID}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+			return
+		}
+		duel, viewer, unlock, status, err := getDuelForViewer(duelsManagers, r)
+		if err != nil {
+			writeAPIError(w, status, err)
+			return
+		}
+		msg := connectionMgr.StateUpdateMessage(duel, viewer)
+		unlock()
+		writeAPIResponse(w, http.StatusOK, msg)
+	})
+
+	// Example: GET /api/duel/{duelID}/log?from_seq=10&to_seq=50&limit=20&player=alice&action=PLAY_CARD
+	// returns a page of the action log, see ActionLogPage
+	handler.HandleFunc("/api/duel/{duelID}/log", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+			return
+		}
+		duel, _, unlock, status, err := getDuelForViewer(duelsManagers, r)
+		if err != nil {
+			writeAPIError(w, status, err)
+			return
+		}
+		filter, err := parseActionLogFilter(r.URL.Query())
+		if err != nil {
+			unlock()
+			writeAPIError(w, http.StatusBadRequest, err)
+			return
+		}
+		page := filter.page(model.FromDuel(duel))
+		unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(page)
+	})
+
+	// Example: GET /api/duel/{duelID}/events?player_id=alice streams the messages as Server-Sent Events
+	handler.HandleFunc("/api/duel/{duelID}/events", handleEvents(duelsManagers, connectionMgr))
+
+	// Example: GET /api/duel/{duelID}/poll?player_id=alice&cursor=0 returns a PollResponse,
+	// the next poll uses its cursor
+	handler.HandleFunc("/api/duel/{duelID}/poll", handlePoll(duelsManagers, connectionMgr))
+
 	return allowCORS()(handler)
 }
+
+// getDuelForViewer finds the duel of the path and checks the optional player_id query.
+// The duel is locked (see turnbased.DuelsManager.LockDuel) so that it can be read while players act,
+// the caller calls unlock once done reading it. On error it returns the HTTP status to respond.
+func getDuelForViewer(duelsManagers map[string]turnbased.DuelsManager, r *http.Request) (
+	duel *turnbased.Duel, viewer turnbased.PlayerID, unlock func(), status int, err error) {
+	duelID := turnbased.DuelID(r.PathValue("duelID"))
+	duel, game := findDuel(duelsManagers, duelID)
+	if duel == nil {
+		return nil, "", nil, http.StatusNotFound, errorWithCode(ErrorCodeDuelNotFound, "duel not found: %s", duelID)
+	}
+	viewer = turnbased.PlayerID(r.URL.Query().Get("player_id"))
+	if viewer != "" && !slices.Contains(duel.Players, viewer) {
+		return nil, "", nil, http.StatusBadRequest, errorWithCode(ErrorCodePlayerNotInDuel, "player %s is not in duel %s", viewer, duelID)
+	}
+	return duel, viewer, duelsManagers[game].LockDuel(duelID), http.StatusOK, nil
+}
+
+func writeAPIResponse(w http.ResponseWriter, status int, msg ServerMessage) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(msg)
+}
+
+// writeAPIError responds an error message with the code of err, see errorCode
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeAPIResponse(w, status, NewErrorMessage("", err))
+}
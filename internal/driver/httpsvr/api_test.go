@@ -0,0 +1,337 @@
+package httpsvr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
+	"github.com/daominah/turn_based_game/internal/core/connect_four"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// TestAPI_CreateDuelAndAction plays over REST and checks that a WebSocket watcher gets the updates
+func TestAPI_CreateDuelAndAction(t *testing.T) {
+	duelsManagers := map[string]turnbased.DuelsManager{connect_four.GameName: turnbased.NewInMemoryDuelsManager()}
+	connectionMgr := NewConnectionManager()
+	wsHandler := NewWebSocketHandler(duelsManagers, connectionMgr)
+	mux := http.NewServeMux()
+	mux.Handle("/api/", NewHandlerAPI(duelsManagers, wsHandler.ActionProcessors(), connectionMgr))
+	mux.HandleFunc("/ws", wsHandler.HandleWebSocket)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	post := func(path string, body any) (int, ServerMessage) {
+		data, _ := json.Marshal(body)
+		resp, err := http.Post(server.URL+path, "application/json", bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("error POST %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var msg ServerMessage
+		if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+			t.Fatalf("error decode response of %s: %v", path, err)
+		}
+		return resp.StatusCode, msg
+	}
+
+	status, created := post("/api/duel?game="+connect_four.GameName, CreateDuelRequest{Players: []string{"alice", "bob"}})
+	if status != http.StatusCreated || created.Type != MessageTypeStateUpdate || created.Duel == nil {
+		t.Fatalf("create duel: got %d %+v", status, created)
+	}
+	if created.GameState == nil {
+		t.Errorf("expected game state in the created duel")
+	}
+
+	// bob watches over WebSocket
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	bob, _, err := websocket.Dial(ctx, "ws"+server.URL[4:]+"/ws", nil)
+	if err != nil {
+		t.Fatalf("error Dial: %v", err)
+	}
+	defer bob.Close(websocket.StatusNormalClosure, "")
+	joinData, _ := json.Marshal(ClientMessage{Type: MessageTypeJoinDuel, DuelID: created.Duel.ID, PlayerID: "bob"})
+	if err := bob.Write(ctx, websocket.MessageText, joinData); err != nil {
+		t.Fatalf("error join: %v", err)
+	}
+	if _, _, err := bob.Read(ctx); err != nil {
+		t.Fatalf("error read join state: %v", err)
+	}
+
+	column := 3
+	actionPath := "/api/duel/" + created.Duel.ID + "/action"
+	status, played := post(actionPath, ActionRequest{PlayerID: "alice", Action: model.ActionData{Column: &column}})
+	if status != http.StatusOK || played.Type != MessageTypeStateUpdate {
+		t.Fatalf("action: got %d %+v", status, played)
+	}
+	if played.Duel.Turn != 2 || played.Duel.TurnPlayer != "bob" {
+		t.Errorf("expected turn 2 of bob, got %d %s", played.Duel.Turn, played.Duel.TurnPlayer)
+	}
+	_, data, err := bob.Read(ctx)
+	if err != nil {
+		t.Fatalf("error read fanout: %v", err)
+	}
+	var fanout ServerMessage
+	_ = json.Unmarshal(data, &fanout)
+	if fanout.Type != MessageTypeStateUpdate || fanout.Duel.Turn != 2 {
+		t.Errorf("expected the fanned out state of turn 2, got %+v", fanout)
+	}
+
+	// errors are JSON too
+	status, rejected := post(actionPath, ActionRequest{PlayerID: "alice", Action: model.ActionData{Column: &column}})
+	if status != http.StatusBadRequest || rejected.Type != MessageTypeError || rejected.Error == "" ||
+		rejected.Code != ErrorCodeNotPlayerTurn {
+		t.Errorf("out of turn action: got %d %+v", status, rejected)
+	}
+	status, notFound := post("/api/duel/nope/action", ActionRequest{PlayerID: "alice", Action: model.ActionData{Column: &column}})
+	if status != http.StatusNotFound || notFound.Type != MessageTypeError || notFound.Code != ErrorCodeDuelNotFound {
+		t.Errorf("unknown duel: got %d %+v", status, notFound)
+	}
+	status, _ = post("/api/duel?game=NOPE", CreateDuelRequest{Players: []string{"alice"}})
+	if status != http.StatusBadRequest {
+		t.Errorf("unknown game: got %d", status)
+	}
+}
+
+// TestAPI_GetDuelAndLog checks the redacted state and the action log paging
+func TestAPI_GetDuelAndLog(t *testing.T) {
+	duelsManagers := map[string]turnbased.DuelsManager{card_game_burn.GameName: turnbased.NewInMemoryDuelsManager()}
+	connectionMgr := NewConnectionManager()
+	wsHandler := NewWebSocketHandler(duelsManagers, connectionMgr)
+	server := httptest.NewServer(NewHandlerAPI(duelsManagers, wsHandler.ActionProcessors(), connectionMgr))
+	defer server.Close()
+
+	duel, err := wsHandler.ActionProcessors()[card_game_burn.GameName].CreateDuel(
+		card_game_burn.GameName, []turnbased.PlayerID{"alice", "bob"})
+	if err != nil {
+		t.Fatalf("error CreateDuel: %v", err)
+	}
+	burnDuel := duel.Game.(*card_game_burn.BurnDuel)
+	for i := 0; i < 6; i++ { // END_TURN entries of both players
+		burnDuel.EndTurn()
+	}
+
+	get := func(path string, v any) int {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("error GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("error decode response of %s: %v", path, err)
+		}
+		return resp.StatusCode
+	}
+	getState := func(path string) (int, model.BurnGameState) {
+		var msg ServerMessage
+		status := get(path, &msg)
+		state, _ := json.Marshal(msg.GameState)
+		var burnState model.BurnGameState
+		_ = json.Unmarshal(state, &burnState)
+		return status, burnState
+	}
+
+	duelPath := "/api/duel/" + string(duel.ID)
+	status, aliceView := getState(duelPath + "?player_id=alice")
+	if status != http.StatusOK || len(aliceView.Players["alice"].Hand) == 0 || len(aliceView.Players["bob"].Hand) != 0 {
+		t.Errorf("alice should only see their own hand: %d %+v", status, aliceView)
+	}
+	status, public := getState(duelPath)
+	if status != http.StatusOK || len(public.Players["alice"].Hand) != 0 || public.Players["alice"].HandSize == 0 {
+		t.Errorf("the public view should only show hand sizes: %d %+v", status, public)
+	}
+	if status, _ := getState(duelPath + "?player_id=eve"); status != http.StatusBadRequest {
+		t.Errorf("a player not in the duel: got %d", status)
+	}
+	if status, _ := getState("/api/duel/nope"); status != http.StatusNotFound {
+		t.Errorf("unknown duel: got %d", status)
+	}
+
+	var page ActionLogPage
+	if status := get(duelPath+"/log?limit=2", &page); status != http.StatusOK {
+		t.Fatalf("log: got %d", status)
+	}
+	if len(page.Entries) != 2 || page.Entries[0].Seq != 1 || page.NextSeq != 3 || page.Total != 6 {
+		t.Errorf("unexpected first page: %+v", page)
+	}
+	page = ActionLogPage{}
+	get(duelPath+"/log?from_seq=3&to_seq=5&action=END_TURN&player="+string(burnDuel.Duel.ActionLog[3].PlayerID), &page)
+	if len(page.Entries) != 1 || page.Entries[0].Seq != 4 || page.NextSeq != 0 {
+		t.Errorf("unexpected filtered page: %+v", page)
+	}
+	page = ActionLogPage{}
+	get(duelPath+"/log?action=PLAY_CARD", &page)
+	if len(page.Entries) != 0 {
+		t.Errorf("expected no PLAY_CARD entry, got %+v", page.Entries)
+	}
+	var errMsg ServerMessage
+	if status := get(duelPath+"/log?limit=x", &errMsg); status != http.StatusBadRequest || errMsg.Type != MessageTypeError {
+		t.Errorf("invalid limit: got %d %+v", status, errMsg)
+	}
+}
+
+// TestAPI_ConcurrentReadAndAction reads a duel while actions are submitted, run with -race.
+// The handler is called directly: in-process sockets would order the requests for the race detector.
+func TestAPI_ConcurrentReadAndAction(t *testing.T) {
+	duelsManagers := map[string]turnbased.DuelsManager{card_game_burn.GameName: turnbased.NewInMemoryDuelsManager()}
+	connectionMgr := NewConnectionManager()
+	wsHandler := NewWebSocketHandler(duelsManagers, connectionMgr)
+	handler := NewHandlerAPI(duelsManagers, wsHandler.ActionProcessors(), connectionMgr)
+
+	duel, err := wsHandler.ActionProcessors()[card_game_burn.GameName].CreateDuel(
+		card_game_burn.GameName, []turnbased.PlayerID{"alice", "bob"})
+	if err != nil {
+		t.Fatalf("error CreateDuel: %v", err)
+	}
+	duelPath := "/api/duel/" + string(duel.ID)
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	for _, path := range []string{duelPath, duelPath + "?player_id=alice", duelPath + "/log"} {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+				if w.Code != http.StatusOK {
+					t.Errorf("GET %s: got %d", path, w.Code)
+					return
+				}
+			}
+		}()
+	}
+
+	endTurnsUntilEnd(t, handler, duel)
+	close(done)
+	readers.Wait()
+}
+
+// TestWebSocket_ConcurrentResyncAndAction is TestAPI_ConcurrentReadAndAction for the WebSocket resync,
+// it is meaningful with -race
+func TestWebSocket_ConcurrentResyncAndAction(t *testing.T) {
+	testConcurrentWithActions(t, func(wsHandler *WebSocketHandler, conn *websocket.Conn, duel *turnbased.Duel, i int) error {
+		return wsHandler.handleResync(conn)
+	})
+}
+
+// TestWebSocket_ConcurrentJoinAndAction is TestAPI_ConcurrentReadAndAction for joining,
+// with and without resuming
+func TestWebSocket_ConcurrentJoinAndAction(t *testing.T) {
+	testConcurrentWithActions(t, func(wsHandler *WebSocketHandler, conn *websocket.Conn, duel *turnbased.Duel, i int) error {
+		return wsHandler.handleJoinDuel(conn, &ClientMessage{
+			DuelID: string(duel.ID), PlayerID: string(duel.Players[i%2]), LastSeq: i % 3})
+	})
+}
+
+// TestWebSocket_ConcurrentSpectateAndAction is TestAPI_ConcurrentReadAndAction for spectating,
+// with and without resuming
+func TestWebSocket_ConcurrentSpectateAndAction(t *testing.T) {
+	testConcurrentWithActions(t, func(wsHandler *WebSocketHandler, conn *websocket.Conn, duel *turnbased.Duel, i int) error {
+		return wsHandler.handleSpectate(conn, &ClientMessage{DuelID: string(duel.ID), LastSeq: i % 3})
+	})
+}
+
+// testConcurrentWithActions plays a Burn duel over REST while goroutines call handle in a loop,
+// each on its own server connection that spectates the duel at first, i counts the calls.
+// The connections are handled directly, the reads and writes of sockets would hide the races from the detector.
+func testConcurrentWithActions(t *testing.T,
+	handle func(wsHandler *WebSocketHandler, conn *websocket.Conn, duel *turnbased.Duel, i int) error) {
+	duelsManagers := map[string]turnbased.DuelsManager{card_game_burn.GameName: turnbased.NewInMemoryDuelsManager()}
+	connectionMgr := NewConnectionManager()
+	wsHandler := NewWebSocketHandler(duelsManagers, connectionMgr)
+	handler := NewHandlerAPI(duelsManagers, wsHandler.ActionProcessors(), connectionMgr)
+	server := httptest.NewServer(http.HandlerFunc(wsHandler.HandleWebSocket))
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	duel, err := wsHandler.ActionProcessors()[card_game_burn.GameName].CreateDuel(
+		card_game_burn.GameName, []turnbased.PlayerID{"alice", "bob"})
+	if err != nil {
+		t.Fatalf("error CreateDuel: %v", err)
+	}
+	duel.Settings.AllowSpectators = true
+
+	for i := 0; i < 8; i++ {
+		conn, _, err := websocket.Dial(ctx, "ws"+server.URL[4:], nil)
+		if err != nil {
+			t.Fatalf("error Dial: %v", err)
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+		send(t, ctx, conn, ClientMessage{Type: MessageTypeSpectate, DuelID: string(duel.ID)})
+		if msg := read(t, ctx, conn); msg.Type != MessageTypeStateUpdate {
+			t.Fatalf("spectate: got %s %s", msg.Type, msg.Error)
+		}
+		go func() { // drains the messages until the connection is closed
+			for {
+				if _, _, err := conn.Read(ctx); err != nil {
+					return
+				}
+			}
+		}()
+	}
+	var serverConns []*websocket.Conn
+	connectionMgr.mu.RLock()
+	for conn := range connectionMgr.spectators {
+		serverConns = append(serverConns, conn.(*websocket.Conn))
+	}
+	connectionMgr.mu.RUnlock()
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	for _, conn := range serverConns {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if err := handle(wsHandler, conn, duel, i); err != nil {
+					t.Errorf("error handling connection: %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	endTurnsUntilEnd(t, handler, duel)
+	close(done)
+	readers.Wait()
+}
+
+// endTurnsUntilEnd posts end-turn actions of the turn player until the Burn duel ends
+func endTurnsUntilEnd(t *testing.T, handler http.Handler, duel *turnbased.Duel) {
+	t.Helper()
+	endTurn := true
+	turnPlayer := string(duel.TurnPlayer)
+	for {
+		data, _ := json.Marshal(ActionRequest{PlayerID: turnPlayer, Action: model.ActionData{EndTurn: &endTurn}})
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/duel/"+string(duel.ID)+"/action", bytes.NewReader(data)))
+		var msg ServerMessage
+		if err := json.NewDecoder(w.Body).Decode(&msg); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("POST action: got %d %+v %v", w.Code, msg, err)
+		}
+		if msg.Duel.State != string(turnbased.DuelStateRunning) {
+			return // a player decked out
+		}
+		turnPlayer = msg.Duel.TurnPlayer
+	}
+}
@@ -0,0 +1,88 @@
+package httpsvr
+
+import (
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/core/battleship"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// BattleshipActionProcessor processes actions for Battleship
+type BattleshipActionProcessor struct {
+	duelsManager  turnbased.DuelsManager
+	connectionMgr *ConnectionManager
+}
+
+// NewBattleshipActionProcessor creates a new Battleship action processor
+func NewBattleshipActionProcessor(duelsManager turnbased.DuelsManager) *BattleshipActionProcessor {
+	// Note: connectionMgr will be set by WebSocketHandler after creation
+	return &BattleshipActionProcessor{
+		duelsManager: duelsManager,
+	}
+}
+
+// SetConnectionManager sets the connection manager (called by WebSocketHandler)
+func (p *BattleshipActionProcessor) SetConnectionManager(cm *ConnectionManager) {
+	p.connectionMgr = cm
+}
+
+// CreateDuel creates a new Battleship duel
+func (p *BattleshipActionProcessor) CreateDuel(game string, players []turnbased.PlayerID) (*turnbased.Duel, error) {
+	bsDuel, err := battleship.NewBattleshipDuel(players)
+	if err != nil {
+		return nil, err
+	}
+	duel := bsDuel.Duel
+	duel.Game = bsDuel
+	return p.duelsManager.CreateDuel(duel), nil
+}
+
+// ProcessAction implements the three-stage flow: Message In → Persist → Fanout
+func (p *BattleshipActionProcessor) ProcessAction(duelID turnbased.DuelID, playerID turnbased.PlayerID, actionData model.ActionData) error {
+	action, err := p.parseAction(actionData)
+	if err != nil {
+		return fmt.Errorf("failed to parse action: %w", err)
+	}
+
+	duel := p.duelsManager.GetDuel(duelID)
+	if duel == nil {
+		return fmt.Errorf("duel not found: %s", duelID)
+	}
+	bsDuel, ok := duel.Game.(*battleship.BattleshipDuel)
+	if !ok {
+		return fmt.Errorf("duel is not a Battleship duel")
+	}
+	if err := bsDuel.HandleActionWithPlayer(action, playerID); err != nil {
+		return err
+	}
+
+	updatedDuel, err := p.duelsManager.UpdateDuel(duel)
+	if err != nil {
+		return fmt.Errorf("failed to persist duel: %w", err)
+	}
+
+	if p.connectionMgr == nil {
+		return fmt.Errorf("connection manager not set")
+	}
+	return p.connectionMgr.BroadcastStateToDuel(updatedDuel)
+}
+
+func (p *BattleshipActionProcessor) parseAction(actionData model.ActionData) (any, error) {
+	if len(actionData.Ships) > 0 {
+		ships := make([]battleship.ShipPlacement, len(actionData.Ships))
+		for i, s := range actionData.Ships {
+			ships[i] = battleship.ShipPlacement{
+				Name:       s.Name,
+				Row:        s.Row,
+				Column:     s.Column,
+				Horizontal: s.Horizontal,
+			}
+		}
+		return battleship.ActionPlaceFleet{Ships: ships}, nil
+	}
+	if actionData.Row != nil && actionData.Column != nil {
+		return battleship.ActionFire{Row: *actionData.Row, Column: *actionData.Column}, nil
+	}
+	return nil, fmt.Errorf("ships, or row and column required")
+}
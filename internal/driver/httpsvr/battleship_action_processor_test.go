@@ -0,0 +1,60 @@
+package httpsvr
+
+import (
+	"testing"
+
+	"github.com/daominah/turn_based_game/internal/core/battleship"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// TestBattleshipHiddenFleetOverWebSocket checks that each player's connection
+// only receives their own ship positions
+func TestBattleshipHiddenFleetOverWebSocket(t *testing.T) {
+	server := newGameServer(t, battleship.GameName)
+	fleet := make([]model.BattleshipShipPlacement, len(battleship.Fleet))
+	for i, spec := range battleship.Fleet {
+		fleet[i] = model.BattleshipShipPlacement{Name: spec.Name, Row: i, Column: 0, Horizontal: true}
+	}
+	var aliceView, bobView model.BattleshipGameState
+
+	alice := server.dial()
+	server.send(alice, ClientMessage{Type: MessageTypeCreateDuel, Game: battleship.GameName, Players: []string{"alice", "bob"}})
+	created := server.readState(alice, &aliceView)
+	duelID := created.Duel.ID
+
+	bob := server.dial()
+	server.send(bob, ClientMessage{Type: MessageTypeJoinDuel, DuelID: duelID, PlayerID: "bob"})
+	server.readState(bob, &bobView)
+
+	server.send(alice, ClientMessage{Type: MessageTypeAction, Game: battleship.GameName, DuelID: duelID, PlayerID: "alice",
+		Action: model.ActionData{Ships: fleet}})
+	server.readState(alice, &aliceView)
+	server.readState(bob, &bobView)
+	if len(aliceView.Players["alice"].Ships) != len(battleship.Fleet) {
+		t.Errorf("Alice should see her own fleet, got %+v", aliceView.Players["alice"].Ships)
+	}
+	if len(bobView.Players["alice"].Ships) != 0 || !bobView.Players["alice"].FleetPlaced {
+		t.Errorf("Bob should only know Alice's fleet is placed, got %+v", bobView.Players["alice"])
+	}
+
+	server.send(bob, ClientMessage{Type: MessageTypeAction, Game: battleship.GameName, DuelID: duelID, PlayerID: "bob",
+		Action: model.ActionData{Ships: fleet}})
+	server.readState(alice, &aliceView)
+	msg := server.readState(bob, &bobView)
+	if msg.Duel.State != string(turnbased.DuelStateRunning) || msg.Duel.TurnPlayer != "alice" {
+		t.Errorf("Expected RUNNING with alice to fire, got %s %s", msg.Duel.State, msg.Duel.TurnPlayer)
+	}
+
+	row, column := 0, 0
+	server.send(alice, ClientMessage{Type: MessageTypeAction, Game: battleship.GameName, DuelID: duelID, PlayerID: "alice",
+		Action: model.ActionData{Row: &row, Column: &column}})
+	server.readState(alice, &aliceView)
+	server.readState(bob, &bobView)
+	if aliceView.Players["bob"].Cells[0][0] != "HIT" || aliceView.Players["bob"].Cells[0][1] != "" {
+		t.Errorf("Alice should only see her hit on Bob's board, got %v", aliceView.Players["bob"].Cells[0])
+	}
+	if bobView.Players["bob"].Cells[0][0] != "HIT" || bobView.Players["bob"].Cells[0][1] != model.BattleshipCellShip {
+		t.Errorf("Bob should see the hit and his own ship, got %v", bobView.Players["bob"].Cells[0])
+	}
+}
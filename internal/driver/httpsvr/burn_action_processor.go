@@ -3,7 +3,11 @@ package httpsvr
 import (
 	"encoding/json"
 	"fmt"
+	"log"
+	"sync"
+	"time"
 
+	"github.com/daominah/turn_based_game/internal/core/ai"
 	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
 	"github.com/daominah/turn_based_game/internal/core/turnbased"
 	"github.com/daominah/turn_based_game/internal/model"
@@ -13,13 +17,24 @@ import (
 type BurnActionProcessor struct {
 	duelsManager  turnbased.DuelsManager
 	connectionMgr *ConnectionManager
+
+	mu   sync.Mutex
+	bots map[turnbased.DuelID]map[turnbased.PlayerID]card_game_burn.Bot // bot players of each duel
+
+	// newBurnDuel creates the duels, card_game_burn.NewBurnDuel unless a test seeds them
+	newBurnDuel func(players []turnbased.PlayerID) *card_game_burn.BurnDuel
 }
 
+// Ensure BurnActionProcessor supports duels against bots
+var _ BotDuelCreator = (*BurnActionProcessor)(nil)
+
 // NewBurnActionProcessor creates a new Burn action processor
 func NewBurnActionProcessor(duelsManager turnbased.DuelsManager) *BurnActionProcessor {
 	// Note: connectionMgr will be set by WebSocketHandler after creation
 	return &BurnActionProcessor{
 		duelsManager: duelsManager,
+		bots:         make(map[turnbased.DuelID]map[turnbased.PlayerID]card_game_burn.Bot),
+		newBurnDuel:  card_game_burn.NewBurnDuel,
 	}
 }
 
@@ -31,7 +46,7 @@ func (p *BurnActionProcessor) SetConnectionManager(cm *ConnectionManager) {
 // CreateDuel creates a new Burn duel
 func (p *BurnActionProcessor) CreateDuel(game string, players []turnbased.PlayerID) (*turnbased.Duel, error) {
 	// Create BurnDuel
-	burnDuel := card_game_burn.NewBurnDuel(players)
+	burnDuel := p.newBurnDuel(players)
 
 	// Wrap in generic Duel
 	duel := burnDuel.Duel
@@ -44,6 +59,81 @@ func (p *BurnActionProcessor) CreateDuel(game string, players []turnbased.Player
 	return createdDuel, nil
 }
 
+// CreateDuelWithBots creates a new Burn duel where the bots (player ID -> BotKind) are played
+// by the server. If a bot wins the coin toss, it plays its turn before this returns.
+func (p *BurnActionProcessor) CreateDuelWithBots(
+	game string, players []turnbased.PlayerID, botKinds map[turnbased.PlayerID]string) (*turnbased.Duel, error) {
+	bots := make(map[turnbased.PlayerID]card_game_burn.Bot)
+	for pid, kind := range botKinds {
+		inDuel := false
+		for _, player := range players {
+			inDuel = inDuel || player == pid
+		}
+		if !inDuel {
+			return nil, fmt.Errorf("bot %s is not a player of the duel", pid)
+		}
+		bot, err := newBurnBot(card_game_burn.BotKind(kind))
+		if err != nil {
+			return nil, err
+		}
+		bots[pid] = bot
+	}
+
+	duel, err := p.CreateDuel(game, players)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	p.bots[duel.ID] = bots
+	p.mu.Unlock()
+
+	p.playBots(duel)
+	return p.duelsManager.UpdateDuel(duel)
+}
+
+// MCTSBotTurnBudget bounds the search time of a tree search bot in one turn,
+// the bots play while their duel is locked (see processAction)
+const MCTSBotTurnBudget = time.Second
+
+// newBurnBot creates a built-in Burn bot, or a tree search bot for ai.BotKindMCTS
+// that searches ai.DefaultConfig per action and MCTSBotTurnBudget per turn
+func newBurnBot(kind card_game_burn.BotKind) (card_game_burn.Bot, error) {
+	if kind == ai.BotKindMCTS {
+		bot, err := ai.NewBurnBot(ai.DefaultConfig)
+		if err != nil {
+			return nil, err
+		}
+		bot.TurnBudget = MCTSBotTurnBudget
+		return bot, nil
+	}
+	return card_game_burn.NewBot(kind)
+}
+
+// playBots lets the bots of the duel act until a human has to act or the duel ends,
+// the bots of an ended duel are forgotten. A bot error is only logged: the actions before it
+// are still persisted and fanned out by the caller.
+func (p *BurnActionProcessor) playBots(duel *turnbased.Duel) {
+	p.mu.Lock()
+	bots := p.bots[duel.ID]
+	p.mu.Unlock()
+	if len(bots) == 0 {
+		return
+	}
+	burnDuel, ok := duel.Game.(*card_game_burn.BurnDuel)
+	if !ok {
+		log.Printf("Error playing the bots of duel %s: not a Burn duel", duel.ID)
+		return
+	}
+	if err := burnDuel.PlayBotTurns(bots); err != nil {
+		log.Printf("Error playing the bots of duel %s: %v", duel.ID, err)
+	}
+	if duel.State == turnbased.DuelStateEnd {
+		p.mu.Lock()
+		delete(p.bots, duel.ID)
+		p.mu.Unlock()
+	}
+}
+
 // ProcessAction implements the three-stage flow: Message In → Persist → Fanout
 func (p *BurnActionProcessor) ProcessAction(duelID turnbased.DuelID, playerID turnbased.PlayerID, actionData model.ActionData) error {
 	// Stage 1: Message In - action is already received, now parse it
@@ -68,6 +158,8 @@ func (p *BurnActionProcessor) ProcessAction(duelID turnbased.DuelID, playerID tu
 	if err := burnDuel.HandleActionWithPlayer(action, playerID); err != nil {
 		return err
 	}
+	// Bots answer right away, their actions are persisted and fanned out with the human's one
+	p.playBots(duel)
 
 	// Stage 2: Persist - update the duel in storage
 	updatedDuel, err := p.duelsManager.UpdateDuel(duel)
@@ -122,27 +214,5 @@ func (p *BurnActionProcessor) fanoutState(duel *turnbased.Duel) error {
 	if p.connectionMgr == nil {
 		return fmt.Errorf("connection manager not set")
 	}
-
-	// Get game-specific state
-	gameStateRaw := duel.Game.GetState()
-
-	// Convert to typed game state if it's a Burn duel
-	var gameState any
-	if burnDuel, ok := duel.Game.(*card_game_burn.BurnDuel); ok {
-		gameState = burnDuel.ToModelBurnGameState()
-	} else {
-		// Fallback to raw state for other games
-		gameState = gameStateRaw
-	}
-
-	// Create serializable duel
-	serializableDuel := model.FromDuel(duel)
-
-	msg := ServerMessage{
-		Type:      MessageTypeStateUpdate,
-		Duel:      &serializableDuel,
-		GameState: gameState,
-	}
-
-	return p.connectionMgr.BroadcastToDuel(duel.ID, msg)
+	return p.connectionMgr.BroadcastStateToDuel(duel)
 }
@@ -233,3 +233,92 @@ func TestBurnActionProcessor_ProcessAction_Invalid(t *testing.T) {
 		t.Error("ProcessAction should fail for non-turn player")
 	}
 }
+
+func TestBurnActionProcessor_Bots(t *testing.T) {
+	duelsManager := turnbased.NewInMemoryDuelsManager()
+	processor := NewBurnActionProcessor(duelsManager)
+	processor.SetConnectionManager(NewConnectionManager())
+	// the seed makes the bot win the coin toss without winning on its first turn
+	processor.newBurnDuel = func(players []turnbased.PlayerID) *card_game_burn.BurnDuel {
+		return card_game_burn.NewBurnDuelWithSeed(players, 6)
+	}
+
+	players := []turnbased.PlayerID{"human", "computer"}
+	if _, err := processor.CreateDuelWithBots(card_game_burn.GameName, players,
+		map[turnbased.PlayerID]string{"stranger": "GREEDY"}); err == nil {
+		t.Error("CreateDuelWithBots should fail for a bot not in the duel")
+	}
+	if _, err := processor.CreateDuelWithBots(card_game_burn.GameName, players,
+		map[turnbased.PlayerID]string{"computer": "SMART"}); err == nil {
+		t.Error("CreateDuelWithBots should fail for an unknown bot kind")
+	}
+
+	duel, err := processor.CreateDuelWithBots(card_game_burn.GameName, players,
+		map[turnbased.PlayerID]string{"computer": string(card_game_burn.BotKindGreedy)})
+	if err != nil {
+		t.Fatalf("CreateDuelWithBots failed: %v", err)
+	}
+	if duel.TurnPlayer != "human" || duel.Turn != 2 {
+		t.Fatalf("Expected the bot to have played its turn, got turn %d of %s", duel.Turn, duel.TurnPlayer)
+	}
+
+	endTurn := true
+	for i := 0; i < 3 && duel.State == turnbased.DuelStateRunning; i++ {
+		turn := duel.Turn
+		if err := processor.ProcessAction(duel.ID, "human", model.ActionData{EndTurn: &endTurn}); err != nil {
+			t.Fatalf("ProcessAction failed: %v", err)
+		}
+		if duel.State == turnbased.DuelStateRunning && (duel.TurnPlayer != "human" || duel.Turn != turn+2) {
+			t.Errorf("Expected the bot to answer with its turn, got turn %d player %s", duel.Turn, duel.TurnPlayer)
+		}
+	}
+
+	// the human only ends turns, the greedy bot wins
+	for i := 0; i < 100 && duel.State == turnbased.DuelStateRunning; i++ {
+		if err := processor.ProcessAction(duel.ID, "human", model.ActionData{EndTurn: &endTurn}); err != nil {
+			t.Fatalf("ProcessAction failed: %v", err)
+		}
+	}
+	if duel.State != turnbased.DuelStateEnd || duel.Winner != "computer" {
+		t.Fatalf("Expected the bot to win, got state %s winner %s", duel.State, duel.Winner)
+	}
+	if len(processor.bots) != 0 {
+		t.Errorf("Expected the bots of the ended duel to be forgotten, got %v", processor.bots)
+	}
+}
+
+// failingBot chooses an action the duel rejects
+type failingBot struct{}
+
+func (failingBot) ChooseAction(*card_game_burn.BurnDuel, turnbased.PlayerID) any {
+	return card_game_burn.ActionPlayCard{CardID: "not in hand", Option: card_game_burn.PlayCardOptionGain}
+}
+
+func TestBurnActionProcessor_BotError(t *testing.T) {
+	duelsManager := turnbased.NewInMemoryDuelsManager()
+	processor := NewBurnActionProcessor(duelsManager)
+	connectionMgr := NewConnectionManager()
+	processor.SetConnectionManager(connectionMgr)
+	// the seed makes the human win the coin toss
+	processor.newBurnDuel = func(players []turnbased.PlayerID) *card_game_burn.BurnDuel {
+		return card_game_burn.NewBurnDuelWithSeed(players, 3)
+	}
+	duel, err := processor.CreateDuel(card_game_burn.GameName, []turnbased.PlayerID{"human", "computer"})
+	if err != nil {
+		t.Fatalf("CreateDuel failed: %v", err)
+	}
+	processor.bots[duel.ID] = map[turnbased.PlayerID]card_game_burn.Bot{"computer": failingBot{}}
+	human := &recordingSubscriber{}
+	connectionMgr.AddConnection(human, "human", duel.ID)
+
+	endTurn := true
+	if err := processor.ProcessAction(duel.ID, "human", model.ActionData{EndTurn: &endTurn}); err != nil {
+		t.Fatalf("ProcessAction should accept the human action despite the bot error, got %v", err)
+	}
+	if duel.TurnPlayer != "computer" || len(duel.ActionLog) == 0 {
+		t.Errorf("Expected the human turn to be ended, got turn player %s", duel.TurnPlayer)
+	}
+	if len(human.written()) == 0 {
+		t.Errorf("Expected the human action to be fanned out")
+	}
+}
@@ -0,0 +1,69 @@
+package httpsvr
+
+import (
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/core/chess"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// ChessActionProcessor processes actions for chess
+type ChessActionProcessor struct {
+	duelsManager  turnbased.DuelsManager
+	connectionMgr *ConnectionManager
+}
+
+// NewChessActionProcessor creates a new chess action processor
+func NewChessActionProcessor(duelsManager turnbased.DuelsManager) *ChessActionProcessor {
+	// Note: connectionMgr will be set by WebSocketHandler after creation
+	return &ChessActionProcessor{
+		duelsManager: duelsManager,
+	}
+}
+
+// SetConnectionManager sets the connection manager (called by WebSocketHandler)
+func (p *ChessActionProcessor) SetConnectionManager(cm *ConnectionManager) {
+	p.connectionMgr = cm
+}
+
+// CreateDuel creates a new chess duel
+func (p *ChessActionProcessor) CreateDuel(game string, players []turnbased.PlayerID) (*turnbased.Duel, error) {
+	chessDuel, err := chess.NewChessDuel(players)
+	if err != nil {
+		return nil, err
+	}
+	duel := chessDuel.Duel
+	duel.Game = chessDuel
+	return p.duelsManager.CreateDuel(duel), nil
+}
+
+// ProcessAction implements the three-stage flow: Message In → Persist → Fanout
+func (p *ChessActionProcessor) ProcessAction(duelID turnbased.DuelID, playerID turnbased.PlayerID, actionData model.ActionData) error {
+	if actionData.Move == nil || *actionData.Move == "" {
+		return fmt.Errorf("failed to parse action: move required")
+	}
+	action := chess.ActionMove{Move: *actionData.Move}
+
+	duel := p.duelsManager.GetDuel(duelID)
+	if duel == nil {
+		return fmt.Errorf("duel not found: %s", duelID)
+	}
+	chessDuel, ok := duel.Game.(*chess.ChessDuel)
+	if !ok {
+		return fmt.Errorf("duel is not a chess duel")
+	}
+	if err := chessDuel.HandleActionWithPlayer(action, playerID); err != nil {
+		return err
+	}
+
+	updatedDuel, err := p.duelsManager.UpdateDuel(duel)
+	if err != nil {
+		return fmt.Errorf("failed to persist duel: %w", err)
+	}
+
+	if p.connectionMgr == nil {
+		return fmt.Errorf("connection manager not set")
+	}
+	return p.connectionMgr.BroadcastStateToDuel(updatedDuel)
+}
@@ -0,0 +1,54 @@
+package httpsvr
+
+import (
+	"testing"
+
+	"github.com/daominah/turn_based_game/internal/core/chess"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// TestChessOverWebSocket plays a whole chess duel (fool's mate) through the /ws protocol
+func TestChessOverWebSocket(t *testing.T) {
+	server := newGameServer(t, chess.GameName)
+	conn := server.dial() // the creator plays for both players (hot seat)
+
+	server.send(conn, ClientMessage{Type: MessageTypeCreateDuel, Game: chess.GameName, Players: []string{"white", "black"}})
+	var gameState model.ChessGameState
+	created := server.readState(conn, &gameState)
+	duelID := created.Duel.ID
+	play := func(player string, m string) {
+		server.send(conn, ClientMessage{
+			Type:     MessageTypeAction,
+			DuelID:   duelID,
+			PlayerID: player,
+			Game:     chess.GameName,
+			Action:   model.ActionData{Move: &m},
+		})
+	}
+
+	// an illegal move is rejected
+	play("white", "e2e5")
+	if msg := server.read(conn); msg.Type != MessageTypeError {
+		t.Fatalf("Expected an error for an illegal move, got %+v", msg)
+	}
+
+	var last ServerMessage
+	for i, m := range []string{"f3", "e7e5", "g4", "Qh4"} {
+		player := "white"
+		if i%2 == 1 {
+			player = "black"
+		}
+		play(player, m)
+		last = server.readState(conn, &gameState)
+	}
+
+	if last.Duel.State != string(turnbased.DuelStateEnd) || last.Duel.Winner != "black" ||
+		last.Duel.EndReason != chess.EndReasonCheckmate {
+		t.Errorf("Expected black to win by checkmate, got state %s winner %s reason %s",
+			last.Duel.State, last.Duel.Winner, last.Duel.EndReason)
+	}
+	if !gameState.InCheck || len(gameState.LegalMoves) != 0 || gameState.History[3] != "Qh4#" {
+		t.Errorf("Unexpected final state %+v", gameState)
+	}
+}
@@ -0,0 +1,69 @@
+package httpsvr
+
+import (
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/core/connect_four"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// ConnectFourActionProcessor processes actions for Connect Four
+type ConnectFourActionProcessor struct {
+	duelsManager  turnbased.DuelsManager
+	connectionMgr *ConnectionManager
+}
+
+// NewConnectFourActionProcessor creates a new Connect Four action processor
+func NewConnectFourActionProcessor(duelsManager turnbased.DuelsManager) *ConnectFourActionProcessor {
+	// Note: connectionMgr will be set by WebSocketHandler after creation
+	return &ConnectFourActionProcessor{
+		duelsManager: duelsManager,
+	}
+}
+
+// SetConnectionManager sets the connection manager (called by WebSocketHandler)
+func (p *ConnectFourActionProcessor) SetConnectionManager(cm *ConnectionManager) {
+	p.connectionMgr = cm
+}
+
+// CreateDuel creates a new Connect Four duel
+func (p *ConnectFourActionProcessor) CreateDuel(game string, players []turnbased.PlayerID) (*turnbased.Duel, error) {
+	c4Duel, err := connect_four.NewConnectFourDuel(players)
+	if err != nil {
+		return nil, err
+	}
+	duel := c4Duel.Duel
+	duel.Game = c4Duel
+	return p.duelsManager.CreateDuel(duel), nil
+}
+
+// ProcessAction implements the three-stage flow: Message In → Persist → Fanout
+func (p *ConnectFourActionProcessor) ProcessAction(duelID turnbased.DuelID, playerID turnbased.PlayerID, actionData model.ActionData) error {
+	if actionData.Column == nil {
+		return fmt.Errorf("failed to parse action: column required")
+	}
+	action := connect_four.ActionDropDisc{Column: *actionData.Column}
+
+	duel := p.duelsManager.GetDuel(duelID)
+	if duel == nil {
+		return fmt.Errorf("duel not found: %s", duelID)
+	}
+	c4Duel, ok := duel.Game.(*connect_four.ConnectFourDuel)
+	if !ok {
+		return fmt.Errorf("duel is not a Connect Four duel")
+	}
+	if err := c4Duel.HandleActionWithPlayer(action, playerID); err != nil {
+		return err
+	}
+
+	updatedDuel, err := p.duelsManager.UpdateDuel(duel)
+	if err != nil {
+		return fmt.Errorf("failed to persist duel: %w", err)
+	}
+
+	if p.connectionMgr == nil {
+		return fmt.Errorf("connection manager not set")
+	}
+	return p.connectionMgr.BroadcastStateToDuel(updatedDuel)
+}
@@ -0,0 +1,46 @@
+package httpsvr
+
+import (
+	"testing"
+
+	"github.com/daominah/turn_based_game/internal/core/connect_four"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// TestConnectFourOverWebSocket plays a whole Connect Four duel through the /ws protocol
+func TestConnectFourOverWebSocket(t *testing.T) {
+	server := newGameServer(t, connect_four.GameName)
+	conn := server.dial() // the creator plays for both players (hot seat)
+
+	server.send(conn, ClientMessage{Type: MessageTypeCreateDuel, Game: connect_four.GameName, Players: []string{"alice", "bob"}})
+	var gameState model.ConnectFourGameState
+	created := server.readState(conn, &gameState)
+	duelID := created.Duel.ID
+
+	// alice stacks column 0, bob stacks column 1, alice wins vertically
+	columns := []int{0, 1, 0, 1, 0, 1, 0}
+	var last ServerMessage
+	for i, column := range columns {
+		player := "alice"
+		if i%2 == 1 {
+			player = "bob"
+		}
+		column := column
+		server.send(conn, ClientMessage{
+			Type:     MessageTypeAction,
+			DuelID:   duelID,
+			PlayerID: player,
+			Game:     connect_four.GameName,
+			Action:   model.ActionData{Column: &column},
+		})
+		last = server.readState(conn, &gameState)
+	}
+
+	if last.Duel.State != string(turnbased.DuelStateEnd) || last.Duel.Winner != "alice" {
+		t.Errorf("Expected alice to win, got state %s winner %s", last.Duel.State, last.Duel.Winner)
+	}
+	if gameState.Board[3][0] != "alice" || len(gameState.WinningLine) != connect_four.WinLength {
+		t.Errorf("Unexpected final board %v, winning line %v", gameState.Board, gameState.WinningLine)
+	}
+}
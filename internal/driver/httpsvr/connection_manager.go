@@ -4,46 +4,124 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"slices"
 	"sync"
 
 	"github.com/coder/websocket"
 	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
 )
 
-// ConnectionManager manages WebSocket connections for duels and players
+// Subscriber is a connection receiving the messages of a duel: *websocket.Conn,
+// or for clients that cannot use WebSocket an SSE stream or a long-poll queue (see stream.go).
+// Write errors remove the subscriber.
+type Subscriber interface {
+	Write(ctx context.Context, typ websocket.MessageType, data []byte) error
+}
+
+// ConnectionManager manages the connections (subscribers) of duels and players
 type ConnectionManager struct {
-	// duelID -> []*websocket.Conn
-	duelConnections map[turnbased.DuelID][]*websocket.Conn
-	// playerID -> *websocket.Conn (one connection per player)
-	playerConnections map[turnbased.PlayerID]*websocket.Conn
+	// duelID -> []Subscriber
+	duelConnections map[turnbased.DuelID][]Subscriber
+	// playerID -> Subscriber (one connection per player)
+	playerConnections map[turnbased.PlayerID]Subscriber
 	// conn -> playerID (reverse mapping for cleanup)
-	connToPlayer map[*websocket.Conn]turnbased.PlayerID
+	connToPlayer map[Subscriber]turnbased.PlayerID
 	// conn -> duelID (track which duel a connection is watching)
-	connToDuel map[*websocket.Conn]turnbased.DuelID
-	mu         sync.RWMutex
+	connToDuel map[Subscriber]turnbased.DuelID
+	// conn -> the other players a hot seat connection plays for, see AddHotSeatConnection
+	hotSeat map[Subscriber][]turnbased.PlayerID
+	// connections added by AddSpectator, they have no player
+	spectators map[Subscriber]bool
+	// conn -> protocol options negotiated in hello, absent for the defaults
+	connOptions map[Subscriber]*connOptions
+	// seqs is the sequence number of the last message fanned out to each duel, see ServerMessage.Seq
+	seqs map[turnbased.DuelID]int
+	// replays is the last messages of each duel, for resuming clients
+	replays map[turnbased.DuelID]*replayBuffer
+	// presence is whether each player who ever connected to a duel is connected, see presence.go
+	presence map[turnbased.DuelID]map[turnbased.PlayerID]playerPresence
+	// ended is the duels whose end state was fanned out,
+	// their entries are deleted when their last connection leaves, see forgetDuelLocked
+	ended map[turnbased.DuelID]bool
+	// fanouts is the messages of each duel waiting to be written, see HoldFanout
+	fanouts map[turnbased.DuelID]*fanoutQueue
+	// onDuelChange is called after a presence change or a state broadcast of a duel, can be nil
+	onDuelChange func(turnbased.DuelID)
+	mu           sync.RWMutex
+}
+
+// connOptions is the protocol options of a connection, see HelloInfo
+type connOptions struct {
+	encoding string     // empty means EncodingJSON
+	delta    *deltaView // nil if the connection does not use the delta feature
+}
+
+// frame is an encoded message and its WebSocket frame type
+type frame struct {
+	typ  websocket.MessageType
+	data []byte
+}
+
+// deltaView is the last state sent to a connection, the base of its next state_delta
+type deltaView struct {
+	version   int // 0 if no state was sent
+	duel      []byte
+	logLength int
+	gameState []byte
 }
 
 // NewConnectionManager creates a new connection manager
 func NewConnectionManager() *ConnectionManager {
 	return &ConnectionManager{
-		duelConnections:   make(map[turnbased.DuelID][]*websocket.Conn),
-		playerConnections: make(map[turnbased.PlayerID]*websocket.Conn),
-		connToPlayer:      make(map[*websocket.Conn]turnbased.PlayerID),
-		connToDuel:        make(map[*websocket.Conn]turnbased.DuelID),
+		duelConnections:   make(map[turnbased.DuelID][]Subscriber),
+		playerConnections: make(map[turnbased.PlayerID]Subscriber),
+		connToPlayer:      make(map[Subscriber]turnbased.PlayerID),
+		connToDuel:        make(map[Subscriber]turnbased.DuelID),
+		hotSeat:           make(map[Subscriber][]turnbased.PlayerID),
+		spectators:        make(map[Subscriber]bool),
+		connOptions:       make(map[Subscriber]*connOptions),
+		seqs:              make(map[turnbased.DuelID]int),
+		replays:           make(map[turnbased.DuelID]*replayBuffer),
+		presence:          make(map[turnbased.DuelID]map[turnbased.PlayerID]playerPresence),
+		ended:             make(map[turnbased.DuelID]bool),
+		fanouts:           make(map[turnbased.DuelID]*fanoutQueue),
 	}
 }
 
-// AddConnection adds a WebSocket connection for a player in a duel
-func (cm *ConnectionManager) AddConnection(conn *websocket.Conn, playerID turnbased.PlayerID, duelID turnbased.DuelID) {
+// AddConnection adds a WebSocket connection for a player in a duel.
+// The other connections of the duel receive a presence event if the player was not connected,
+// the new connection gets the player's presence in its first state.
+func (cm *ConnectionManager) AddConnection(conn Subscriber, playerID turnbased.PlayerID, duelID turnbased.DuelID) {
 	cm.mu.Lock()
-	defer cm.mu.Unlock()
+	var notify []func()
+	defer func() {
+		cm.mu.Unlock()
+		for _, f := range notify {
+			f()
+		}
+	}()
+
+	// The connection leaves the duel it was watching as another player or as a spectator
+	if _, attached := cm.connToDuel[conn]; attached && cm.connToPlayer[conn] != playerID {
+		notify = append(notify, cm.detachLocked(conn))
+	}
 
 	// Remove old connection if player already has one
 	if oldConn, exists := cm.playerConnections[playerID]; exists {
-		cm.removeConnectionLocked(oldConn)
+		oldDuelID := cm.connToDuel[oldConn]
+		cm.releasePlayerLocked(oldConn, playerID)
+		if oldDuelID != duelID {
+			notify = append(notify, cm.setPresenceLocked(oldDuelID, playerID, false))
+			cm.forgetDuelLocked(oldDuelID)
+		}
 	}
+	notify = append(notify, cm.setPresenceLocked(duelID, playerID, true))
 
-	// Add new connection
+	// Add new connection, its first state is a full one
+	if options, ok := cm.connOptions[conn]; ok && options.delta != nil {
+		*options.delta = deltaView{}
+	}
 	cm.playerConnections[playerID] = conn
 	cm.connToPlayer[conn] = playerID
 	cm.connToDuel[conn] = duelID
@@ -55,21 +133,275 @@ func (cm *ConnectionManager) AddConnection(conn *websocket.Conn, playerID turnba
 		playerID, duelID, len(cm.duelConnections[duelID]))
 }
 
-// RemoveConnection removes a WebSocket connection
-func (cm *ConnectionManager) RemoveConnection(conn *websocket.Conn) {
+// AddHotSeatConnection adds a WebSocket connection playing for all the players of a duel
+// on the same screen (hot seat). It receives the state as seen by the first player,
+// the other players can still join with their own connection.
+func (cm *ConnectionManager) AddHotSeatConnection(conn Subscriber, playerIDs []turnbased.PlayerID, duelID turnbased.DuelID) {
+	// the other players first, so that like in AddConnection the connection does not receive its own presence events
 	cm.mu.Lock()
-	defer cm.mu.Unlock()
+	var notify []func()
+	if _, attached := cm.connToDuel[conn]; attached {
+		notify = append(notify, cm.detachLocked(conn))
+	}
+	for _, playerID := range playerIDs[1:] {
+		if oldConn, exists := cm.playerConnections[playerID]; exists {
+			oldDuelID := cm.connToDuel[oldConn]
+			cm.releasePlayerLocked(oldConn, playerID)
+			if oldDuelID != duelID {
+				notify = append(notify, cm.setPresenceLocked(oldDuelID, playerID, false))
+				cm.forgetDuelLocked(oldDuelID)
+			}
+		}
+		notify = append(notify, cm.setPresenceLocked(duelID, playerID, true))
+		cm.playerConnections[playerID] = conn
+		cm.hotSeat[conn] = append(cm.hotSeat[conn], playerID)
+	}
+	cm.mu.Unlock()
+	for _, f := range notify {
+		f()
+	}
+
+	cm.AddConnection(conn, playerIDs[0], duelID)
+}
+
+// releasePlayerLocked makes the player's old connection stop playing for them:
+// it is removed, or a hot seat connection keeps playing for its other players
+func (cm *ConnectionManager) releasePlayerLocked(oldConn Subscriber, playerID turnbased.PlayerID) {
+	if cm.connToPlayer[oldConn] == playerID {
+		cm.removeConnectionLocked(oldConn)
+		return
+	}
+	delete(cm.playerConnections, playerID)
+	others := cm.hotSeat[oldConn]
+	for i, pid := range others {
+		if pid == playerID {
+			cm.hotSeat[oldConn] = append(others[:i], others[i+1:]...)
+			break
+		}
+	}
+	if len(cm.hotSeat[oldConn]) == 0 {
+		delete(cm.hotSeat, oldConn)
+	}
+}
+
+// AddSpectator adds a read-only connection to a duel, it receives the public state of the duel
+// (as seen by the empty viewer, see turnbased.PlayerStateViewer).
+// The other connections of the duel receive the new number of spectators,
+// the new connection gets it in its first state.
+func (cm *ConnectionManager) AddSpectator(conn Subscriber, duelID turnbased.DuelID) {
+	cm.mu.Lock()
+	notify := cm.detachLocked(conn)
+	if options, ok := cm.connOptions[conn]; ok && options.delta != nil {
+		*options.delta = deltaView{}
+	}
+	cm.spectators[conn] = true
+	cm.connToDuel[conn] = duelID
+	notifySpectators := cm.spectatorsChangedLocked(duelID)
+	cm.duelConnections[duelID] = append(cm.duelConnections[duelID], conn)
+	cm.mu.Unlock()
+	notify()
+	notifySpectators()
+	log.Printf("Spectator added: duel=%s", duelID)
+}
+
+// IsSpectator returns true if the connection was added by AddSpectator
+func (cm *ConnectionManager) IsSpectator(conn Subscriber) bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.spectators[conn]
+}
+
+// RemoveConnection removes a WebSocket connection, the other connections of the duel
+// receive a presence event for each player of the connection, or the new number of spectators
+func (cm *ConnectionManager) RemoveConnection(conn Subscriber) {
+	cm.mu.Lock()
+	notify := cm.detachLocked(conn)
+	delete(cm.connOptions, conn)
+	cm.mu.Unlock()
+	notify()
+}
+
+// detachLocked removes the connection from its duel, the returned function tells the other connections
+// of the duel (the presence of the connection's players or the number of spectators), to be called after unlocking
+func (cm *ConnectionManager) detachLocked(conn Subscriber) func() {
+	duelID, hasDuel := cm.connToDuel[conn]
+	spectator := cm.spectators[conn]
+	players := slices.Clone(cm.hotSeat[conn])
+	if playerID, hasPlayer := cm.connToPlayer[conn]; hasPlayer {
+		players = append([]turnbased.PlayerID{playerID}, players...)
+	}
 	cm.removeConnectionLocked(conn)
+	if !hasDuel {
+		return func() {}
+	}
+	var notify []func()
+	for _, playerID := range players {
+		notify = append(notify, cm.setPresenceLocked(duelID, playerID, false))
+	}
+	if spectator {
+		notify = append(notify, cm.spectatorsChangedLocked(duelID))
+	}
+	cm.forgetDuelLocked(duelID)
+	return func() {
+		for _, f := range notify {
+			f()
+		}
+	}
+}
+
+// OnDuelChange sets the function called after the presence of a player changed in a duel
+// or the state of a duel was broadcast, e.g. to watch the disconnected players (see disconnectWatchdog).
+// It is called without holding any lock of the connection manager.
+func (cm *ConnectionManager) OnDuelChange(f func(duelID turnbased.DuelID)) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.onDuelChange = f
+}
+
+// EnableDelta makes the connection receive state_delta messages instead of full state updates,
+// except for its first state in a duel and on resync
+func (cm *ConnectionManager) EnableDelta(conn Subscriber) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if options := cm.optionsLocked(conn); options.delta == nil {
+		options.delta = &deltaView{}
+	}
+}
+
+// SetEncoding sets the encoding of the messages sent to the connection, see MarshalMessage
+func (cm *ConnectionManager) SetEncoding(conn Subscriber, encoding string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.optionsLocked(conn).encoding = encoding
+}
+
+func (cm *ConnectionManager) optionsLocked(conn Subscriber) *connOptions {
+	options, ok := cm.connOptions[conn]
+	if !ok {
+		options = &connOptions{}
+		cm.connOptions[conn] = options
+	}
+	return options
+}
+
+// encodingLocked returns the encoding of the connection, empty for the default
+func (cm *ConnectionManager) encodingLocked(conn Subscriber) string {
+	if options, ok := cm.connOptions[conn]; ok {
+		return options.encoding
+	}
+	return ""
+}
+
+// Send writes a message to the connection in its encoding
+func (cm *ConnectionManager) Send(conn Subscriber, message ServerMessage) error {
+	cm.mu.RLock()
+	encoding := cm.encodingLocked(conn)
+	cm.mu.RUnlock()
+	typ, data, err := MarshalMessage(encoding, message)
+	if err != nil {
+		return err
+	}
+	return writeWithTimeout(conn, typ, data)
+}
+
+// ConnectionPlayer returns the player and the duel the connection was added for
+func (cm *ConnectionManager) ConnectionPlayer(conn Subscriber) (turnbased.PlayerID, turnbased.DuelID, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	duelID, ok := cm.connToDuel[conn]
+	return cm.connToPlayer[conn], duelID, ok
+}
+
+// PlaysFor returns true if the connection was added for the player in the duel,
+// or plays for them on a hot seat (see AddHotSeatConnection)
+func (cm *ConnectionManager) PlaysFor(conn Subscriber, playerID turnbased.PlayerID, duelID turnbased.DuelID) bool {
+	connPlayer, connDuel, ok := cm.ConnectionPlayer(conn)
+	if !ok || connDuel != duelID || playerID == "" {
+		return false
+	}
+	if connPlayer == playerID {
+		return true
+	}
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return slices.Contains(cm.hotSeat[conn], playerID)
+}
+
+// SendStateTo sends the full state_update of the duel as seen by the viewer to the connection,
+// it is the base of the next state_delta if the connection has the delta feature
+func (cm *ConnectionManager) SendStateTo(conn Subscriber, duel *turnbased.Duel, viewer turnbased.PlayerID) error {
+	send, err := cm.PrepareState(conn, duel, viewer)
+	if err != nil {
+		return err
+	}
+	return send()
+}
+
+// PrepareState is SendStateTo in two steps: it builds the state right away, to be called while the duel
+// is locked (see turnbased.DuelsManager.LockDuel), and send writes it, to be called after unlocking
+func (cm *ConnectionManager) PrepareState(conn Subscriber, duel *turnbased.Duel, viewer turnbased.PlayerID) (send func() error, err error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	msg := cm.stateUpdateLocked(duel, viewer)
+	if options, ok := cm.connOptions[conn]; ok && options.delta != nil {
+		if err := options.delta.update(msg); err != nil {
+			return nil, err
+		}
+	}
+	return func() error { return cm.Send(conn, msg) }, nil
+}
+
+// StateUpdateMessage returns the state_update of the duel as seen by the viewer,
+// with the players' presence and the current seq of the duel
+func (cm *ConnectionManager) StateUpdateMessage(duel *turnbased.Duel, viewer turnbased.PlayerID) ServerMessage {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.stateUpdateLocked(duel, viewer)
+}
+
+func (cm *ConnectionManager) stateUpdateLocked(duel *turnbased.Duel, viewer turnbased.PlayerID) ServerMessage {
+	msg := NewStateUpdateMessage(duel, viewer)
+	msg.Duel.Presence = cm.presenceLocked(duel.ID)
+	msg.Duel.Spectators = cm.spectatorCountLocked(duel.ID)
+	msg.Seq = cm.seqLocked(duel.ID)
+	msg.Version = msg.Seq
+	return msg
+}
+
+// seqLocked returns the sequence number of the last message of the duel,
+// it starts at 1 for the duel's initial state
+func (cm *ConnectionManager) seqLocked(duelID turnbased.DuelID) int {
+	return max(cm.seqs[duelID], 1)
 }
 
-func (cm *ConnectionManager) removeConnectionLocked(conn *websocket.Conn) {
+// nextSeqLocked increments the sequence number of the duel for a new message
+func (cm *ConnectionManager) nextSeqLocked(duelID turnbased.DuelID) int {
+	cm.seqs[duelID] = cm.seqLocked(duelID) + 1
+	return cm.seqs[duelID]
+}
+
+func (cm *ConnectionManager) replayLocked(duelID turnbased.DuelID) *replayBuffer {
+	replay, ok := cm.replays[duelID]
+	if !ok {
+		replay = &replayBuffer{}
+		cm.replays[duelID] = replay
+	}
+	return replay
+}
+
+func (cm *ConnectionManager) removeConnectionLocked(conn Subscriber) {
 	playerID, hasPlayer := cm.connToPlayer[conn]
 	duelID, hasDuel := cm.connToDuel[conn]
+	delete(cm.spectators, conn)
 
 	if hasPlayer {
 		delete(cm.playerConnections, playerID)
 		delete(cm.connToPlayer, conn)
 	}
+	for _, pid := range cm.hotSeat[conn] {
+		delete(cm.playerConnections, pid)
+	}
+	delete(cm.hotSeat, conn)
 
 	if hasDuel {
 		// Remove from duel connections slice
@@ -92,31 +424,201 @@ func (cm *ConnectionManager) removeConnectionLocked(conn *websocket.Conn) {
 	}
 }
 
-// BroadcastToDuel sends a message to all connections watching a duel
+// forgetDuelLocked deletes the entries of an ended duel that no connection watches anymore,
+// a client that resumes it later gets the full state
+func (cm *ConnectionManager) forgetDuelLocked(duelID turnbased.DuelID) {
+	if !cm.ended[duelID] || len(cm.duelConnections[duelID]) > 0 {
+		return
+	}
+	delete(cm.seqs, duelID)
+	delete(cm.replays, duelID)
+	delete(cm.presence, duelID)
+	delete(cm.ended, duelID)
+}
+
+// BroadcastToDuel sends a message to all connections watching a duel,
+// it sets the message Seq and keeps the message for resuming clients
 func (cm *ConnectionManager) BroadcastToDuel(duelID turnbased.DuelID, message ServerMessage) error {
-	cm.mu.RLock()
-	conns := make([]*websocket.Conn, len(cm.duelConnections[duelID]))
-	copy(conns, cm.duelConnections[duelID])
-	cm.mu.RUnlock()
+	cm.mu.Lock()
+	write, err := cm.broadcastLocked(duelID, message)
+	cm.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	write()
+	return nil
+}
 
+// broadcastLocked is BroadcastToDuel for the connections of the duel at the time of the call,
+// the returned function writes the message (see queueFanoutLocked), to be called after unlocking
+func (cm *ConnectionManager) broadcastLocked(duelID turnbased.DuelID, message ServerMessage) (func(), error) {
+	conns := slices.Clone(cm.duelConnections[duelID])
+	if len(conns) == 0 && cm.seqs[duelID] == 0 {
+		// no client got a seq of the duel (or it was forgotten), none can resume it
+		return func() {}, nil
+	}
+	message.Seq = cm.nextSeqLocked(duelID)
 	data, err := json.Marshal(message)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	cm.replayLocked(duelID).add(replayEntry{seq: message.Seq, message: data})
+
+	// marshal once per encoding
+	frames := make(map[string]frame)
+	framesByConn := make(map[Subscriber]frame, len(conns))
+	for _, conn := range conns {
+		encoding := cm.encodingLocked(conn)
+		f, done := frames[encoding]
+		if !done {
+			typ, data, err := MarshalMessage(encoding, message)
+			if err != nil {
+				return nil, err
+			}
+			f = frame{typ: typ, data: data}
+			frames[encoding] = f
+		}
+		framesByConn[conn] = f
 	}
+	return cm.queueFanoutLocked(duelID, func() {
+		cm.writeAll(conns, func(conn Subscriber) frame { return framesByConn[conn] })
+	}), nil
+}
 
-	var wg sync.WaitGroup
+// BroadcastStateToDuel sends a state_update to all connections watching a duel,
+// each connection receives the game state as seen by its player,
+// so games with hidden information never leak secrets to the opponent.
+// The state version is the new Seq of the duel, connections with the delta feature
+// receive a state_delta from the last state sent to them.
+// The state of each player and the public state are kept for resuming clients.
+// While the duel is held (see HoldFanout), the writes wait for its release.
+func (cm *ConnectionManager) BroadcastStateToDuel(duel *turnbased.Duel) error {
+	cm.mu.Lock()
+	conns := make([]Subscriber, len(cm.duelConnections[duel.ID]))
+	copy(conns, cm.duelConnections[duel.ID])
+	cm.nextSeqLocked(duel.ID)
+	fullByViewer := make(map[turnbased.PlayerID]ServerMessage)
+	fullFor := func(viewer turnbased.PlayerID) ServerMessage {
+		full, ok := fullByViewer[viewer]
+		if !ok {
+			full = cm.stateUpdateLocked(duel, viewer)
+			fullByViewer[viewer] = full
+		}
+		return full
+	}
+	entry := replayEntry{seq: cm.seqLocked(duel.ID), states: make(map[turnbased.PlayerID]json.RawMessage)}
+	for _, viewer := range append(slices.Clone(duel.Players), "") {
+		data, err := json.Marshal(fullFor(viewer))
+		if err != nil {
+			cm.mu.Unlock()
+			return err
+		}
+		entry.states[viewer] = data
+	}
+	cm.replayLocked(duel.ID).add(entry)
+	if duel.State == turnbased.DuelStateEnd {
+		cm.ended[duel.ID] = true
+		cm.forgetDuelLocked(duel.ID)
+	}
+
+	// marshal once per viewer and encoding (and per connection for deltas), before writing concurrently
+	type viewerEncoding struct {
+		viewer   turnbased.PlayerID
+		encoding string
+	}
+	framesByViewer := make(map[viewerEncoding]frame)
+	framesByConn := make(map[Subscriber]frame, len(conns))
 	for _, conn := range conns {
-		wg.Add(1)
-		go func(c *websocket.Conn) {
-			defer wg.Done()
-			if err := c.Write(context.Background(), websocket.MessageText, data); err != nil {
-				log.Printf("Error broadcasting to connection: %v", err)
-				cm.RemoveConnection(c)
+		viewer := cm.connToPlayer[conn]
+		full := fullFor(viewer)
+		options := cm.connOptions[conn]
+		if options != nil && options.delta != nil {
+			msg, err := options.delta.delta(full)
+			if err != nil {
+				cm.mu.Unlock()
+				return err
+			}
+			typ, data, err := MarshalMessage(options.encoding, msg)
+			if err != nil {
+				cm.mu.Unlock()
+				return err
+			}
+			framesByConn[conn] = frame{typ: typ, data: data}
+			continue
+		}
+		key := viewerEncoding{viewer: viewer, encoding: cm.encodingLocked(conn)}
+		if _, done := framesByViewer[key]; !done {
+			typ, data, err := MarshalMessage(key.encoding, full)
+			if err != nil {
+				cm.mu.Unlock()
+				return err
 			}
-		}(conn)
+			framesByViewer[key] = frame{typ: typ, data: data}
+		}
+		framesByConn[conn] = framesByViewer[key]
 	}
-	wg.Wait()
+	flush := cm.queueFanoutLocked(duel.ID, func() {
+		cm.writeAll(conns, func(conn Subscriber) frame { return framesByConn[conn] })
+	})
+	onDuelChange := cm.onDuelChange
+	cm.mu.Unlock()
 
+	flush()
+	if onDuelChange != nil {
+		onDuelChange(duel.ID)
+	}
+	return nil
+}
+
+// delta returns the state_delta from the view to the full state_update, then updates the view.
+// It returns the full state if the view has no state yet.
+func (v *deltaView) delta(full ServerMessage) (ServerMessage, error) {
+	base := *v
+	if err := v.update(full); err != nil {
+		return ServerMessage{}, err
+	}
+	if base.version == 0 || base.logLength > v.logLength {
+		return full, nil
+	}
+	duelPatch, err := model.DiffMergePatch(base.duel, v.duel)
+	if err != nil {
+		return ServerMessage{}, err
+	}
+	gameStatePatch, err := model.DiffMergePatch(base.gameState, v.gameState)
+	if err != nil {
+		return ServerMessage{}, err
+	}
+	return ServerMessage{
+		Type:        MessageTypeStateDelta,
+		Seq:         full.Seq,
+		Version:     full.Version,
+		BaseVersion: base.version,
+		Delta: &StateDelta{
+			DuelPatch:      duelPatch,
+			NewLogEntries:  full.Duel.ActionLog[base.logLength:],
+			GameStatePatch: gameStatePatch,
+		},
+	}, nil
+}
+
+// update sets the view to the full state_update
+func (v *deltaView) update(full ServerMessage) error {
+	duel := *full.Duel
+	duel.ActionLog = nil
+	duelData, err := json.Marshal(duel)
+	if err != nil {
+		return err
+	}
+	gameState, err := json.Marshal(full.GameState)
+	if err != nil {
+		return err
+	}
+	*v = deltaView{
+		version:   full.Version,
+		duel:      duelData,
+		logLength: len(full.Duel.ActionLog),
+		gameState: gameState,
+	}
 	return nil
 }
 
@@ -130,10 +632,5 @@ func (cm *ConnectionManager) SendToPlayer(playerID turnbased.PlayerID, message S
 		return nil // Player not connected, silently ignore
 	}
 
-	data, err := json.Marshal(message)
-	if err != nil {
-		return err
-	}
-
-	return conn.Write(context.Background(), websocket.MessageText, data)
+	return cm.Send(conn, message)
 }
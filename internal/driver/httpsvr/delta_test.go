@@ -0,0 +1,125 @@
+package httpsvr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+func TestStateDelta(t *testing.T) {
+	handler := NewWebSocketHandler(
+		map[string]turnbased.DuelsManager{card_game_burn.GameName: turnbased.NewInMemoryDuelsManager()},
+		NewConnectionManager(),
+	)
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	dial := func(features ...string) *websocket.Conn {
+		conn, _, err := websocket.Dial(ctx, "ws"+server.URL[4:], nil)
+		if err != nil {
+			t.Fatalf("error Dial: %v", err)
+		}
+		t.Cleanup(func() { conn.Close(websocket.StatusNormalClosure, "") })
+		send(t, ctx, conn, ClientMessage{Type: MessageTypeHello, ProtocolVersion: ProtocolVersion, Features: features})
+		if welcome := read(t, ctx, conn); len(welcome.Hello.Features) != len(features) {
+			t.Fatalf("unexpected welcome: %+v", welcome.Hello)
+		}
+		return conn
+	}
+	// alice uses deltas, bob receives full states to compare with
+	alice, bob := dial(FeatureDelta), dial()
+
+	send(t, ctx, alice, ClientMessage{Type: MessageTypeCreateDuel, Game: card_game_burn.GameName, Players: []string{"alice", "bob"}})
+	created := read(t, ctx, alice)
+	if created.Type != MessageTypeStateUpdate || created.Version == 0 {
+		t.Fatalf("expected a full state with a version, got %+v", created)
+	}
+	send(t, ctx, bob, ClientMessage{Type: MessageTypeJoinDuel, DuelID: created.Duel.ID, PlayerID: "bob"})
+	read(t, ctx, bob)
+
+	// alice's state, rebuilt from deltas like a client does
+	duelJSON, gameState := splitState(t, created)
+	log := created.Duel.ActionLog
+	version := created.Version
+	var aliceFull ServerMessage
+	turnPlayer := created.Duel.TurnPlayer // the first player is random
+	for turn := 0; turn < 4; turn++ {
+		action := ClientMessage{Type: MessageTypeAction, Game: card_game_burn.GameName,
+			DuelID: created.Duel.ID, PlayerID: turnPlayer, Action: endTurnAction()}
+		if turnPlayer == "alice" {
+			send(t, ctx, alice, action)
+		} else {
+			send(t, ctx, bob, action)
+		}
+		msg := read(t, ctx, alice)
+		turnPlayer = read(t, ctx, bob).Duel.TurnPlayer
+		if msg.Type != MessageTypeStateDelta || msg.BaseVersion != version || msg.Version <= version {
+			t.Fatalf("turn %d: expected a delta from version %d, got %+v", turn, version, msg)
+		}
+		var err error
+		if duelJSON, err = model.ApplyMergePatch(duelJSON, msg.Delta.DuelPatch); err != nil {
+			t.Fatalf("error ApplyMergePatch: %v", err)
+		}
+		if gameState, err = model.ApplyMergePatch(gameState, msg.Delta.GameStatePatch); err != nil {
+			t.Fatalf("error ApplyMergePatch: %v", err)
+		}
+		log = append(log, msg.Delta.NewLogEntries...)
+		version = msg.Version
+	}
+
+	// resync answers the full state, it must equal the rebuilt one
+	send(t, ctx, alice, ClientMessage{Type: MessageTypeResync})
+	aliceFull = read(t, ctx, alice)
+	if aliceFull.Type != MessageTypeStateUpdate || aliceFull.Version != version {
+		t.Fatalf("expected the full state of version %d, got %+v", version, aliceFull)
+	}
+	wantDuel, wantState := splitState(t, aliceFull)
+	if !jsonEqual(t, duelJSON, wantDuel) || !jsonEqual(t, gameState, wantState) {
+		t.Errorf("rebuilt state differs:\n%s\n%s\nwant:\n%s\n%s", duelJSON, gameState, wantDuel, wantState)
+	}
+	if !reflect.DeepEqual(log, aliceFull.Duel.ActionLog) {
+		t.Errorf("rebuilt log differs: %+v, want %+v", log, aliceFull.Duel.ActionLog)
+	}
+}
+
+func endTurnAction() model.ActionData {
+	endTurn := true
+	return model.ActionData{EndTurn: &endTurn}
+}
+
+// splitState returns the JSON of the duel without its log and of the game state
+func splitState(t *testing.T, msg ServerMessage) ([]byte, []byte) {
+	duel := *msg.Duel
+	duel.ActionLog = nil
+	duelJSON, err := json.Marshal(duel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gameState, err := json.Marshal(msg.GameState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return duelJSON, gameState
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	var va, vb any
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatal(err)
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,117 @@
+package httpsvr
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// disconnectWatchdog applies the disconnect rule of the duels (see turnbased.Settings):
+// when a player the duel waits for stays disconnected longer than the grace period,
+// their turn is passed or they abandon the duel.
+// It only acts while another player of the duel is connected, nobody waits for a duel
+// that every player left, and players who never connected (e.g. bots) are never acted for.
+type disconnectWatchdog struct {
+	duelsManagers map[string]turnbased.DuelsManager
+	connectionMgr *ConnectionManager
+
+	mu     sync.Mutex
+	timers map[turnbased.DuelID]*time.Timer // the next check of each duel
+}
+
+// newDisconnectWatchdog creates a watchdog checking a duel each time its presence or state changes
+func newDisconnectWatchdog(duelsManagers map[string]turnbased.DuelsManager, connectionMgr *ConnectionManager) *disconnectWatchdog {
+	w := &disconnectWatchdog{
+		duelsManagers: duelsManagers,
+		connectionMgr: connectionMgr,
+		timers:        make(map[turnbased.DuelID]*time.Timer),
+	}
+	// the change can happen while the duel is locked, e.g. the fanout of an action
+	connectionMgr.OnDuelChange(func(duelID turnbased.DuelID) { go w.check(duelID) })
+	return w
+}
+
+// check applies the disconnect rule to the duel if a grace period is over,
+// otherwise it schedules the next check at the end of the earliest grace period
+func (w *disconnectWatchdog) check(duelID turnbased.DuelID) {
+	duel, game := findDuel(w.duelsManagers, duelID)
+	if duel == nil {
+		return
+	}
+	manager := w.duelsManagers[game]
+	release := w.connectionMgr.HoldFanout(duelID)
+	defer release()
+	unlock := manager.LockDuel(duelID)
+	defer unlock()
+
+	var next time.Duration
+	if duel.Settings.DisconnectRule != turnbased.DisconnectRuleNone && w.anyConnected(duel) {
+		for _, playerID := range duel.WaitingFor() {
+			presence, known := w.connectionMgr.playerPresence(duelID, playerID)
+			if !known || presence.connected {
+				continue
+			}
+			left := time.Until(presence.since.Add(duel.Settings.DisconnectGracePeriod))
+			if left > 0 {
+				if next == 0 || left < next {
+					next = left
+				}
+				continue
+			}
+			// the fanout of the change triggers the next check
+			w.schedule(duelID, 0)
+			w.act(manager, duel, playerID)
+			return
+		}
+	}
+	w.schedule(duelID, next)
+}
+
+// anyConnected returns true if a player of the duel is connected to it
+func (w *disconnectWatchdog) anyConnected(duel *turnbased.Duel) bool {
+	for _, playerID := range duel.Players {
+		if presence, known := w.connectionMgr.playerPresence(duel.ID, playerID); known && presence.connected {
+			return true
+		}
+	}
+	return false
+}
+
+// schedule sets the next check of the duel after the delay, 0 cancels it
+func (w *disconnectWatchdog) schedule(duelID turnbased.DuelID, delay time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if timer, ok := w.timers[duelID]; ok {
+		timer.Stop()
+		delete(w.timers, duelID)
+	}
+	if delay > 0 {
+		w.timers[duelID] = time.AfterFunc(delay, func() { w.check(duelID) })
+	}
+}
+
+// act passes the turn of the disconnected player or makes them abandon the duel,
+// then persists and fans out the duel, the caller holds the duel lock
+func (w *disconnectWatchdog) act(manager turnbased.DuelsManager, duel *turnbased.Duel, playerID turnbased.PlayerID) {
+	passer, canPass := duel.Game.(turnbased.TurnPasser)
+	if duel.Settings.DisconnectRule == turnbased.DisconnectRulePass && canPass && duel.TurnPlayer == playerID {
+		log.Printf("Disconnected player %s: passing their turn, duel=%s", playerID, duel.ID)
+		if err := passer.PassTurn(playerID); err != nil {
+			log.Printf("Error passing the turn of %s, they abandon instead: %v", playerID, err)
+			duel.Abandon(playerID)
+		}
+	} else {
+		log.Printf("Disconnected player %s: abandoning, duel=%s", playerID, duel.ID)
+		duel.Abandon(playerID)
+	}
+	updated, err := manager.UpdateDuel(duel)
+	if err != nil {
+		log.Printf("Error persisting duel %s: %v", duel.ID, err)
+		return
+	}
+	if err := w.connectionMgr.BroadcastStateToDuel(updated); err != nil {
+		log.Printf("Error broadcasting duel %s: %v", duel.ID, err)
+	}
+}
@@ -0,0 +1,141 @@
+package httpsvr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
+	"github.com/daominah/turn_based_game/internal/core/connect_four"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+func TestDisconnectRule(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		game     string
+		rule     turnbased.DisconnectRule
+		wantPass bool // else the player who left abandons
+	}{
+		{name: "forfeit", game: connect_four.GameName, rule: turnbased.DisconnectRuleForfeit},
+		{name: "pass", game: card_game_burn.GameName, rule: turnbased.DisconnectRulePass, wantPass: true},
+		{name: "pass in a game without passing", game: connect_four.GameName, rule: turnbased.DisconnectRulePass},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			duelsManagers := map[string]turnbased.DuelsManager{c.game: turnbased.NewInMemoryDuelsManager()}
+			handler := NewWebSocketHandler(duelsManagers, NewConnectionManager())
+			server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			dial := func() *websocket.Conn {
+				conn, _, err := websocket.Dial(ctx, "ws"+server.URL[4:], nil)
+				if err != nil {
+					t.Fatalf("error Dial: %v", err)
+				}
+				t.Cleanup(func() { conn.CloseNow() })
+				return conn
+			}
+
+			conns := map[string]*websocket.Conn{"alice": dial(), "bob": dial()}
+			send(t, ctx, conns["alice"], ClientMessage{Type: MessageTypeCreateDuel, Game: c.game,
+				Players: []string{"alice", "bob"}, Settings: &model.DuelSettings{DisconnectRule: string(c.rule)}})
+			created := read(t, ctx, conns["alice"])
+			if created.Type != MessageTypeStateUpdate {
+				t.Fatalf("expected state_update, got %+v", created)
+			}
+			if got := created.Duel.Settings; got.DisconnectRule != string(c.rule) ||
+				got.DisconnectGracePeriodSeconds != int(turnbased.DefaultDisconnectGracePeriod/time.Second) {
+				t.Errorf("created duel settings: %+v", got)
+			}
+			duelID := turnbased.DuelID(created.Duel.ID)
+			manager := duelsManagers[c.game]
+			unlock := manager.LockDuel(duelID)
+			manager.GetDuel(duelID).Settings.DisconnectGracePeriod = 50 * time.Millisecond
+			unlock()
+			send(t, ctx, conns["bob"], ClientMessage{Type: MessageTypeJoinDuel, DuelID: created.Duel.ID, PlayerID: "bob"})
+			read(t, ctx, conns["bob"])
+
+			// the turn player leaves
+			leaver := created.Duel.TurnPlayer
+			waiter := "alice"
+			if leaver == "alice" {
+				waiter = "bob"
+			}
+			conns[leaver].Close(websocket.StatusNormalClosure, "")
+			msg := read(t, ctx, conns[waiter])
+			if msg.Type != MessageTypeStateUpdate {
+				t.Fatalf("expected state_update, got %+v", msg)
+			}
+			last := msg.Duel.ActionLog[len(msg.Duel.ActionLog)-1]
+			if !c.wantPass {
+				if msg.Duel.State != string(turnbased.DuelStateEnd) || msg.Duel.Winner != waiter ||
+					msg.Duel.EndReason != turnbased.EndReasonAbandoned || last.Action != "ABANDON" || last.PlayerID != leaver {
+					t.Errorf("expected %s to abandon, got %+v", leaver, msg.Duel)
+				}
+				return
+			}
+			if msg.Duel.State != string(turnbased.DuelStateRunning) || msg.Duel.TurnPlayer != waiter ||
+				last.Action != "END_TURN" || last.PlayerID != leaver {
+				t.Fatalf("expected the turn of %s to be passed, got %+v", leaver, msg.Duel)
+			}
+
+			// once the grace period is over, the next turns of the player are passed right away
+			send(t, ctx, conns[waiter], ClientMessage{Type: MessageTypeAction, Game: c.game,
+				DuelID: created.Duel.ID, PlayerID: waiter, Action: endTurnAction()})
+			if msg := read(t, ctx, conns[waiter]); msg.Duel.TurnPlayer != leaver {
+				t.Fatalf("expected the turn of %s, got %+v", leaver, msg.Duel)
+			}
+			if msg := read(t, ctx, conns[waiter]); msg.Duel.TurnPlayer != waiter {
+				t.Fatalf("expected the turn of %s to be passed again, got %+v", leaver, msg.Duel)
+			}
+		})
+	}
+}
+
+func TestDisconnectRule_DefaultNone(t *testing.T) {
+	duelsManagers := map[string]turnbased.DuelsManager{connect_four.GameName: turnbased.NewInMemoryDuelsManager()}
+	handler := NewWebSocketHandler(duelsManagers, NewConnectionManager())
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conns := make(map[string]*websocket.Conn)
+	for _, player := range []string{"alice", "bob"} {
+		conn, _, err := websocket.Dial(ctx, "ws"+server.URL[4:], nil)
+		if err != nil {
+			t.Fatalf("error Dial: %v", err)
+		}
+		defer conn.CloseNow()
+		conns[player] = conn
+	}
+
+	send(t, ctx, conns["alice"], ClientMessage{Type: MessageTypeCreateDuel, Game: connect_four.GameName, Players: []string{"alice", "bob"}})
+	created := read(t, ctx, conns["alice"])
+	if got := created.Duel.Settings.DisconnectRule; got != string(turnbased.DisconnectRuleNone) {
+		t.Fatalf("created duel disconnect rule: %q, want %q", got, turnbased.DisconnectRuleNone)
+	}
+	duelID := turnbased.DuelID(created.Duel.ID)
+	manager := duelsManagers[connect_four.GameName]
+	unlock := manager.LockDuel(duelID)
+	manager.GetDuel(duelID).Settings.DisconnectGracePeriod = 10 * time.Millisecond
+	unlock()
+	send(t, ctx, conns["bob"], ClientMessage{Type: MessageTypeJoinDuel, DuelID: created.Duel.ID, PlayerID: "bob"})
+	read(t, ctx, conns["bob"])
+
+	// the turn player leaves, the duel waits for them
+	leaver := created.Duel.TurnPlayer
+	conns[leaver].Close(websocket.StatusNormalClosure, "")
+	time.Sleep(100 * time.Millisecond)
+	unlock = manager.LockDuel(duelID)
+	defer unlock()
+	if duel := manager.GetDuel(duelID); duel.State != turnbased.DuelStateRunning || duel.TurnPlayer != turnbased.PlayerID(leaver) {
+		t.Errorf("expected the duel to wait for %s, got state %s, turn %s", leaver, duel.State, duel.TurnPlayer)
+	}
+}
@@ -0,0 +1,38 @@
+package httpsvr
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/coder/websocket"
+)
+
+// MarshalMessage encodes a ServerMessage or ClientMessage in the encoding (EncodingJSON if empty),
+// it returns the WebSocket frame type to send it: text for JSON, binary for CBOR.
+// A CBOR message is the CBOR form of the JSON message: same field names and values.
+func MarshalMessage(encoding string, msg any) (websocket.MessageType, []byte, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return 0, nil, err
+	}
+	switch encoding {
+	case "", EncodingJSON:
+		return websocket.MessageText, data, nil
+	case EncodingCBOR:
+		data, err = jsonToCBOR(data)
+		return websocket.MessageBinary, data, err
+	}
+	return 0, nil, fmt.Errorf("unsupported encoding %q", encoding)
+}
+
+// UnmarshalMessage decodes a WebSocket frame into a ServerMessage or ClientMessage:
+// text frames are JSON and binary frames are CBOR, whatever the encoding negotiated in hello
+func UnmarshalMessage(frameType websocket.MessageType, data []byte, msg any) error {
+	if frameType == websocket.MessageBinary {
+		var err error
+		if data, err = cborToJSON(data); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, msg)
+}
@@ -0,0 +1,83 @@
+package httpsvr
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+func TestCBOR(t *testing.T) {
+	// examples of RFC 8949 appendix A
+	for _, c := range []struct{ json, cbor string }{
+		{`0`, "00"},
+		{`23`, "17"},
+		{`24`, "1818"},
+		{`1000000`, "1a000f4240"},
+		{`-1000`, "3903e7"},
+		{`1.5`, "fa3fc00000"},
+		{`1.1`, "fb3ff199999999999a"},
+		{`false`, "f4"},
+		{`null`, "f6"},
+		{`"IETF"`, "6449455446"},
+		{`[1,[2,3]]`, "8201820203"},
+		{`{"a":1,"b":[2,3]}`, "a26161016162820203"},
+	} {
+		got, err := jsonToCBOR([]byte(c.json))
+		if err != nil || hex.EncodeToString(got) != c.cbor {
+			t.Errorf("jsonToCBOR(%s) = %x, %v, want %s", c.json, got, err, c.cbor)
+		}
+		data, _ := hex.DecodeString(c.cbor)
+		back, err := cborToJSON(data)
+		if err != nil || !bytes.Equal(back, []byte(c.json)) {
+			t.Errorf("cborToJSON(%s) = %s, %v, want %s", c.cbor, back, err, c.json)
+		}
+	}
+	// half-precision floats and tags from other encoders
+	for cbor, want := range map[string]string{"f93e00": "1.5", "c11a514b67b0": "1363896240"} {
+		data, _ := hex.DecodeString(cbor)
+		if got, err := cborToJSON(data); err != nil || string(got) != want {
+			t.Errorf("cborToJSON(%s) = %s, %v, want %s", cbor, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "82", "a10102", "5f", "0000", "9bffffffffffffffff"} {
+		data, _ := hex.DecodeString(bad)
+		if _, err := cborToJSON(data); err == nil {
+			t.Errorf("cborToJSON(%s): expected an error", bad)
+		}
+	}
+}
+
+func TestMarshalMessage(t *testing.T) {
+	column := 3
+	msg := ClientMessage{Type: MessageTypeAction, RequestID: "7", DuelID: "duel_1", PlayerID: "alice",
+		Action: model.ActionData{Column: &column}}
+	frameType, data, err := MarshalMessage(EncodingCBOR, msg)
+	if err != nil || frameType != websocket.MessageBinary {
+		t.Fatalf("MarshalMessage: %v %v", frameType, err)
+	}
+	var got ClientMessage
+	if err := UnmarshalMessage(frameType, data, &got); err != nil {
+		t.Fatalf("error UnmarshalMessage: %v", err)
+	}
+	if !reflect.DeepEqual(got, msg) {
+		t.Errorf("got %+v, want %+v", got, msg)
+	}
+
+	// a Burn state is smaller in CBOR
+	duel := card_game_burn.NewBurnDuel([]turnbased.PlayerID{"alice", "bob"})
+	state := NewStateUpdateMessage(duel.Duel, "alice")
+	_, jsonData, _ := MarshalMessage(EncodingJSON, state)
+	_, cborData, err := MarshalMessage(EncodingCBOR, state)
+	if err != nil || len(cborData) >= len(jsonData) {
+		t.Errorf("expected CBOR smaller than JSON, got %d and %d bytes, %v", len(cborData), len(jsonData), err)
+	}
+	if _, _, err := MarshalMessage("xml", state); err == nil {
+		t.Errorf("expected an error for an unsupported encoding")
+	}
+}
@@ -0,0 +1,84 @@
+package httpsvr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// ErrorCode is the machine-readable reason of an error message,
+// clients should branch on it rather than on the human-readable Error text
+type ErrorCode string
+
+const (
+	// ErrorCodeInvalidMessage means the message could not be decoded
+	ErrorCodeInvalidMessage ErrorCode = "INVALID_MESSAGE"
+	// ErrorCodeUnknownMessageType means the message type is not one of the client message types
+	ErrorCodeUnknownMessageType ErrorCode = "UNKNOWN_MESSAGE_TYPE"
+	// ErrorCodeBadRequest means a required field is missing or invalid
+	ErrorCodeBadRequest ErrorCode = "BAD_REQUEST"
+	// ErrorCodeUnknownGame means the server does not serve the game
+	ErrorCodeUnknownGame ErrorCode = "UNKNOWN_GAME"
+	// ErrorCodeDuelNotFound means no duel has the given ID
+	ErrorCodeDuelNotFound ErrorCode = "DUEL_NOT_FOUND"
+	// ErrorCodePlayerNotInDuel means the player is not one of the duel players
+	ErrorCodePlayerNotInDuel ErrorCode = "PLAYER_NOT_IN_DUEL"
+	// ErrorCodeNotPlayerTurn means the action was sent out of the player's turn
+	ErrorCodeNotPlayerTurn ErrorCode = "NOT_PLAYER_TURN"
+	// ErrorCodeDuelNotRunning means the duel has not started or has ended
+	ErrorCodeDuelNotRunning ErrorCode = "DUEL_NOT_RUNNING"
+	// ErrorCodeSpectatorsNotAllowed means the duel settings do not allow spectators
+	ErrorCodeSpectatorsNotAllowed ErrorCode = "SPECTATORS_NOT_ALLOWED"
+	// ErrorCodeSpectatorCannotAct means the connection spectates a duel, it cannot send actions
+	ErrorCodeSpectatorCannotAct ErrorCode = "SPECTATOR_CANNOT_ACT"
+	// ErrorCodeInvalidAction means the game rejected the action
+	ErrorCodeInvalidAction ErrorCode = "INVALID_ACTION"
+	// ErrorCodeIncompatibleClient means the hello was rejected, the connection is then closed
+	ErrorCodeIncompatibleClient ErrorCode = "INCOMPATIBLE_CLIENT"
+	// ErrorCodeInternal means the server failed, the request can be retried
+	ErrorCodeInternal ErrorCode = "INTERNAL"
+)
+
+// codedError is an error with the code sent to the client, its text is the one of err
+type codedError struct {
+	code ErrorCode
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+
+func (e *codedError) Unwrap() error { return e.err }
+
+// errorWithCode formats an error like fmt.Errorf and attaches the code to it
+func errorWithCode(code ErrorCode, format string, args ...any) error {
+	return &codedError{code: code, err: fmt.Errorf(format, args...)}
+}
+
+// errorCode returns the code of an error returned while handling a client message:
+// the rules common to all games (see turnbased errors) first, then the attached code,
+// BAD_REQUEST if there is none
+func errorCode(err error) ErrorCode {
+	switch {
+	case errors.Is(err, turnbased.ErrNotPlayerTurn):
+		return ErrorCodeNotPlayerTurn
+	case errors.Is(err, turnbased.ErrDuelNotRunning):
+		return ErrorCodeDuelNotRunning
+	}
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	return ErrorCodeBadRequest
+}
+
+// NewErrorMessage creates an error message answering the request (requestID can be empty)
+func NewErrorMessage(requestID string, err error) ServerMessage {
+	return ServerMessage{
+		Type:      MessageTypeError,
+		RequestID: requestID,
+		Code:      errorCode(err),
+		Error:     err.Error(),
+		Message:   err.Error(),
+	}
+}
@@ -0,0 +1,117 @@
+package httpsvr
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// WriteTimeout bounds a write of a message to a connection,
+// a connection that does not take a fanned out message in time is removed
+const WriteTimeout = 10 * time.Second
+
+// fanoutQueue is the messages fanned out to a duel that are not written yet, oldest first.
+// They are written by one goroutine at a time so that every connection receives them in seq order.
+type fanoutQueue struct {
+	holds    int // the callers of HoldFanout that did not release the duel yet
+	flushing bool
+	writes   []func()
+}
+
+// HoldFanout queues the messages fanned out to the duel until release is called,
+// release then writes them. Holding the fanout while the duel is locked (see turnbased.DuelsManager.LockDuel)
+// and releasing it after unlocking means a slow connection never blocks the next action on the duel.
+func (cm *ConnectionManager) HoldFanout(duelID turnbased.DuelID) (release func()) {
+	cm.mu.Lock()
+	cm.fanoutQueueLocked(duelID).holds++
+	cm.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cm.mu.Lock()
+			queue := cm.fanouts[duelID]
+			queue.holds--
+			flush := queue.holds == 0 && !queue.flushing
+			if flush {
+				queue.flushing = true
+			}
+			cm.mu.Unlock()
+			if flush {
+				cm.flushFanout(duelID)
+			}
+		})
+	}
+}
+
+func (cm *ConnectionManager) fanoutQueueLocked(duelID turnbased.DuelID) *fanoutQueue {
+	queue, ok := cm.fanouts[duelID]
+	if !ok {
+		queue = &fanoutQueue{}
+		cm.fanouts[duelID] = queue
+	}
+	return queue
+}
+
+// queueFanoutLocked adds the write of a message fanned out to the duel to its queue,
+// the returned function writes the queue unless the duel is held or another goroutine writes it,
+// to be called after unlocking
+func (cm *ConnectionManager) queueFanoutLocked(duelID turnbased.DuelID, write func()) func() {
+	queue := cm.fanoutQueueLocked(duelID)
+	queue.writes = append(queue.writes, write)
+	if queue.holds > 0 || queue.flushing {
+		return func() {}
+	}
+	queue.flushing = true
+	return func() { cm.flushFanout(duelID) }
+}
+
+// flushFanout writes the queued messages of the duel until the queue is empty or the duel is held,
+// the caller has set the queue flushing
+func (cm *ConnectionManager) flushFanout(duelID turnbased.DuelID) {
+	for {
+		cm.mu.Lock()
+		queue := cm.fanouts[duelID]
+		if len(queue.writes) == 0 || queue.holds > 0 {
+			queue.flushing = false
+			if len(queue.writes) == 0 && queue.holds == 0 {
+				delete(cm.fanouts, duelID)
+			}
+			cm.mu.Unlock()
+			return
+		}
+		writes := queue.writes
+		queue.writes = nil
+		cm.mu.Unlock()
+		for _, write := range writes {
+			write()
+		}
+	}
+}
+
+// writeAll writes to the connections concurrently, connections that fail or time out are removed
+func (cm *ConnectionManager) writeAll(conns []Subscriber, frameFor func(Subscriber) frame) {
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		wg.Add(1)
+		go func(c Subscriber) {
+			defer wg.Done()
+			f := frameFor(c)
+			if err := writeWithTimeout(c, f.typ, f.data); err != nil {
+				log.Printf("Error broadcasting to connection: %v", err)
+				cm.RemoveConnection(c)
+			}
+		}(conn)
+	}
+	wg.Wait()
+}
+
+// writeWithTimeout writes to the connection, failing if it does not take the message within WriteTimeout
+func writeWithTimeout(conn Subscriber, typ websocket.MessageType, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), WriteTimeout)
+	defer cancel()
+	return conn.Write(ctx, typ, data)
+}
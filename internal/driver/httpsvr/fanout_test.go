@@ -0,0 +1,90 @@
+package httpsvr
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// recordingSubscriber keeps the seq of the messages written to it, or fails the writes with err
+type recordingSubscriber struct {
+	mu   sync.Mutex
+	seqs []int
+	err  error
+}
+
+func (s *recordingSubscriber) Write(ctx context.Context, typ websocket.MessageType, data []byte) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return context.Canceled // the fanout must bound its writes
+	}
+	if s.err != nil {
+		return s.err
+	}
+	var msg ServerMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seqs = append(s.seqs, msg.Seq)
+	return nil
+}
+
+func (s *recordingSubscriber) written() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int{}, s.seqs...)
+}
+
+func TestConnectionManager_HoldFanout(t *testing.T) {
+	cm := NewConnectionManager()
+	duelID := turnbased.DuelID("duel1")
+	alice := &recordingSubscriber{}
+	cm.AddConnection(alice, "alice", duelID)
+
+	release := cm.HoldFanout(duelID)
+	for range 2 {
+		if err := cm.BroadcastToDuel(duelID, ServerMessage{Type: MessageTypeError}); err != nil {
+			t.Fatalf("error BroadcastToDuel: %v", err)
+		}
+	}
+	if got := alice.written(); len(got) != 0 {
+		t.Fatalf("messages written while the duel is held: %v", got)
+	}
+	release()
+	release() // releasing twice is a no-op
+	if got := alice.written(); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Fatalf("expected seqs [2 3] written on release, got %v", got)
+	}
+	if err := cm.BroadcastToDuel(duelID, ServerMessage{Type: MessageTypeError}); err != nil {
+		t.Fatalf("error BroadcastToDuel: %v", err)
+	}
+	if got := alice.written(); len(got) != 3 {
+		t.Errorf("expected a message of a released duel to be written right away, got %v", got)
+	}
+	if len(cm.fanouts) != 0 {
+		t.Errorf("expected no fanout queue left, got %v", cm.fanouts)
+	}
+}
+
+func TestConnectionManager_FanoutDropsFailedSubscriber(t *testing.T) {
+	cm := NewConnectionManager()
+	duelID := turnbased.DuelID("duel1")
+	alice, bob := &recordingSubscriber{}, &recordingSubscriber{err: context.DeadlineExceeded}
+	cm.AddConnection(alice, "alice", duelID)
+	cm.AddConnection(bob, "bob", duelID)
+	if err := cm.BroadcastToDuel(duelID, ServerMessage{Type: MessageTypeError}); err != nil {
+		t.Fatalf("error BroadcastToDuel: %v", err)
+	}
+	if _, _, ok := cm.ConnectionPlayer(bob); ok {
+		t.Errorf("expected the subscriber that timed out to be removed")
+	}
+	// alice receives bob's connection, the message, then bob's disconnection
+	if got := alice.written(); len(got) != 3 || got[0] != 2 || got[1] != 3 || got[2] != 4 {
+		t.Errorf("expected alice to receive seqs [2 3 4], got %v", got)
+	}
+}
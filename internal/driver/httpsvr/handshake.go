@@ -0,0 +1,107 @@
+package httpsvr
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Protocol versions of the WebSocket messages. A client that does not send hello
+// is assumed to speak MinProtocolVersion.
+const (
+	ProtocolVersion    = 1 // the newest version this server speaks
+	MinProtocolVersion = 1 // older clients are rejected
+)
+
+// Encodings of WebSocket messages, see MarshalMessage
+const (
+	EncodingJSON = "json" // in text frames
+	EncodingCBOR = "cbor" // in binary frames, smaller than JSON
+)
+
+// supportedEncodings is the encodings this server can speak, the first one is the default
+var supportedEncodings = []string{EncodingJSON, EncodingCBOR}
+
+// Optional protocol features, asked by the client in hello
+const (
+	// FeatureDelta sends state_delta instead of full state_update after the first state of a duel
+	FeatureDelta = "delta"
+)
+
+// supportedFeatures is the optional protocol features this server can enable for a connection
+var supportedFeatures = []string{FeatureDelta}
+
+// HelloInfo is the server's answer to hello: what it supports and what is enabled for the connection
+type HelloInfo struct {
+	// ProtocolVersion is the version used on this connection: the client's one if the server knows it,
+	// otherwise the server's newest version, the client should then adapt or disconnect
+	ProtocolVersion    int      `json:"protocol_version"`
+	MinProtocolVersion int      `json:"min_protocol_version"`
+	MaxProtocolVersion int      `json:"max_protocol_version"`
+	Games              []string `json:"games"`
+	Encodings          []string `json:"encodings"` // supported by the server
+	Encoding           string   `json:"encoding"`  // used on this connection
+	// Features is the features enabled on this connection: the ones asked by the client
+	// that the server supports
+	Features          []string `json:"features"`
+	SupportedFeatures []string `json:"supported_features"`
+}
+
+// connSession is the protocol state negotiated for one WebSocket connection
+type connSession struct {
+	helloDone       bool
+	started         bool // a message other than hello was received
+	protocolVersion int
+	encoding        string
+	features        map[string]bool
+}
+
+func newConnSession() *connSession {
+	return &connSession{
+		protocolVersion: MinProtocolVersion,
+		encoding:        supportedEncodings[0],
+		features:        make(map[string]bool),
+	}
+}
+
+// hello negotiates the protocol of the connection, it is optional but must be the first message.
+// It returns an error if the client is too old or asks for an unsupported encoding.
+func (s *connSession) hello(msg *ClientMessage, games []string) (*HelloInfo, error) {
+	if s.helloDone {
+		return nil, fmt.Errorf("hello already done")
+	}
+	if s.started {
+		return nil, fmt.Errorf("hello must be the first message")
+	}
+	if msg.ProtocolVersion < MinProtocolVersion {
+		return nil, fmt.Errorf("unsupported protocol version %d, server supports %d to %d",
+			msg.ProtocolVersion, MinProtocolVersion, ProtocolVersion)
+	}
+	s.protocolVersion = min(msg.ProtocolVersion, ProtocolVersion)
+	if msg.Encoding != "" {
+		if !slices.Contains(supportedEncodings, msg.Encoding) {
+			return nil, fmt.Errorf("unsupported encoding %q, server supports %v", msg.Encoding, supportedEncodings)
+		}
+		s.encoding = msg.Encoding
+	}
+	enabled := []string{}
+	for _, feature := range msg.Features {
+		if slices.Contains(supportedFeatures, feature) && !s.features[feature] {
+			s.features[feature] = true
+			enabled = append(enabled, feature)
+		}
+	}
+	s.helloDone = true
+
+	sort.Strings(games)
+	return &HelloInfo{
+		ProtocolVersion:    s.protocolVersion,
+		MinProtocolVersion: MinProtocolVersion,
+		MaxProtocolVersion: ProtocolVersion,
+		Games:              games,
+		Encodings:          slices.Clone(supportedEncodings),
+		Encoding:           s.encoding,
+		Features:           enabled,
+		SupportedFeatures:  append([]string{}, supportedFeatures...),
+	}, nil
+}
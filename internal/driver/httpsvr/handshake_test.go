@@ -0,0 +1,102 @@
+package httpsvr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/connect_four"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+func TestHello(t *testing.T) {
+	handler := NewWebSocketHandler(
+		map[string]turnbased.DuelsManager{connect_four.GameName: turnbased.NewInMemoryDuelsManager()},
+		NewConnectionManager(),
+	)
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// exchange sends the messages then returns the server's answer to the last one
+	exchange := func(msgs ...ClientMessage) (ServerMessage, *websocket.Conn) {
+		conn, _, err := websocket.Dial(ctx, "ws"+server.URL[4:], nil)
+		if err != nil {
+			t.Fatalf("error Dial: %v", err)
+		}
+		var answer ServerMessage
+		for _, msg := range msgs {
+			data, _ := json.Marshal(msg)
+			if err := conn.Write(ctx, websocket.MessageText, data); err != nil {
+				t.Fatalf("error Write: %v", err)
+			}
+			_, data, err = conn.Read(ctx)
+			if err != nil {
+				t.Fatalf("error Read: %v", err)
+			}
+			answer = ServerMessage{}
+			_ = json.Unmarshal(data, &answer)
+		}
+		return answer, conn
+	}
+
+	t.Run("compatible client", func(t *testing.T) {
+		answer, conn := exchange(ClientMessage{Type: MessageTypeHello, ProtocolVersion: 1, Features: []string{"teleport"}})
+		defer conn.Close(websocket.StatusNormalClosure, "")
+		if answer.Type != MessageTypeWelcome || answer.Hello == nil {
+			t.Fatalf("expected welcome, got %+v", answer)
+		}
+		hello := answer.Hello
+		if hello.ProtocolVersion != 1 || hello.Encoding != EncodingJSON || len(hello.Features) != 0 {
+			t.Errorf("unexpected negotiation: %+v", hello)
+		}
+		if len(hello.Games) != 1 || hello.Games[0] != connect_four.GameName {
+			t.Errorf("unexpected games: %v", hello.Games)
+		}
+		// the connection is usable after hello
+		created, conn2 := exchange(ClientMessage{Type: MessageTypeHello, ProtocolVersion: 1},
+			ClientMessage{Type: MessageTypeCreateDuel, Game: connect_four.GameName, Players: []string{"alice", "bob"}})
+		defer conn2.Close(websocket.StatusNormalClosure, "")
+		if created.Type != MessageTypeStateUpdate {
+			t.Errorf("expected state_update after hello, got %+v", created)
+		}
+	})
+
+	t.Run("newer client adapts to the server version", func(t *testing.T) {
+		answer, conn := exchange(ClientMessage{Type: MessageTypeHello, ProtocolVersion: ProtocolVersion + 1})
+		defer conn.Close(websocket.StatusNormalClosure, "")
+		if answer.Type != MessageTypeWelcome || answer.Hello.ProtocolVersion != ProtocolVersion {
+			t.Errorf("expected the server version %d, got %+v", ProtocolVersion, answer)
+		}
+	})
+
+	for name, hello := range map[string]ClientMessage{
+		"too old client":       {Type: MessageTypeHello, ProtocolVersion: MinProtocolVersion - 1},
+		"unsupported encoding": {Type: MessageTypeHello, ProtocolVersion: 1, Encoding: "xml"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			answer, conn := exchange(hello)
+			if answer.Type != MessageTypeError || answer.Error == "" {
+				t.Errorf("expected an error, got %+v", answer)
+			}
+			if _, _, err := conn.Read(ctx); websocket.CloseStatus(err) != websocket.StatusPolicyViolation {
+				t.Errorf("expected the connection closed with policy violation, got %v", err)
+			}
+		})
+	}
+
+	t.Run("hello after other messages", func(t *testing.T) {
+		answer, conn := exchange(
+			ClientMessage{Type: MessageTypeCreateDuel, Game: connect_four.GameName, Players: []string{"alice", "bob"}},
+			ClientMessage{Type: MessageTypeHello, ProtocolVersion: 1})
+		defer conn.Close(websocket.StatusNormalClosure, "")
+		if answer.Type != MessageTypeError {
+			t.Errorf("expected an error, got %+v", answer)
+		}
+	})
+}
@@ -2,6 +2,9 @@
 package httpsvr
 
 import (
+	"encoding/json"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
 	"github.com/daominah/turn_based_game/internal/model"
 )
 
@@ -19,24 +22,120 @@ const (
 	MessageTypeError MessageType = "error"
 	// MessageTypeJoinDuel is sent from client to server to join an existing duel
 	MessageTypeJoinDuel MessageType = "join_duel"
+	// MessageTypeHello is sent from client to server as the first message to negotiate
+	// the protocol version, encoding and features, optional for version 1 clients
+	MessageTypeHello MessageType = "hello"
+	// MessageTypeWelcome is sent from server to client as the answer to hello
+	MessageTypeWelcome MessageType = "welcome"
+	// MessageTypeAck is sent from server to client when a request with a request_id succeeded,
+	// after the state updates it caused
+	MessageTypeAck MessageType = "ack"
+	// MessageTypeStateDelta is sent from server to client instead of state_update
+	// on connections with the delta feature, once the connection received a full state
+	MessageTypeStateDelta MessageType = "state_delta"
+	// MessageTypeResync is sent from client to server to get the full state of its duel,
+	// when a state_delta does not apply to the client's state version
+	MessageTypeResync MessageType = "resync"
+	// MessageTypePresence is sent from server to client when a player of the duel connects,
+	// disconnects or reconnects
+	MessageTypePresence MessageType = "presence"
+	// MessageTypeSpectate is sent from client to server to watch a duel without playing,
+	// the connection receives the public state of the duel and cannot send actions
+	MessageTypeSpectate MessageType = "spectate"
+	// MessageTypeSpectators is sent from server to client when the number of spectators of the duel changes
+	MessageTypeSpectators MessageType = "spectators"
+)
+
+// Events of a presence message
+const (
+	PresenceEventConnected    = "connected"    // the player connected to the duel for the first time
+	PresenceEventDisconnected = "disconnected" // the last connection of the player was lost or closed
+	PresenceEventReconnected  = "reconnected"  // the player connected again after being disconnected
 )
 
 // ClientMessage represents a message sent from client to server
 type ClientMessage struct {
-	Type     MessageType      `json:"type"`
-	DuelID   string           `json:"duel_id,omitempty"`
-	PlayerID string           `json:"player_id,omitempty"`
-	Game     string           `json:"game,omitempty"`
-	Players  []string         `json:"players,omitempty"`
-	Action   model.ActionData `json:"action,omitempty"`
+	Type MessageType `json:"type"`
+	// RequestID is chosen by the client, if set the server answers the message
+	// with an ack or an error carrying the same request_id
+	RequestID string           `json:"request_id,omitempty"`
+	DuelID    string           `json:"duel_id,omitempty"`
+	PlayerID  string           `json:"player_id,omitempty"`
+	Game      string           `json:"game,omitempty"`
+	Players   []string         `json:"players,omitempty"`
+	Action    model.ActionData `json:"action,omitempty"`
+	// Bots is used with create_duel: player ID -> bot kind (e.g. "GREEDY"),
+	// these players are played by the server, for games implementing BotDuelCreator
+	Bots map[string]string `json:"bots,omitempty"`
+	// Settings is optionally used with create_duel, missing values are the defaults
+	Settings *model.DuelSettings `json:"settings,omitempty"`
+	// LastSeq is used with join_duel and spectate to resume: the seq of the last message the client received
+	// in the duel, the server then sends the messages after it instead of the full state
+	LastSeq int `json:"last_seq,omitempty"`
+
+	// ProtocolVersion, Encoding and Features are used with hello
+	ProtocolVersion int      `json:"protocol_version,omitempty"`
+	Encoding        string   `json:"encoding,omitempty"` // empty means the server default
+	Features        []string `json:"features,omitempty"`
 }
 
 // ServerMessage represents a message sent from server to client
 // GameState uses any because different games have different state structures
 type ServerMessage struct {
 	Type      MessageType             `json:"type"`
+	RequestID string                  `json:"request_id,omitempty"` // of the answered client message
 	Duel      *model.SerializableDuel `json:"duel,omitempty"`
 	GameState any                     `json:"game_state,omitempty"` // Game-specific state (e.g., model.BurnGameState)
 	Error     string                  `json:"error,omitempty"`
+	Code      ErrorCode               `json:"code,omitempty"` // for error messages
 	Message   string                  `json:"message,omitempty"`
+	Hello     *HelloInfo              `json:"hello,omitempty"` // for welcome messages
+	Presence  *PresenceEvent          `json:"presence,omitempty"`
+	// Spectators is set in spectators messages
+	Spectators *SpectatorsEvent `json:"spectators,omitempty"`
+	// Seq is the sequence number of a message fanned out to a duel (state changes and events),
+	// it increases by one with each message of the duel, see ConnectionManager.Resume.
+	// A state_update sent to one connection (on create, join or resync) has the seq of the last message.
+	Seq int `json:"seq,omitempty"`
+	// Version is the state version of the duel in a state_update or state_delta:
+	// the seq of the message that changed the state to it
+	Version int `json:"version,omitempty"`
+	// BaseVersion is the version a state_delta applies to
+	BaseVersion int         `json:"base_version,omitempty"`
+	Delta       *StateDelta `json:"delta,omitempty"`
+}
+
+// StateDelta is the change from the state of BaseVersion to the state of Version,
+// the patches are JSON merge patches (RFC 7386, see model.ApplyMergePatch)
+type StateDelta struct {
+	// DuelPatch applies to the duel without its action log
+	DuelPatch json.RawMessage `json:"duel_patch"`
+	// NewLogEntries is appended to the action log
+	NewLogEntries  []model.SerializableActionLogEntry `json:"new_log_entries"`
+	GameStatePatch json.RawMessage                    `json:"game_state_patch"`
+}
+
+// PresenceEvent is the payload of a presence message
+type PresenceEvent struct {
+	DuelID   string                     `json:"duel_id"`
+	PlayerID string                     `json:"player_id"`
+	Event    string                     `json:"event"`    // connected, disconnected or reconnected
+	Presence model.SerializablePresence `json:"presence"` // the new presence of the player, see model.SerializableDuel
+}
+
+// SpectatorsEvent is the payload of a spectators message
+type SpectatorsEvent struct {
+	DuelID string `json:"duel_id"`
+	Count  int    `json:"count"` // the new number of spectators, see model.SerializableDuel
+}
+
+// NewStateUpdateMessage creates a state_update message with the generic duel
+// and the game-specific state as seen by the viewer (see turnbased.StateForPlayer)
+func NewStateUpdateMessage(duel *turnbased.Duel, viewer turnbased.PlayerID) ServerMessage {
+	serializableDuel := model.FromDuel(duel)
+	return ServerMessage{
+		Type:      MessageTypeStateUpdate,
+		Duel:      &serializableDuel,
+		GameState: turnbased.StateForPlayer(duel.Game, viewer),
+	}
 }
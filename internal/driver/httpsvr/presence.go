@@ -0,0 +1,91 @@
+package httpsvr
+
+import (
+	"log"
+	"time"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// playerPresence is whether a player has a connection to a duel, since when
+type playerPresence struct {
+	connected bool
+	since     time.Time
+}
+
+func (p playerPresence) serializable() model.SerializablePresence {
+	status := model.PresenceDisconnected
+	if p.connected {
+		status = model.PresenceConnected
+	}
+	return model.SerializablePresence{Status: status, Since: p.since.Format(model.TimestampFormat)}
+}
+
+// setPresenceLocked records that the player connected to or disconnected from the duel.
+// If the presence changed, it returns the function broadcasting the presence event to the duel,
+// to be called after unlocking, otherwise a no-op.
+// The event is encoded for the connections of the duel at the time of the call.
+func (cm *ConnectionManager) setPresenceLocked(duelID turnbased.DuelID, playerID turnbased.PlayerID, connected bool) func() {
+	if playerID == "" {
+		return func() {}
+	}
+	if cm.presence[duelID] == nil {
+		cm.presence[duelID] = make(map[turnbased.PlayerID]playerPresence)
+	}
+	old, known := cm.presence[duelID][playerID]
+	if old.connected == connected && (known || !connected) {
+		return func() {}
+	}
+	event := PresenceEventConnected
+	switch {
+	case !connected:
+		event = PresenceEventDisconnected
+	case known:
+		event = PresenceEventReconnected
+	}
+	presence := playerPresence{connected: connected, since: time.Now()}
+	cm.presence[duelID][playerID] = presence
+	log.Printf("Presence: player=%s %s, duel=%s", playerID, event, duelID)
+
+	write, err := cm.broadcastLocked(duelID, ServerMessage{
+		Type: MessageTypePresence,
+		Presence: &PresenceEvent{
+			DuelID:   string(duelID),
+			PlayerID: string(playerID),
+			Event:    event,
+			Presence: presence.serializable(),
+		},
+	})
+	if err != nil {
+		log.Printf("Error broadcasting presence: %v", err)
+		write = func() {}
+	}
+	onDuelChange := cm.onDuelChange
+	return func() {
+		write()
+		if onDuelChange != nil {
+			onDuelChange(duelID)
+		}
+	}
+}
+
+// playerPresence returns the presence of the player in the duel, false if they never connected to it
+func (cm *ConnectionManager) playerPresence(duelID turnbased.DuelID, playerID turnbased.PlayerID) (playerPresence, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	p, ok := cm.presence[duelID][playerID]
+	return p, ok
+}
+
+// presenceLocked returns the presence of the players of the duel, nil if none ever connected
+func (cm *ConnectionManager) presenceLocked(duelID turnbased.DuelID) map[string]model.SerializablePresence {
+	if len(cm.presence[duelID]) == 0 {
+		return nil
+	}
+	presence := make(map[string]model.SerializablePresence, len(cm.presence[duelID]))
+	for playerID, p := range cm.presence[duelID] {
+		presence[string(playerID)] = p.serializable()
+	}
+	return presence
+}
@@ -0,0 +1,124 @@
+package httpsvr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/connect_four"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+func TestPresence(t *testing.T) {
+	handler := NewWebSocketHandler(
+		map[string]turnbased.DuelsManager{connect_four.GameName: turnbased.NewInMemoryDuelsManager()},
+		NewConnectionManager(),
+	)
+	handler.heartbeatInterval = 20 * time.Millisecond
+	handler.heartbeatTimeout = 50 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	dial := func() *websocket.Conn {
+		conn, _, err := websocket.Dial(ctx, "ws"+server.URL[4:], nil)
+		if err != nil {
+			t.Fatalf("error Dial: %v", err)
+		}
+		t.Cleanup(func() { conn.CloseNow() })
+		return conn
+	}
+	lastSeq := 0
+	readPresence := func(conn *websocket.Conn, player string, event string) {
+		t.Helper()
+		msg := readAny(t, ctx, conn)
+		if msg.Type != MessageTypePresence || msg.Presence.PlayerID != player || msg.Presence.Event != event {
+			t.Fatalf("expected presence %s %s, got %+v", player, event, msg)
+		}
+		if msg.Seq != lastSeq+1 {
+			t.Errorf("presence %s %s: seq %d, want %d", player, event, msg.Seq, lastSeq+1)
+		}
+		lastSeq = msg.Seq
+	}
+
+	alice := dial()
+	send(t, ctx, alice, ClientMessage{Type: MessageTypeCreateDuel, Game: connect_four.GameName, Players: []string{"alice", "bob"}})
+	created := readAny(t, ctx, alice)
+	if created.Type != MessageTypeStateUpdate {
+		t.Fatalf("expected state_update, got %+v", created)
+	}
+	lastSeq = created.Seq
+	// the connection that creates the duel plays for bob too, until bob joins
+	if presence := created.Duel.Presence; presence["alice"].Status != model.PresenceConnected ||
+		presence["bob"].Status != model.PresenceConnected {
+		t.Errorf("created duel presence: %+v, want both connected", presence)
+	}
+
+	// bob joins, still connected, then stops reading so the heartbeat pongs never come
+	bob := dial()
+	send(t, ctx, bob, ClientMessage{Type: MessageTypeJoinDuel, DuelID: created.Duel.ID, PlayerID: "bob"})
+	joined := readAny(t, ctx, bob)
+	if presence := joined.Duel.Presence; presence["alice"].Status != model.PresenceConnected ||
+		presence["bob"].Status != model.PresenceConnected {
+		t.Errorf("joined duel presence: %+v, want both connected", presence)
+	}
+	readPresence(alice, "bob", PresenceEventDisconnected)
+
+	// bob comes back, alice sees the presence in the next state too
+	bob = dial()
+	send(t, ctx, bob, ClientMessage{Type: MessageTypeJoinDuel, DuelID: created.Duel.ID, PlayerID: "bob"})
+	readPresence(alice, "bob", PresenceEventReconnected)
+	go func() { // keep answering pings
+		for {
+			if _, _, err := bob.Read(ctx); err != nil {
+				return
+			}
+		}
+	}()
+	column := 3
+	send(t, ctx, alice, ClientMessage{Type: MessageTypeAction, Game: connect_four.GameName, DuelID: created.Duel.ID,
+		PlayerID: "alice", Action: model.ActionData{Column: &column}})
+	state := readAny(t, ctx, alice)
+	if state.Type != MessageTypeStateUpdate || state.Duel.Presence["bob"].Status != model.PresenceConnected {
+		t.Errorf("expected a state where bob is connected, got %+v", state)
+	}
+
+	// alice closes the connection
+	alice.Close(websocket.StatusNormalClosure, "")
+	time.Sleep(20 * time.Millisecond) // let the server read loop end
+	handler.connectionMgr.mu.RLock()
+	presence := handler.connectionMgr.presenceLocked(turnbased.DuelID(created.Duel.ID))
+	handler.connectionMgr.mu.RUnlock()
+	if presence["alice"].Status != model.PresenceDisconnected || presence["bob"].Status != model.PresenceConnected {
+		t.Errorf("presence after alice left: %+v", presence)
+	}
+}
+
+func TestConnectionManager_HotSeatCreatesAnotherDuel(t *testing.T) {
+	cm := NewConnectionManager()
+	conn := newPollSubscriber(cm)
+	players := []turnbased.PlayerID{"alice", "bob"}
+	cm.AddHotSeatConnection(conn, players, "duel1")
+	cm.AddHotSeatConnection(conn, players, "duel2")
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	if cm.connToDuel[conn] != "duel2" || cm.connToPlayer[conn] != "alice" ||
+		cm.playerConnections["alice"] != conn || cm.playerConnections["bob"] != conn {
+		t.Errorf("expected the connection to play for alice and bob in duel2")
+	}
+	if len(cm.duelConnections["duel1"]) != 0 {
+		t.Errorf("expected the connection to leave duel1, got %v", cm.duelConnections["duel1"])
+	}
+	old, current := cm.presenceLocked("duel1"), cm.presenceLocked("duel2")
+	for _, playerID := range []string{"alice", "bob"} {
+		if old[playerID].Status != model.PresenceDisconnected || current[playerID].Status != model.PresenceConnected {
+			t.Errorf("presence of %s: duel1 %+v, duel2 %+v", playerID, old[playerID], current[playerID])
+		}
+	}
+}
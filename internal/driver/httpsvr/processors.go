@@ -0,0 +1,168 @@
+package httpsvr
+
+import (
+	"slices"
+
+	"github.com/daominah/turn_based_game/internal/core/battleship"
+	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
+	"github.com/daominah/turn_based_game/internal/core/chess"
+	"github.com/daominah/turn_based_game/internal/core/connect_four"
+	"github.com/daominah/turn_based_game/internal/core/rock_paper_scissors"
+	"github.com/daominah/turn_based_game/internal/core/spades"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// NewActionProcessors creates the action processor of each game in duelsManagers (key is game name),
+// processors fan out state changes to the connections of connectionMgr
+func NewActionProcessors(
+	duelsManagers map[string]turnbased.DuelsManager,
+	connectionMgr *ConnectionManager,
+) map[string]ActionProcessor {
+	processors := make(map[string]ActionProcessor)
+	if _, ok := duelsManagers[card_game_burn.GameName]; ok {
+		processor := NewBurnActionProcessor(duelsManagers[card_game_burn.GameName])
+		processor.SetConnectionManager(connectionMgr)
+		processors[card_game_burn.GameName] = processor
+	}
+	if _, ok := duelsManagers[connect_four.GameName]; ok {
+		processor := NewConnectFourActionProcessor(duelsManagers[connect_four.GameName])
+		processor.SetConnectionManager(connectionMgr)
+		processors[connect_four.GameName] = processor
+	}
+	if _, ok := duelsManagers[chess.GameName]; ok {
+		processor := NewChessActionProcessor(duelsManagers[chess.GameName])
+		processor.SetConnectionManager(connectionMgr)
+		processors[chess.GameName] = processor
+	}
+	if _, ok := duelsManagers[battleship.GameName]; ok {
+		processor := NewBattleshipActionProcessor(duelsManagers[battleship.GameName])
+		processor.SetConnectionManager(connectionMgr)
+		processors[battleship.GameName] = processor
+	}
+	if _, ok := duelsManagers[spades.GameName]; ok {
+		processor := NewSpadesActionProcessor(duelsManagers[spades.GameName])
+		processor.SetConnectionManager(connectionMgr)
+		processors[spades.GameName] = processor
+	}
+	if _, ok := duelsManagers[rock_paper_scissors.GameName]; ok {
+		processor := NewRockPaperScissorsActionProcessor(duelsManagers[rock_paper_scissors.GameName])
+		processor.SetConnectionManager(connectionMgr)
+		processors[rock_paper_scissors.GameName] = processor
+	}
+	return processors
+}
+
+// createDuel creates a duel of the game, bots (player ID -> bot kind) and settings are optional,
+// the first player is the creator so it cannot be a bot
+func createDuel(processors map[string]ActionProcessor,
+	game string, players []string, bots map[string]string, settings *model.DuelSettings) (*turnbased.Duel, error) {
+	duelSettings := turnbased.DefaultSettings()
+	if settings != nil {
+		var err error
+		if duelSettings, err = settings.ToSettings(); err != nil {
+			return nil, &codedError{code: ErrorCodeBadRequest, err: err}
+		}
+	}
+	duel, err := newDuel(processors, game, players, bots)
+	if err != nil {
+		return nil, err
+	}
+	duel.Settings = duelSettings
+	return duel, nil
+}
+
+func newDuel(processors map[string]ActionProcessor,
+	game string, players []string, bots map[string]string) (*turnbased.Duel, error) {
+	if game == "" {
+		return nil, errorWithCode(ErrorCodeBadRequest, "game name required")
+	}
+	if len(players) == 0 {
+		return nil, errorWithCode(ErrorCodeBadRequest, "at least one player required")
+	}
+	processor, ok := processors[game]
+	if !ok {
+		return nil, errorWithCode(ErrorCodeUnknownGame, "unknown game: %s", game)
+	}
+
+	playerIDs := make([]turnbased.PlayerID, len(players))
+	for i, p := range players {
+		playerIDs[i] = turnbased.PlayerID(p)
+	}
+	if len(bots) == 0 {
+		return processor.CreateDuel(game, playerIDs)
+	}
+	botCreator, ok := processor.(BotDuelCreator)
+	if !ok {
+		return nil, errorWithCode(ErrorCodeBadRequest, "game %s does not support bots", game)
+	}
+	botKinds := make(map[turnbased.PlayerID]string)
+	for pid, kind := range bots {
+		botKinds[turnbased.PlayerID(pid)] = kind
+	}
+	if _, ok := botKinds[playerIDs[0]]; ok {
+		return nil, errorWithCode(ErrorCodeBadRequest, "the first player is the creator, it cannot be a bot")
+	}
+	duel, err := botCreator.CreateDuelWithBots(game, playerIDs, botKinds)
+	if err != nil {
+		return nil, &codedError{code: ErrorCodeBadRequest, err: err}
+	}
+	return duel, nil
+}
+
+// processAction lets the game's processor handle the action (Message In → Persist → Fanout)
+// while the duel is locked, so that actions on a duel from any transport are serialized.
+// The fanout is written after unlocking the duel, see ConnectionManager.HoldFanout.
+// respond, if not nil, is called with the duel after the action before unlocking it,
+// e.g. to build the response of the REST API without racing with the next action.
+func processAction(
+	duelsManagers map[string]turnbased.DuelsManager, processors map[string]ActionProcessor, connectionMgr *ConnectionManager,
+	game string, duelID turnbased.DuelID, playerID turnbased.PlayerID, action model.ActionData,
+	respond func(duel *turnbased.Duel),
+) error {
+	if duelID == "" {
+		return errorWithCode(ErrorCodeBadRequest, "duel_id required")
+	}
+	if playerID == "" {
+		return errorWithCode(ErrorCodeBadRequest, "player_id required")
+	}
+	if game == "" {
+		return errorWithCode(ErrorCodeBadRequest, "game required")
+	}
+	manager, ok := duelsManagers[game]
+	if !ok {
+		return errorWithCode(ErrorCodeUnknownGame, "unknown game: %s", game)
+	}
+	if manager.GetDuel(duelID) == nil {
+		return errorWithCode(ErrorCodeDuelNotFound, "duel not found: %s", duelID)
+	}
+	processor, ok := processors[game]
+	if !ok {
+		return errorWithCode(ErrorCodeUnknownGame, "no processor for game: %s", game)
+	}
+	if !slices.Contains(manager.GetDuel(duelID).Players, playerID) {
+		return errorWithCode(ErrorCodePlayerNotInDuel, "player %s is not in duel %s", playerID, duelID)
+	}
+	release := connectionMgr.HoldFanout(duelID)
+	defer release()
+	unlock := manager.LockDuel(duelID)
+	defer unlock()
+	if err := processor.ProcessAction(duelID, playerID, action); err != nil {
+		// the game rejected the action, errorCode still tells turn and state errors apart
+		return &codedError{code: ErrorCodeInvalidAction, err: err}
+	}
+	if respond != nil {
+		respond(manager.GetDuel(duelID))
+	}
+	return nil
+}
+
+// findDuel searches the duel in the managers of all games, it returns the game name too
+func findDuel(duelsManagers map[string]turnbased.DuelsManager, duelID turnbased.DuelID) (*turnbased.Duel, string) {
+	for game, manager := range duelsManagers {
+		if duel := manager.GetDuel(duelID); duel != nil {
+			return duel, game
+		}
+	}
+	return nil, ""
+}
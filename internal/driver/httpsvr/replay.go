@@ -0,0 +1,116 @@
+package httpsvr
+
+import (
+	"encoding/json"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// ReplayBufferSize is the number of messages kept per duel for resuming clients,
+// a client that missed more gets the full state
+const ReplayBufferSize = 128
+
+// replayEntry is a message fanned out to a duel, encoded in JSON
+type replayEntry struct {
+	seq     int
+	message json.RawMessage // the same message for all viewers, nil for a state
+	// states is the state_update as seen by each player, the key "" is the public state
+	states map[turnbased.PlayerID]json.RawMessage
+}
+
+// messageFor returns the message as seen by the viewer
+func (e replayEntry) messageFor(viewer turnbased.PlayerID) json.RawMessage {
+	if e.states == nil {
+		return e.message
+	}
+	if state, ok := e.states[viewer]; ok {
+		return state
+	}
+	return e.states[""]
+}
+
+// replayBuffer is the last messages of a duel, oldest first, with consecutive seqs
+type replayBuffer struct {
+	entries []replayEntry
+}
+
+func (b *replayBuffer) add(entry replayEntry) {
+	b.entries = append(b.entries, entry)
+	if len(b.entries) > ReplayBufferSize {
+		b.entries = append([]replayEntry{}, b.entries[len(b.entries)-ReplayBufferSize:]...)
+	}
+}
+
+// since returns the entries after lastSeq, ok is false if some of them are not kept
+// or if lastSeq is not a seq of the duel, a nil buffer keeps no entries
+func (b *replayBuffer) since(lastSeq int, currentSeq int) (entries []replayEntry, ok bool) {
+	if lastSeq <= 0 || lastSeq > currentSeq {
+		return nil, false
+	}
+	if lastSeq == currentSeq {
+		return nil, true
+	}
+	if b == nil || len(b.entries) == 0 || b.entries[0].seq > lastSeq+1 {
+		return nil, false
+	}
+	return b.entries[lastSeq+1-b.entries[0].seq:], true
+}
+
+// Resume sends the messages of the duel after lastSeq to the connection, in order and as seen
+// by the viewer, or the full state if some of them are not kept anymore (see ReplayBufferSize).
+// Nothing is sent if the client missed nothing.
+// A message fanned out while resuming can arrive twice, clients drop a seq they already received.
+func (cm *ConnectionManager) Resume(conn Subscriber, duel *turnbased.Duel, viewer turnbased.PlayerID, lastSeq int) error {
+	send, err := cm.PrepareResume(conn, duel, viewer, lastSeq)
+	if err != nil {
+		return err
+	}
+	return send()
+}
+
+// PrepareResume is Resume in two steps like PrepareState: it picks the messages right away,
+// to be called while the duel is locked, and send writes them, to be called after unlocking
+func (cm *ConnectionManager) PrepareResume(conn Subscriber, duel *turnbased.Duel, viewer turnbased.PlayerID, lastSeq int) (
+	send func() error, err error) {
+	cm.mu.Lock()
+	entries, ok := cm.replays[duel.ID].since(lastSeq, cm.seqLocked(duel.ID))
+	if !ok {
+		cm.mu.Unlock()
+		return cm.PrepareState(conn, duel, viewer)
+	}
+	messages := make([]json.RawMessage, len(entries))
+	var lastState json.RawMessage
+	for i, entry := range entries {
+		messages[i] = entry.messageFor(viewer)
+		if entry.states != nil {
+			lastState = messages[i]
+		}
+	}
+	encoding := cm.encodingLocked(conn)
+	if options, ok := cm.connOptions[conn]; ok && options.delta != nil && lastState != nil {
+		// the replayed states are full, the next delta is based on the last one
+		var state ServerMessage
+		if err := json.Unmarshal(lastState, &state); err != nil {
+			cm.mu.Unlock()
+			return nil, err
+		}
+		if err := options.delta.update(state); err != nil {
+			cm.mu.Unlock()
+			return nil, err
+		}
+	}
+	cm.mu.Unlock()
+
+	return func() error {
+		for _, message := range messages {
+			typ, data, err := MarshalMessage(encoding, message)
+			if err != nil {
+				return err
+			}
+			if err := writeWithTimeout(conn, typ, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
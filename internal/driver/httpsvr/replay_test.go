@@ -0,0 +1,203 @@
+package httpsvr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+func TestResume(t *testing.T) {
+	handler := NewWebSocketHandler(
+		map[string]turnbased.DuelsManager{card_game_burn.GameName: turnbased.NewInMemoryDuelsManager()},
+		NewConnectionManager(),
+	)
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	dial := func(features ...string) *websocket.Conn {
+		conn, _, err := websocket.Dial(ctx, "ws"+server.URL[4:], nil)
+		if err != nil {
+			t.Fatalf("error Dial: %v", err)
+		}
+		t.Cleanup(func() { conn.Close(websocket.StatusNormalClosure, "") })
+		send(t, ctx, conn, ClientMessage{Type: MessageTypeHello, ProtocolVersion: ProtocolVersion, Features: features})
+		read(t, ctx, conn) // welcome
+		return conn
+	}
+	alice, bob := dial(FeatureDelta), dial()
+
+	send(t, ctx, alice, ClientMessage{Type: MessageTypeCreateDuel, Game: card_game_burn.GameName, Players: []string{"alice", "bob"}})
+	created := read(t, ctx, alice)
+	if created.Type != MessageTypeStateUpdate || created.Seq == 0 {
+		t.Fatalf("expected a full state with a seq, got %+v", created)
+	}
+	duelID := created.Duel.ID
+	send(t, ctx, bob, ClientMessage{Type: MessageTypeJoinDuel, DuelID: duelID, PlayerID: "bob"})
+	// bob was connected through alice's hot seat connection, so joining is not a presence event
+	if joined := read(t, ctx, bob); joined.Seq != created.Seq {
+		t.Fatalf("a joining player gets the current seq %d, got %d", created.Seq, joined.Seq)
+	}
+	lastSeq := created.Seq
+
+	turnPlayer := created.Duel.TurnPlayer
+	endTurn := func() ServerMessage {
+		t.Helper()
+		if turnPlayer == "alice" {
+			// through the REST API path, so alice's only connection is the lost one
+			err := processAction(handler.duelsManagers, handler.actionProcessors, handler.connectionMgr,
+				card_game_burn.GameName, turnbased.DuelID(duelID), "alice", endTurnAction(), nil)
+			if err != nil {
+				t.Fatalf("error processAction: %v", err)
+			}
+		} else {
+			send(t, ctx, bob, ClientMessage{Type: MessageTypeAction, Game: card_game_burn.GameName,
+				DuelID: duelID, PlayerID: turnPlayer, Action: endTurnAction()})
+		}
+		msg := read(t, ctx, bob)
+		turnPlayer = msg.Duel.TurnPlayer
+		return msg
+	}
+
+	// alice misses 3 turns
+	alice.Close(websocket.StatusGoingAway, "")
+	var missed []ServerMessage
+	for i := 0; i < 3; i++ {
+		missed = append(missed, endTurn())
+	}
+	alice = dial(FeatureDelta)
+	send(t, ctx, alice, ClientMessage{Type: MessageTypeJoinDuel, DuelID: duelID, PlayerID: "alice", LastSeq: lastSeq, RequestID: "r1"})
+	// the missed messages: alice's presence events and the states
+	var replayed []ServerMessage
+	for {
+		msg := readAny(t, ctx, alice)
+		if msg.Type == MessageTypeAck {
+			break
+		}
+		if msg.Seq != lastSeq+1 {
+			t.Fatalf("replayed message after seq %d: got %+v", lastSeq, msg)
+		}
+		lastSeq = msg.Seq
+		if msg.Type == MessageTypeStateUpdate {
+			replayed = append(replayed, msg)
+		}
+	}
+	if len(replayed) != len(missed) {
+		t.Fatalf("%d states replayed, want %d", len(replayed), len(missed))
+	}
+	for i, bobView := range missed {
+		msg := replayed[i]
+		if msg.Seq != bobView.Seq {
+			t.Errorf("replayed state %d: seq %d, want %d", i, msg.Seq, bobView.Seq)
+		}
+		if len(msg.Duel.ActionLog) != len(bobView.Duel.ActionLog) {
+			t.Errorf("replayed message %d: %d log entries, want %d", i, len(msg.Duel.ActionLog), len(bobView.Duel.ActionLog))
+		}
+		_, aliceState := splitState(t, msg)
+		_, bobState := splitState(t, bobView)
+		if jsonEqual(t, aliceState, bobState) {
+			t.Errorf("replayed message %d: alice sees bob's view of the game", i)
+		}
+	}
+
+	// the next delta is based on the last replayed state
+	last := endTurn()
+	delta := read(t, ctx, alice)
+	if delta.Type != MessageTypeStateDelta || delta.BaseVersion != missed[2].Seq || delta.Seq != last.Seq ||
+		delta.Seq != lastSeq+1 {
+		t.Fatalf("expected a delta from seq %d, got %+v", missed[2].Seq, delta)
+	}
+
+	// nothing missed: only the ack
+	send(t, ctx, alice, ClientMessage{Type: MessageTypeJoinDuel, DuelID: duelID, PlayerID: "alice", LastSeq: last.Seq, RequestID: "r2"})
+	if ack := read(t, ctx, alice); ack.Type != MessageTypeAck || ack.RequestID != "r2" {
+		t.Fatalf("expected only the ack of the join, got %+v", ack)
+	}
+	// an unknown seq gets the full state
+	send(t, ctx, alice, ClientMessage{Type: MessageTypeJoinDuel, DuelID: duelID, PlayerID: "alice", LastSeq: last.Seq + 10})
+	if full := read(t, ctx, alice); full.Type != MessageTypeStateUpdate || full.Seq != last.Seq {
+		t.Fatalf("expected the full state of seq %d, got %+v", last.Seq, full)
+	}
+}
+
+func TestReplayBuffer_Since(t *testing.T) {
+	var buffer replayBuffer
+	const current = ReplayBufferSize + 10
+	for seq := 1; seq <= current; seq++ {
+		buffer.add(replayEntry{seq: seq})
+	}
+	for _, c := range []struct {
+		lastSeq   int
+		wantFirst int // 0 if no entry
+		wantOK    bool
+	}{
+		{lastSeq: current - 1, wantFirst: current, wantOK: true},
+		{lastSeq: current, wantOK: true},
+		{lastSeq: current - ReplayBufferSize, wantFirst: current - ReplayBufferSize + 1, wantOK: true},
+		{lastSeq: current - ReplayBufferSize - 1, wantOK: false}, // its next message is not kept
+		{lastSeq: current + 1, wantOK: false},
+		{lastSeq: 0, wantOK: false},
+	} {
+		entries, ok := buffer.since(c.lastSeq, current)
+		if ok != c.wantOK {
+			t.Errorf("since(%d): ok %v, want %v", c.lastSeq, ok, c.wantOK)
+			continue
+		}
+		if c.wantFirst == 0 {
+			if len(entries) != 0 {
+				t.Errorf("since(%d): %d entries, want none", c.lastSeq, len(entries))
+			}
+			continue
+		}
+		if len(entries) != current-c.lastSeq || entries[0].seq != c.wantFirst {
+			t.Errorf("since(%d): %d entries from seq %d, want %d from seq %d",
+				c.lastSeq, len(entries), entries[0].seq, current-c.lastSeq, c.wantFirst)
+		}
+	}
+}
+
+func TestConnectionManager_ForgetEndedDuel(t *testing.T) {
+	cm := NewConnectionManager()
+	duel := card_game_burn.NewBurnDuelWithSeed([]turnbased.PlayerID{"alice", "bob"}, 1).Duel
+	duel.ID = "duel1"
+	alice, bob := newPollSubscriber(cm), newPollSubscriber(cm)
+	cm.AddConnection(alice, "alice", duel.ID)
+	cm.AddConnection(bob, "bob", duel.ID)
+	if err := cm.BroadcastStateToDuel(duel); err != nil {
+		t.Fatalf("error BroadcastStateToDuel: %v", err)
+	}
+	cm.RemoveConnection(alice)
+	if cm.seqs[duel.ID] == 0 || cm.replays[duel.ID] == nil {
+		t.Fatalf("a running duel keeps its seq and replay when a connection leaves")
+	}
+
+	duel.State = turnbased.DuelStateEnd
+	if err := cm.BroadcastStateToDuel(duel); err != nil {
+		t.Fatalf("error BroadcastStateToDuel: %v", err)
+	}
+	if cm.seqs[duel.ID] == 0 {
+		t.Fatalf("an ended duel keeps its seq while a connection watches it")
+	}
+	cm.RemoveConnection(bob)
+	if len(cm.seqs) != 0 || len(cm.replays) != 0 || len(cm.presence) != 0 || len(cm.ended) != 0 {
+		t.Errorf("expected the entries of the ended duel to be deleted, got seqs %v, replays %v, presence %v, ended %v",
+			cm.seqs, cm.replays, cm.presence, cm.ended)
+	}
+	if err := cm.BroadcastToDuel(duel.ID, ServerMessage{Type: MessageTypeError}); err != nil || len(cm.seqs) != 0 {
+		t.Errorf("a forgotten duel without connections records nothing, got %v, seqs %v", err, cm.seqs)
+	}
+	// reading the state of a forgotten duel does not bring its entries back
+	if err := cm.Resume(newPollSubscriber(cm), duel, "alice", 2); err != nil {
+		t.Fatalf("error Resume: %v", err)
+	}
+	if len(cm.seqs) != 0 || len(cm.replays) != 0 {
+		t.Errorf("expected no entries after resuming a forgotten duel, got seqs %v, replays %v", cm.seqs, cm.replays)
+	}
+}
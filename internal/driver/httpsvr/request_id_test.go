@@ -0,0 +1,100 @@
+package httpsvr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/connect_four"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+func TestRequestIDAndAck(t *testing.T) {
+	handler := NewWebSocketHandler(
+		map[string]turnbased.DuelsManager{connect_four.GameName: turnbased.NewInMemoryDuelsManager()},
+		NewConnectionManager(),
+	)
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+server.URL[4:], nil)
+	if err != nil {
+		t.Fatalf("error Dial: %v", err)
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "")
+	send := func(msg ClientMessage) {
+		data, _ := json.Marshal(msg)
+		if err := conn.Write(ctx, websocket.MessageText, data); err != nil {
+			t.Fatalf("error Write: %v", err)
+		}
+	}
+	read := func() ServerMessage {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatalf("error Read: %v", err)
+		}
+		var msg ServerMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("error Unmarshal: %v", err)
+		}
+		return msg
+	}
+
+	// a successful request is answered by its state update then an ack
+	send(ClientMessage{Type: MessageTypeCreateDuel, RequestID: "1", Game: connect_four.GameName, Players: []string{"alice", "bob"}})
+	created := read()
+	if created.Type != MessageTypeStateUpdate {
+		t.Fatalf("expected state_update, got %+v", created)
+	}
+	if ack := read(); ack.Type != MessageTypeAck || ack.RequestID != "1" {
+		t.Errorf("expected ack of request 1, got %+v", ack)
+	}
+
+	column := 3
+	action := ClientMessage{Type: MessageTypeAction, Game: connect_four.GameName,
+		DuelID: created.Duel.ID, PlayerID: "bob", Action: model.ActionData{Column: &column}}
+	for i, c := range []struct {
+		msg  ClientMessage
+		code ErrorCode
+	}{
+		{msg: ClientMessage{Type: "dance"}, code: ErrorCodeUnknownMessageType},
+		{msg: ClientMessage{Type: MessageTypeJoinDuel, DuelID: "nope", PlayerID: "alice"}, code: ErrorCodeDuelNotFound},
+		{msg: ClientMessage{Type: MessageTypeJoinDuel, DuelID: created.Duel.ID, PlayerID: "carol"}, code: ErrorCodePlayerNotInDuel},
+		{msg: ClientMessage{Type: MessageTypeCreateDuel, Game: "NOPE", Players: []string{"alice"}}, code: ErrorCodeUnknownGame},
+		{msg: action, code: ErrorCodeNotPlayerTurn},
+	} {
+		c.msg.RequestID = "e" + string(rune('0'+i))
+		send(c.msg)
+		if got := read(); got.Type != MessageTypeError || got.RequestID != c.msg.RequestID || got.Code != c.code {
+			t.Errorf("%s: expected error %s, got %+v", c.msg.RequestID, c.code, got)
+		}
+	}
+
+	// the acting connection gets the fanned out state before the ack
+	action.PlayerID, action.RequestID = "alice", "2"
+	send(action)
+	if played := read(); played.Type != MessageTypeStateUpdate || played.Duel.Turn != 2 {
+		t.Errorf("expected the state of turn 2, got %+v", played)
+	}
+	if ack := read(); ack.Type != MessageTypeAck || ack.RequestID != "2" {
+		t.Errorf("expected ack of request 2, got %+v", ack)
+	}
+
+	// without request_id there is no ack, as before
+	action.PlayerID, action.RequestID = "bob", ""
+	send(action)
+	send(ClientMessage{Type: "dance"})
+	if got := read(); got.Type != MessageTypeStateUpdate {
+		t.Errorf("expected state_update, got %+v", got)
+	}
+	if got := read(); got.Type != MessageTypeError || got.RequestID != "" || got.Code != ErrorCodeUnknownMessageType {
+		t.Errorf("expected an error without request_id, got %+v", got)
+	}
+}
@@ -0,0 +1,73 @@
+package httpsvr
+
+import (
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/core/rock_paper_scissors"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// RockPaperScissorsActionProcessor processes actions for rock-paper-scissors
+type RockPaperScissorsActionProcessor struct {
+	duelsManager  turnbased.DuelsManager
+	connectionMgr *ConnectionManager
+}
+
+// NewRockPaperScissorsActionProcessor creates a new RockPaperScissors action processor
+func NewRockPaperScissorsActionProcessor(duelsManager turnbased.DuelsManager) *RockPaperScissorsActionProcessor {
+	// Note: connectionMgr will be set by WebSocketHandler after creation
+	return &RockPaperScissorsActionProcessor{
+		duelsManager: duelsManager,
+	}
+}
+
+// SetConnectionManager sets the connection manager (called by WebSocketHandler)
+func (p *RockPaperScissorsActionProcessor) SetConnectionManager(cm *ConnectionManager) {
+	p.connectionMgr = cm
+}
+
+// CreateDuel creates a new rock-paper-scissors duel, best of DefaultBestOf rounds
+func (p *RockPaperScissorsActionProcessor) CreateDuel(game string, players []turnbased.PlayerID) (*turnbased.Duel, error) {
+	rpsDuel, err := rock_paper_scissors.NewRockPaperScissorsDuel(players, rock_paper_scissors.DefaultBestOf)
+	if err != nil {
+		return nil, err
+	}
+	duel := rpsDuel.Duel
+	duel.Game = rpsDuel
+	return p.duelsManager.CreateDuel(duel), nil
+}
+
+// ProcessAction implements the three-stage flow: Message In → Persist → Fanout
+func (p *RockPaperScissorsActionProcessor) ProcessAction(duelID turnbased.DuelID, playerID turnbased.PlayerID, actionData model.ActionData) error {
+	if actionData.Throw == nil {
+		return fmt.Errorf("failed to parse action: throw required")
+	}
+	throw, err := rock_paper_scissors.ParseThrow(*actionData.Throw)
+	if err != nil {
+		return fmt.Errorf("failed to parse action: %w", err)
+	}
+	action := rock_paper_scissors.ActionThrow{Throw: throw}
+
+	duel := p.duelsManager.GetDuel(duelID)
+	if duel == nil {
+		return fmt.Errorf("duel not found: %s", duelID)
+	}
+	rpsDuel, ok := duel.Game.(*rock_paper_scissors.RockPaperScissorsDuel)
+	if !ok {
+		return fmt.Errorf("duel is not a rock paper scissors duel")
+	}
+	if err := rpsDuel.HandleActionWithPlayer(action, playerID); err != nil {
+		return err
+	}
+
+	updatedDuel, err := p.duelsManager.UpdateDuel(duel)
+	if err != nil {
+		return fmt.Errorf("failed to persist duel: %w", err)
+	}
+
+	if p.connectionMgr == nil {
+		return fmt.Errorf("connection manager not set")
+	}
+	return p.connectionMgr.BroadcastStateToDuel(updatedDuel)
+}
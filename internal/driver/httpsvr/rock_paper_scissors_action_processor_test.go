@@ -0,0 +1,50 @@
+package httpsvr
+
+import (
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/rock_paper_scissors"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// TestRockPaperScissorsOverWebSocket checks that a throw stays hidden from the opponent
+// until both players have thrown
+func TestRockPaperScissorsOverWebSocket(t *testing.T) {
+	server := newGameServer(t, rock_paper_scissors.GameName)
+	var duelID string
+	throw := func(conn *websocket.Conn, player string, throw string) {
+		server.send(conn, ClientMessage{Type: MessageTypeAction, Game: rock_paper_scissors.GameName, DuelID: duelID,
+			PlayerID: player, Action: model.ActionData{Throw: &throw}})
+	}
+	var aliceView, bobView model.RockPaperScissorsGameState
+
+	alice := server.dial()
+	server.send(alice, ClientMessage{Type: MessageTypeCreateDuel, Game: rock_paper_scissors.GameName, Players: []string{"alice", "bob"}})
+	created := server.readState(alice, &aliceView)
+	duelID = created.Duel.ID
+
+	bob := server.dial()
+	server.send(bob, ClientMessage{Type: MessageTypeJoinDuel, DuelID: created.Duel.ID, PlayerID: "bob"})
+	server.readState(bob, &bobView)
+
+	throw(alice, "alice", "rock")
+	server.readState(alice, &aliceView)
+	server.readState(bob, &bobView)
+	if aliceView.MyThrow != string(rock_paper_scissors.Rock) {
+		t.Errorf("Alice should see the throw of alice, got %q", aliceView.MyThrow)
+	}
+	if bobView.MyThrow != "" || !bobView.Thrown["alice"] {
+		t.Errorf("Bob should only know that Alice has thrown, got %+v", bobView)
+	}
+
+	throw(bob, "bob", "paper")
+	server.readState(alice, &aliceView)
+	msg := server.readState(bob, &bobView)
+	if len(bobView.Rounds) != 1 || bobView.Rounds[0].Winner != "bob" || bobView.Rounds[0].Throws["alice"] != "ROCK" {
+		t.Errorf("Expected the revealed round won by bob, got %+v", bobView.Rounds)
+	}
+	if msg.Duel.Turn != 2 || msg.Duel.TurnPlayer != "" {
+		t.Errorf("Expected round 2 without turn player, got %d %q", msg.Duel.Turn, msg.Duel.TurnPlayer)
+	}
+}
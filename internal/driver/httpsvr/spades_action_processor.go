@@ -0,0 +1,69 @@
+package httpsvr
+
+import (
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/core/spades"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// SpadesActionProcessor processes actions for Spades
+type SpadesActionProcessor struct {
+	duelsManager  turnbased.DuelsManager
+	connectionMgr *ConnectionManager
+}
+
+// NewSpadesActionProcessor creates a new Spades action processor
+func NewSpadesActionProcessor(duelsManager turnbased.DuelsManager) *SpadesActionProcessor {
+	// Note: connectionMgr will be set by WebSocketHandler after creation
+	return &SpadesActionProcessor{
+		duelsManager: duelsManager,
+	}
+}
+
+// SetConnectionManager sets the connection manager (called by WebSocketHandler)
+func (p *SpadesActionProcessor) SetConnectionManager(cm *ConnectionManager) {
+	p.connectionMgr = cm
+}
+
+// CreateDuel creates a new Spades duel, players[0] and players[2] are partners
+func (p *SpadesActionProcessor) CreateDuel(game string, players []turnbased.PlayerID) (*turnbased.Duel, error) {
+	spadesDuel, err := spades.NewSpadesDuel(players, spades.DefaultHandsToPlay)
+	if err != nil {
+		return nil, err
+	}
+	duel := spadesDuel.Duel
+	duel.Game = spadesDuel
+	return p.duelsManager.CreateDuel(duel), nil
+}
+
+// ProcessAction implements the three-stage flow: Message In → Persist → Fanout
+func (p *SpadesActionProcessor) ProcessAction(duelID turnbased.DuelID, playerID turnbased.PlayerID, actionData model.ActionData) error {
+	if actionData.CardID == nil {
+		return fmt.Errorf("failed to parse action: card_id required")
+	}
+	action := spades.ActionPlayCard{CardID: *actionData.CardID}
+
+	duel := p.duelsManager.GetDuel(duelID)
+	if duel == nil {
+		return fmt.Errorf("duel not found: %s", duelID)
+	}
+	spadesDuel, ok := duel.Game.(*spades.SpadesDuel)
+	if !ok {
+		return fmt.Errorf("duel is not a Spades duel")
+	}
+	if err := spadesDuel.HandleActionWithPlayer(action, playerID); err != nil {
+		return err
+	}
+
+	updatedDuel, err := p.duelsManager.UpdateDuel(duel)
+	if err != nil {
+		return fmt.Errorf("failed to persist duel: %w", err)
+	}
+
+	if p.connectionMgr == nil {
+		return fmt.Errorf("connection manager not set")
+	}
+	return p.connectionMgr.BroadcastStateToDuel(updatedDuel)
+}
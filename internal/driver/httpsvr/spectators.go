@@ -0,0 +1,32 @@
+package httpsvr
+
+import (
+	"log"
+
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// spectatorCountLocked returns the number of spectators of the duel, see ConnectionManager.AddSpectator
+func (cm *ConnectionManager) spectatorCountLocked(duelID turnbased.DuelID) int {
+	count := 0
+	for conn := range cm.spectators {
+		if cm.connToDuel[conn] == duelID {
+			count++
+		}
+	}
+	return count
+}
+
+// spectatorsChangedLocked returns the function broadcasting the number of spectators to the duel,
+// to be called after unlocking. The message is encoded for the connections of the duel at the time of the call.
+func (cm *ConnectionManager) spectatorsChangedLocked(duelID turnbased.DuelID) func() {
+	write, err := cm.broadcastLocked(duelID, ServerMessage{
+		Type:       MessageTypeSpectators,
+		Spectators: &SpectatorsEvent{DuelID: string(duelID), Count: cm.spectatorCountLocked(duelID)},
+	})
+	if err != nil {
+		log.Printf("Error broadcasting spectators: %v", err)
+		return func() {}
+	}
+	return write
+}
@@ -0,0 +1,184 @@
+package httpsvr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+func TestSpectate(t *testing.T) {
+	handler := NewWebSocketHandler(
+		map[string]turnbased.DuelsManager{card_game_burn.GameName: turnbased.NewInMemoryDuelsManager()},
+		NewConnectionManager(),
+	)
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	dial := func() *websocket.Conn {
+		conn, _, err := websocket.Dial(ctx, "ws"+server.URL[4:], nil)
+		if err != nil {
+			t.Fatalf("error Dial: %v", err)
+		}
+		t.Cleanup(func() { conn.CloseNow() })
+		return conn
+	}
+	burnState := func(msg ServerMessage) model.BurnGameState {
+		t.Helper()
+		data, _ := json.Marshal(msg.GameState)
+		var state model.BurnGameState
+		if err := json.Unmarshal(data, &state); err != nil {
+			t.Fatalf("error Unmarshal game state: %v", err)
+		}
+		return state
+	}
+	checkHidden := func(msg ServerMessage) {
+		t.Helper()
+		if msg.Type != MessageTypeStateUpdate {
+			t.Fatalf("expected state_update, got %+v", msg)
+		}
+		for pid, player := range burnState(msg).Players {
+			if len(player.Hand) != 0 || player.HandSize == 0 {
+				t.Errorf("a spectator sees the hand of %s: %+v", pid, player)
+			}
+		}
+	}
+	readSpectators := func(conn *websocket.Conn, want int) {
+		t.Helper()
+		for {
+			msg := readAny(t, ctx, conn)
+			if msg.Type == MessageTypePresence {
+				continue
+			}
+			if msg.Type != MessageTypeSpectators || msg.Spectators.Count != want {
+				t.Fatalf("expected %d spectators, got %+v", want, msg)
+			}
+			return
+		}
+	}
+
+	alice, bob := dial(), dial()
+	send(t, ctx, alice, ClientMessage{Type: MessageTypeCreateDuel, Game: card_game_burn.GameName, Players: []string{"alice", "bob"}})
+	created := read(t, ctx, alice)
+	if !*created.Duel.Settings.AllowSpectators {
+		t.Fatalf("spectators are allowed by default, got %+v", created.Duel.Settings)
+	}
+	duelID := created.Duel.ID
+	send(t, ctx, bob, ClientMessage{Type: MessageTypeJoinDuel, DuelID: duelID, PlayerID: "bob"})
+	read(t, ctx, bob)
+
+	carol := dial()
+	send(t, ctx, carol, ClientMessage{Type: MessageTypeSpectate, DuelID: duelID})
+	readSpectators(alice, 1)
+	readSpectators(bob, 1)
+	watched := read(t, ctx, carol)
+	checkHidden(watched)
+	if watched.Duel.Spectators != 1 {
+		t.Errorf("spectators in the state: %d, want 1", watched.Duel.Spectators)
+	}
+
+	// a spectator cannot act, even for the turn player
+	turnPlayer := created.Duel.TurnPlayer
+	send(t, ctx, carol, ClientMessage{Type: MessageTypeAction, Game: card_game_burn.GameName, DuelID: duelID,
+		PlayerID: turnPlayer, Action: endTurnAction(), RequestID: "r1"})
+	if msg := read(t, ctx, carol); msg.Type != MessageTypeError || msg.Code != ErrorCodeSpectatorCannotAct || msg.RequestID != "r1" {
+		t.Fatalf("expected a spectator error, got %+v", msg)
+	}
+
+	// the spectator follows the duel, hands stay hidden
+	players := map[string]*websocket.Conn{"alice": alice, "bob": bob}
+	send(t, ctx, players[turnPlayer], ClientMessage{Type: MessageTypeAction, Game: card_game_burn.GameName, DuelID: duelID,
+		PlayerID: turnPlayer, Action: endTurnAction()})
+	if state := read(t, ctx, alice); state.Duel.Spectators != 1 {
+		t.Errorf("spectators in the state of alice: %d, want 1", state.Duel.Spectators)
+	}
+	next := read(t, ctx, carol)
+	checkHidden(next)
+	if next.Duel.TurnPlayer == turnPlayer {
+		t.Errorf("expected the turn to pass, got %+v", next.Duel)
+	}
+
+	carol.Close(websocket.StatusNormalClosure, "")
+	readSpectators(alice, 0)
+
+	// a duel can refuse spectators
+	allow := false
+	send(t, ctx, alice, ClientMessage{Type: MessageTypeCreateDuel, Game: card_game_burn.GameName,
+		Players: []string{"alice", "bob"}, Settings: &model.DuelSettings{AllowSpectators: &allow}})
+	private := read(t, ctx, alice)
+	dave := dial()
+	send(t, ctx, dave, ClientMessage{Type: MessageTypeSpectate, DuelID: private.Duel.ID})
+	if msg := read(t, ctx, dave); msg.Type != MessageTypeError || msg.Code != ErrorCodeSpectatorsNotAllowed {
+		t.Fatalf("expected spectators not allowed, got %+v", msg)
+	}
+}
+
+func TestAction_ActorIsTheConnectionPlayer(t *testing.T) {
+	duelsManagers := map[string]turnbased.DuelsManager{card_game_burn.GameName: turnbased.NewInMemoryDuelsManager()}
+	handler := NewWebSocketHandler(duelsManagers, NewConnectionManager())
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	dial := func() *websocket.Conn {
+		conn, _, err := websocket.Dial(ctx, "ws"+server.URL[4:], nil)
+		if err != nil {
+			t.Fatalf("error Dial: %v", err)
+		}
+		t.Cleanup(func() { conn.CloseNow() })
+		return conn
+	}
+
+	alice, bob, carol, dave := dial(), dial(), dial(), dial()
+	send(t, ctx, alice, ClientMessage{Type: MessageTypeCreateDuel, Game: card_game_burn.GameName, Players: []string{"alice", "bob"}})
+	created := read(t, ctx, alice)
+	duelID := created.Duel.ID
+	send(t, ctx, bob, ClientMessage{Type: MessageTypeJoinDuel, DuelID: duelID, PlayerID: "bob"})
+	read(t, ctx, bob)
+	send(t, ctx, carol, ClientMessage{Type: MessageTypeSpectate, DuelID: duelID})
+	read(t, ctx, carol)
+
+	// each connection sends the turn player's action, only the turn player's connection may
+	turnPlayer := created.Duel.TurnPlayer
+	other := map[string]*websocket.Conn{"alice": bob, "bob": alice}[turnPlayer]
+	for _, c := range []struct {
+		name     string
+		conn     *websocket.Conn
+		wantCode ErrorCode
+	}{
+		{name: "the other player", conn: other, wantCode: ErrorCodePlayerNotInDuel},
+		{name: "a spectator", conn: carol, wantCode: ErrorCodeSpectatorCannotAct},
+		{name: "a connection that did not join", conn: dave, wantCode: ErrorCodePlayerNotInDuel},
+	} {
+		send(t, ctx, c.conn, ClientMessage{Type: MessageTypeAction, Game: card_game_burn.GameName, DuelID: duelID,
+			PlayerID: turnPlayer, Action: endTurnAction(), RequestID: "r1"})
+		for {
+			msg := read(t, ctx, c.conn)
+			if msg.Type == MessageTypeSpectators {
+				continue
+			}
+			if msg.Type != MessageTypeError || msg.Code != c.wantCode || msg.RequestID != "r1" {
+				t.Fatalf("%s acting for %s: expected error %s, got %+v", c.name, turnPlayer, c.wantCode, msg)
+			}
+			break
+		}
+	}
+	manager := duelsManagers[card_game_burn.GameName]
+	unlock := manager.LockDuel(turnbased.DuelID(duelID))
+	duel := manager.GetDuel(turnbased.DuelID(duelID))
+	if duel.TurnPlayer != turnbased.PlayerID(turnPlayer) || len(duel.ActionLog) != len(created.Duel.ActionLog) {
+		t.Errorf("expected the rejected actions to leave the duel unchanged, got turn %s, %d log entries",
+			duel.TurnPlayer, len(duel.ActionLog))
+	}
+	unlock()
+}
@@ -0,0 +1,311 @@
+package httpsvr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// Server-Sent Events and long-poll transports, for clients behind proxies that break WebSocket.
+// They receive the same JSON ServerMessage as WebSocket clients through ConnectionManager,
+// actions are sent to the REST action endpoint.
+
+// Timings of the HTTP transports
+const (
+	// SSEKeepAliveInterval is the interval of comment lines that keep proxies from closing an idle stream
+	SSEKeepAliveInterval = 15 * time.Second
+	// DefaultPollWait is how long a poll waits for a message, MaxPollWait bounds the wait query parameter
+	DefaultPollWait = 25 * time.Second
+	MaxPollWait     = 60 * time.Second
+	// PollIdleTimeout is how long a long-poll subscriber is kept without being polled,
+	// after it the player is disconnected
+	PollIdleTimeout = time.Minute
+	// PollQueueSize is the number of messages kept for a long-poll subscriber,
+	// a client falling further behind gets the full state again
+	PollQueueSize = 64
+)
+
+// errSubscriberGone is returned by writes to a subscriber whose client left
+var errSubscriberGone = errors.New("subscriber gone")
+
+// sseSubscriber writes messages as Server-Sent Events, one JSON ServerMessage per event,
+// the event ID is the message seq so that a reconnecting EventSource resumes with Last-Event-ID
+type sseSubscriber struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher *http.ResponseController
+	closed  bool // set when the request ends, the writer must not be used after that
+}
+
+func (s *sseSubscriber) Write(ctx context.Context, typ websocket.MessageType, data []byte) error {
+	if typ != websocket.MessageText {
+		return fmt.Errorf("server-sent events can only carry text")
+	}
+	var message struct {
+		Seq int `json:"seq"`
+	}
+	_ = json.Unmarshal(data, &message)
+	event := "data: " + string(data) + "\n\n"
+	if message.Seq > 0 {
+		event = "id: " + strconv.Itoa(message.Seq) + "\n" + event
+	}
+	return s.write(ctx, event)
+}
+
+func (s *sseSubscriber) write(ctx context.Context, text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errSubscriberGone
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		// a client that does not read fails the write instead of blocking it
+		_ = s.flusher.SetWriteDeadline(deadline)
+		defer s.flusher.SetWriteDeadline(time.Time{})
+	}
+	if _, err := s.w.Write([]byte(text)); err != nil {
+		return err
+	}
+	return s.flusher.Flush()
+}
+
+func (s *sseSubscriber) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+}
+
+// handleEvents serves GET /api/duel/{duelID}/events?player_id=alice: the current state,
+// then every message of the duel as seen by the player, until the client disconnects.
+// A client resuming with the Last-Event-ID header (or the last_seq query parameter)
+// gets the messages it missed instead of the current state, see ConnectionManager.Resume.
+func handleEvents(duelsManagers map[string]turnbased.DuelsManager, connectionMgr *ConnectionManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+			return
+		}
+		duel, viewer, unlock, status, err := getDuelForPlayer(duelsManagers, r)
+		if err != nil {
+			writeAPIError(w, status, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no") // tell nginx-like proxies not to buffer the stream
+		w.WriteHeader(http.StatusOK)
+
+		// the state is read while the duel is locked and written after unlocking,
+		// the messages of the next actions wait for it in the held fanout
+		release := connectionMgr.HoldFanout(duel.ID)
+		subscriber := &sseSubscriber{w: w, flusher: http.NewResponseController(w)}
+		defer subscriber.close()
+		connectionMgr.AddConnection(subscriber, viewer, duel.ID)
+		defer connectionMgr.RemoveConnection(subscriber)
+		lastSeq := r.Header.Get("Last-Event-ID")
+		if lastSeq == "" {
+			lastSeq = r.URL.Query().Get("last_seq")
+		}
+		var send func() error
+		if seq, _ := strconv.Atoi(lastSeq); seq > 0 {
+			send, err = connectionMgr.PrepareResume(subscriber, duel, viewer, seq)
+		} else {
+			send, err = connectionMgr.PrepareState(subscriber, duel, viewer)
+		}
+		unlock()
+		if err == nil {
+			err = send()
+		}
+		release()
+		if err != nil {
+			log.Printf("Error sending state to SSE subscriber: %v", err)
+			return
+		}
+
+		keepAlive := time.NewTicker(SSEKeepAliveInterval)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-keepAlive.C:
+				ctx, cancel := context.WithTimeout(context.Background(), WriteTimeout)
+				err := subscriber.write(ctx, ": keep-alive\n\n")
+				cancel()
+				if err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// PollResponse is the response of GET /api/duel/{duelID}/poll
+type PollResponse struct {
+	Messages []json.RawMessage `json:"messages"` // ServerMessage, oldest first
+	// Cursor is the value of the cursor query parameter of the next poll
+	Cursor int `json:"cursor"`
+}
+
+// pollSubscriber queues messages until the client polls them
+type pollSubscriber struct {
+	mu       sync.Mutex
+	messages []json.RawMessage
+	cursor   int           // the number of messages queued since the subscriber was created
+	notify   chan struct{} // closed and replaced when a message is queued
+	idle     *time.Timer   // removes the subscriber if it is not polled, reset by each poll
+}
+
+func newPollSubscriber(connectionMgr *ConnectionManager) *pollSubscriber {
+	p := &pollSubscriber{notify: make(chan struct{})}
+	p.idle = time.AfterFunc(PollIdleTimeout, func() { connectionMgr.RemoveConnection(p) })
+	return p
+}
+
+func (p *pollSubscriber) Write(ctx context.Context, typ websocket.MessageType, data []byte) error {
+	if typ != websocket.MessageText {
+		return fmt.Errorf("long-poll can only carry text")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, json.RawMessage(data))
+	if len(p.messages) > PollQueueSize {
+		p.messages = p.messages[len(p.messages)-PollQueueSize:]
+	}
+	p.cursor++
+	close(p.notify)
+	p.notify = make(chan struct{})
+	return nil
+}
+
+// poll returns the messages after the cursor, ok is false if some of them were dropped from the queue.
+// The subscriber is kept for PollIdleTimeout after the wait of the poll.
+func (p *pollSubscriber) poll(cursor int, wait time.Duration) (messages []json.RawMessage, next int, notify <-chan struct{}, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.idle.Reset(wait + PollIdleTimeout)
+	if cursor > p.cursor {
+		return nil, p.cursor, p.notify, false
+	}
+	missed := p.cursor - cursor
+	if missed > len(p.messages) {
+		return nil, p.cursor, p.notify, false
+	}
+	return append([]json.RawMessage{}, p.messages[len(p.messages)-missed:]...), p.cursor, p.notify, true
+}
+
+// pollSubscribers keeps the long-poll subscriber of each player of each duel between polls
+type pollSubscribers struct {
+	mu          sync.Mutex
+	subscribers map[pollKey]*pollSubscriber
+}
+
+type pollKey struct {
+	duelID turnbased.DuelID
+	player turnbased.PlayerID
+}
+
+// get returns the subscriber of the player, a new one if there is none or if the connection manager
+// removed it (idle, or replaced by another connection of the player)
+func (ps *pollSubscribers) get(connectionMgr *ConnectionManager, duelID turnbased.DuelID, player turnbased.PlayerID) (
+	subscriber *pollSubscriber, created bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	key := pollKey{duelID: duelID, player: player}
+	if subscriber, ok := ps.subscribers[key]; ok {
+		if _, _, registered := connectionMgr.ConnectionPlayer(subscriber); registered {
+			return subscriber, false
+		}
+	}
+	// forget subscribers removed by the connection manager
+	for k, s := range ps.subscribers {
+		if _, _, registered := connectionMgr.ConnectionPlayer(s); !registered {
+			delete(ps.subscribers, k)
+		}
+	}
+	subscriber = newPollSubscriber(connectionMgr)
+	ps.subscribers[key] = subscriber
+	connectionMgr.AddConnection(subscriber, player, duelID)
+	return subscriber, true
+}
+
+// handlePoll serves GET /api/duel/{duelID}/poll?player_id=alice&cursor=0&wait=25:
+// the messages of the duel after the cursor, waiting up to wait seconds for one.
+// The first poll (cursor 0), or a poll that fell too far behind, gets the current full state.
+func handlePoll(duelsManagers map[string]turnbased.DuelsManager, connectionMgr *ConnectionManager) http.HandlerFunc {
+	subscribers := &pollSubscribers{subscribers: make(map[pollKey]*pollSubscriber)}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+			return
+		}
+		query := r.URL.Query()
+		cursor, wait := 0, DefaultPollWait
+		if value := query.Get("cursor"); value != "" {
+			var err error
+			if cursor, err = strconv.Atoi(value); err != nil || cursor < 0 {
+				writeAPIError(w, http.StatusBadRequest, fmt.Errorf("cursor must be a non-negative integer, got %q", value))
+				return
+			}
+		}
+		if value := query.Get("wait"); value != "" {
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds < 0 {
+				writeAPIError(w, http.StatusBadRequest, fmt.Errorf("wait must be a non-negative integer, got %q", value))
+				return
+			}
+			wait = min(time.Duration(seconds)*time.Second, MaxPollWait)
+		}
+		duel, viewer, unlock, status, err := getDuelForPlayer(duelsManagers, r)
+		if err != nil {
+			writeAPIError(w, status, err)
+			return
+		}
+
+		subscriber, created := subscribers.get(connectionMgr, duel.ID, viewer)
+		messages, next, notify, ok := subscriber.poll(cursor, wait)
+		fullState := created || cursor == 0 || !ok
+		if fullState {
+			// start from the full state, queued after the current cursor
+			if err = connectionMgr.SendStateTo(subscriber, duel, viewer); err == nil {
+				messages, next, _, _ = subscriber.poll(next, 0)
+			}
+		}
+		unlock()
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if !fullState && len(messages) == 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-notify:
+				messages, next, _, _ = subscriber.poll(cursor, 0)
+			case <-timer.C:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(PollResponse{Messages: messages, Cursor: next})
+	}
+}
+
+// getDuelForPlayer is getDuelForViewer where player_id is required
+func getDuelForPlayer(duelsManagers map[string]turnbased.DuelsManager, r *http.Request) (
+	*turnbased.Duel, turnbased.PlayerID, func(), int, error) {
+	if r.URL.Query().Get("player_id") == "" {
+		return nil, "", nil, http.StatusBadRequest, errorWithCode(ErrorCodeBadRequest, "player_id required")
+	}
+	return getDuelForViewer(duelsManagers, r)
+}
@@ -0,0 +1,224 @@
+package httpsvr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/daominah/turn_based_game/internal/core/connect_four"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// TestStreams plays over REST, one player watching with SSE and the other with long-poll
+func TestStreams(t *testing.T) {
+	duelsManagers := map[string]turnbased.DuelsManager{connect_four.GameName: turnbased.NewInMemoryDuelsManager()}
+	connectionMgr := NewConnectionManager()
+	wsHandler := NewWebSocketHandler(duelsManagers, connectionMgr)
+	server := httptest.NewServer(NewHandlerAPI(duelsManagers, wsHandler.ActionProcessors(), connectionMgr))
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	duel, err := wsHandler.ActionProcessors()[connect_four.GameName].CreateDuel(
+		connect_four.GameName, []turnbased.PlayerID{"alice", "bob"})
+	if err != nil {
+		t.Fatalf("error CreateDuel: %v", err)
+	}
+	duelPath := server.URL + "/api/duel/" + string(duel.ID)
+	act := func(player string, column int) {
+		data, _ := json.Marshal(ActionRequest{PlayerID: player, Action: model.ActionData{Column: &column}})
+		resp, err := http.Post(duelPath+"/action", "application/json", bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("error Post: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("action of %s: status %d", player, resp.StatusCode)
+		}
+	}
+	poll := func(query string) PollResponse {
+		resp, err := http.Get(duelPath + "/poll?player_id=alice&" + query)
+		if err != nil {
+			t.Fatalf("error Get: %v", err)
+		}
+		defer resp.Body.Close()
+		var page PollResponse
+		if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+			t.Fatalf("error Decode: %v", err)
+		}
+		return page
+	}
+	turnOf := func(data []byte) int {
+		var msg ServerMessage
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type != MessageTypeStateUpdate {
+			t.Fatalf("expected a state_update, got %s %v", data, err)
+		}
+		return msg.Duel.Turn
+	}
+
+	// bob watches with SSE
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, duelPath+"/events?player_id=bob", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("error Get events: %v", err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("events Content-Type %q", got)
+	}
+	events := bufio.NewScanner(resp.Body)
+	lastEventID := ""
+	// nextEvent returns the data of the next event that is not a presence event,
+	// the event ID must be the message seq
+	nextEvent := func() []byte {
+		for events.Scan() {
+			if id, ok := strings.CutPrefix(events.Text(), "id: "); ok {
+				lastEventID = id
+			}
+			data, ok := strings.CutPrefix(events.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var msg ServerMessage
+			if err := json.Unmarshal([]byte(data), &msg); err != nil || strconv.Itoa(msg.Seq) != lastEventID {
+				t.Fatalf("event id %q, data %s: %v", lastEventID, data, err)
+			}
+			if msg.Type != MessageTypePresence {
+				return []byte(data)
+			}
+		}
+		t.Fatalf("events ended: %v", events.Err())
+		return nil
+	}
+	if turn := turnOf(nextEvent()); turn != 1 {
+		t.Errorf("first event: turn %d, want the current state of turn 1", turn)
+	}
+
+	// alice polls, the first poll is the current state
+	first := poll("cursor=0")
+	if len(first.Messages) != 1 || turnOf(first.Messages[0]) != 1 {
+		t.Fatalf("unexpected first poll: %+v", first)
+	}
+	if empty := poll("wait=0&cursor=" + strconv.Itoa(first.Cursor)); len(empty.Messages) != 0 || empty.Cursor != first.Cursor {
+		t.Errorf("expected no new message, got %+v", empty)
+	}
+	polled := make(chan PollResponse, 1)
+	go func() { polled <- poll("wait=5&cursor=" + strconv.Itoa(first.Cursor)) }()
+	time.Sleep(50 * time.Millisecond) // let the poll wait, the cursor makes it pass even if it did not
+
+	act("alice", 3)
+	if turn := turnOf(nextEvent()); turn != 2 {
+		t.Errorf("SSE: turn %d, want 2", turn)
+	}
+	second := <-polled
+	if len(second.Messages) != 1 || turnOf(second.Messages[0]) != 2 || second.Cursor != first.Cursor+1 {
+		t.Errorf("unexpected waiting poll: %+v", second)
+	}
+	resumeID := lastEventID
+	act("bob", 4)
+	if turn := turnOf(nextEvent()); turn != 3 {
+		t.Errorf("SSE: turn %d, want 3", turn)
+	}
+
+	// an EventSource reconnecting with Last-Event-ID gets the missed event
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, duelPath+"/events?player_id=bob", nil)
+	req.Header.Set("Last-Event-ID", resumeID)
+	resumed, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("error Get events: %v", err)
+	}
+	defer resumed.Body.Close()
+	events = bufio.NewScanner(resumed.Body)
+	if turn := turnOf(nextEvent()); turn != 3 {
+		t.Errorf("resumed SSE: turn %d, want the missed event of turn 3", turn)
+	}
+	if third := poll("cursor=" + strconv.Itoa(second.Cursor)); len(third.Messages) != 1 || turnOf(third.Messages[0]) != 3 {
+		t.Errorf("unexpected poll: %+v", third)
+	}
+
+	for _, path := range []string{"/events", "/poll", "/poll?player_id=carol", "/poll?player_id=alice&cursor=x"} {
+		resp, err := http.Get(duelPath + path)
+		if err != nil {
+			t.Fatalf("error Get: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", path, resp.StatusCode)
+		}
+	}
+}
+
+// stuckWriter is the http.ResponseWriter of an SSE client that does not read:
+// its first write blocks until unblock is closed
+type stuckWriter struct {
+	header  http.Header
+	once    sync.Once
+	writing chan struct{} // closed by the first write
+	unblock chan struct{}
+}
+
+func (w *stuckWriter) Header() http.Header { return w.header }
+func (w *stuckWriter) WriteHeader(int)     {}
+func (w *stuckWriter) Flush()              {}
+
+func (w *stuckWriter) Write(data []byte) (int, error) {
+	w.once.Do(func() { close(w.writing) })
+	<-w.unblock
+	return len(data), nil
+}
+
+// TestStreams_StuckSubscriber checks that an SSE client that does not read its first state
+// does not block the actions on the duel
+func TestStreams_StuckSubscriber(t *testing.T) {
+	duelsManagers := map[string]turnbased.DuelsManager{connect_four.GameName: turnbased.NewInMemoryDuelsManager()}
+	connectionMgr := NewConnectionManager()
+	wsHandler := NewWebSocketHandler(duelsManagers, connectionMgr)
+	handler := NewHandlerAPI(duelsManagers, wsHandler.ActionProcessors(), connectionMgr)
+	duel, err := wsHandler.ActionProcessors()[connect_four.GameName].CreateDuel(
+		connect_four.GameName, []turnbased.PlayerID{"alice", "bob"})
+	if err != nil {
+		t.Fatalf("error CreateDuel: %v", err)
+	}
+	duelPath := "/api/duel/" + string(duel.ID)
+
+	stuck := &stuckWriter{header: http.Header{}, writing: make(chan struct{}), unblock: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		req := httptest.NewRequestWithContext(ctx, http.MethodGet, duelPath+"/events?player_id=bob", nil)
+		handler.ServeHTTP(stuck, req)
+	}()
+	defer func() {
+		close(stuck.unblock)
+		cancel()
+		<-served
+	}()
+	<-stuck.writing
+
+	acted := make(chan int, 1)
+	go func() {
+		column := 3
+		data, _ := json.Marshal(ActionRequest{PlayerID: "alice", Action: model.ActionData{Column: &column}})
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, duelPath+"/action", bytes.NewReader(data)))
+		acted <- w.Code
+	}()
+	select {
+	case code := <-acted:
+		if code != http.StatusOK {
+			t.Errorf("action: status %d", code)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("the action waits for the stuck SSE client")
+	}
+}
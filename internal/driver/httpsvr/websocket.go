@@ -3,22 +3,31 @@ package httpsvr
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/coder/websocket"
-	"github.com/daominah/turn_based_game/internal/core/card_game_burn"
 	"github.com/daominah/turn_based_game/internal/core/turnbased"
 	"github.com/daominah/turn_based_game/internal/model"
 )
 
+// Heartbeats of WebSocket connections: the server pings each connection every HeartbeatInterval
+// and closes it if the pong does not come within HeartbeatTimeout (browsers and WebSocket
+// libraries answer pings automatically), so a dead peer is noticed without waiting for a failed write
+const (
+	HeartbeatInterval = 20 * time.Second
+	HeartbeatTimeout  = 10 * time.Second
+)
+
 // WebSocketHandler handles WebSocket connections and game actions
 type WebSocketHandler struct {
 	duelsManagers    map[string]turnbased.DuelsManager
 	connectionMgr    *ConnectionManager
 	actionProcessors map[string]ActionProcessor
+
+	heartbeatInterval time.Duration
+	heartbeatTimeout  time.Duration
 }
 
 // ActionProcessor processes actions for a specific game
@@ -27,25 +36,34 @@ type ActionProcessor interface {
 	CreateDuel(game string, players []turnbased.PlayerID) (*turnbased.Duel, error)
 }
 
-// NewWebSocketHandler creates a new WebSocket handler
+// BotDuelCreator is optionally implemented by an ActionProcessor of a game with built-in bots
+type BotDuelCreator interface {
+	// CreateDuelWithBots creates a duel where some players (player ID -> bot kind)
+	// are played by the server, bots act automatically when they become the turn player
+	CreateDuelWithBots(game string, players []turnbased.PlayerID, bots map[turnbased.PlayerID]string) (*turnbased.Duel, error)
+}
+
+// NewWebSocketHandler creates a new WebSocket handler,
+// it applies the disconnect rule of the duels of connectionMgr (see turnbased.Settings)
 func NewWebSocketHandler(
 	duelsManagers map[string]turnbased.DuelsManager,
 	connectionMgr *ConnectionManager,
 ) *WebSocketHandler {
-	handler := &WebSocketHandler{
+	newDisconnectWatchdog(duelsManagers, connectionMgr)
+	return &WebSocketHandler{
 		duelsManagers:    duelsManagers,
 		connectionMgr:    connectionMgr,
-		actionProcessors: make(map[string]ActionProcessor),
-	}
+		actionProcessors: NewActionProcessors(duelsManagers, connectionMgr),
 
-	// Register action processors for each game
-	if _, ok := duelsManagers[card_game_burn.GameName]; ok {
-		processor := NewBurnActionProcessor(duelsManagers[card_game_burn.GameName])
-		processor.SetConnectionManager(connectionMgr)
-		handler.actionProcessors[card_game_burn.GameName] = processor
+		heartbeatInterval: HeartbeatInterval,
+		heartbeatTimeout:  HeartbeatTimeout,
 	}
+}
 
-	return handler
+// ActionProcessors returns the action processor of each game (key is game name),
+// to be shared with other handlers so that their state changes are fanned out the same way
+func (h *WebSocketHandler) ActionProcessors() map[string]ActionProcessor {
+	return h.actionProcessors
 }
 
 // HandleWebSocket handles WebSocket connections
@@ -59,99 +77,155 @@ func (h *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	defer conn.Close(websocket.StatusInternalError, "connection closed")
+	defer h.connectionMgr.RemoveConnection(conn)
 
 	connectDuration := time.Since(connectStartTime)
 	log.Printf("WebSocket connection established from %s in %v", r.RemoteAddr, connectDuration)
+	heartbeatCtx, stopHeartbeat := context.WithCancel(context.Background())
+	defer stopHeartbeat()
+	go h.heartbeat(heartbeatCtx, conn, r.RemoteAddr)
 
 	// Read messages from client
+	session := newConnSession()
 	for {
-		_, data, err := conn.Read(context.Background())
+		frameType, data, err := conn.Read(context.Background())
 		if err != nil {
 			log.Printf("WebSocket read error: %v", err)
 			break
 		}
 
 		var clientMsg ClientMessage
-		if err := json.Unmarshal(data, &clientMsg); err != nil {
-			h.sendError(conn, fmt.Sprintf("invalid message format: %v", err))
+		if err := UnmarshalMessage(frameType, data, &clientMsg); err != nil {
+			h.sendError(conn, "", errorWithCode(ErrorCodeInvalidMessage, "invalid message format: %v", err))
 			continue
 		}
 
+		if clientMsg.Type == MessageTypeHello {
+			if err := h.handleHello(conn, session, &clientMsg); err != nil {
+				// an incompatible client cannot be served, tell it why then disconnect
+				log.Printf("Rejected hello from %s: %v", r.RemoteAddr, err)
+				h.sendError(conn, clientMsg.RequestID, &codedError{code: ErrorCodeIncompatibleClient, err: err})
+				conn.Close(websocket.StatusPolicyViolation, "incompatible client")
+				return
+			}
+			continue
+		}
+		session.started = true
 		if err := h.handleMessage(conn, &clientMsg); err != nil {
 			log.Printf("Error handling message: %v", err)
-			h.sendError(conn, err.Error())
+			h.sendError(conn, clientMsg.RequestID, err)
+			continue
+		}
+		if clientMsg.RequestID != "" {
+			h.send(conn, ServerMessage{Type: MessageTypeAck, RequestID: clientMsg.RequestID})
+		}
+	}
+}
+
+// heartbeat pings the connection until ctx is done, see HeartbeatInterval.
+// Closing the connection ends the read loop, which removes the connection.
+func (h *WebSocketHandler) heartbeat(ctx context.Context, conn *websocket.Conn, remoteAddr string) {
+	ticker := time.NewTicker(h.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		pingCtx, cancel := context.WithTimeout(ctx, h.heartbeatTimeout)
+		err := conn.Ping(pingCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("WebSocket heartbeat failed from %s: %v", remoteAddr, err)
+				conn.CloseNow()
+			}
+			return
 		}
 	}
 }
 
+func (h *WebSocketHandler) handleHello(conn *websocket.Conn, session *connSession, msg *ClientMessage) error {
+	games := make([]string, 0, len(h.actionProcessors))
+	for game := range h.actionProcessors {
+		games = append(games, game)
+	}
+	info, err := session.hello(msg, games)
+	if err != nil {
+		return err
+	}
+	if session.features[FeatureDelta] {
+		h.connectionMgr.EnableDelta(conn)
+	}
+	// welcome is in JSON like hello, the negotiated encoding is used after it
+	data, err := json.Marshal(ServerMessage{Type: MessageTypeWelcome, RequestID: msg.RequestID, Hello: info})
+	if err != nil {
+		return err
+	}
+	if err := conn.Write(context.Background(), websocket.MessageText, data); err != nil {
+		return err
+	}
+	h.connectionMgr.SetEncoding(conn, session.encoding)
+	return nil
+}
+
 func (h *WebSocketHandler) handleMessage(conn *websocket.Conn, msg *ClientMessage) error {
 	switch msg.Type {
 	case MessageTypeCreateDuel:
 		return h.handleCreateDuel(conn, msg)
 	case MessageTypeJoinDuel:
 		return h.handleJoinDuel(conn, msg)
+	case MessageTypeSpectate:
+		return h.handleSpectate(conn, msg)
 	case MessageTypeAction:
 		return h.handleAction(conn, msg)
+	case MessageTypeResync:
+		return h.handleResync(conn)
 	default:
-		return fmt.Errorf("unknown message type: %s", msg.Type)
+		return errorWithCode(ErrorCodeUnknownMessageType, "unknown message type: %s", msg.Type)
 	}
 }
 
 func (h *WebSocketHandler) handleCreateDuel(conn *websocket.Conn, msg *ClientMessage) error {
-	if msg.Game == "" {
-		return fmt.Errorf("game name required")
-	}
-	if len(msg.Players) == 0 {
-		return fmt.Errorf("at least one player required")
-	}
-
-	processor, ok := h.actionProcessors[msg.Game]
-	if !ok {
-		return fmt.Errorf("unknown game: %s", msg.Game)
-	}
-
-	playerIDs := make([]turnbased.PlayerID, len(msg.Players))
-	for i, p := range msg.Players {
-		playerIDs[i] = turnbased.PlayerID(p)
-	}
-
-	duel, err := processor.CreateDuel(msg.Game, playerIDs)
+	duel, err := createDuel(h.actionProcessors, msg.Game, msg.Players, msg.Bots, msg.Settings)
 	if err != nil {
 		return err
 	}
-
-	// Register connection for all players in the duel
-	for _, playerID := range playerIDs {
-		h.connectionMgr.AddConnection(conn, playerID, duel.ID)
+	playerIDs := duel.Players
+
+	// Register connection for all human players in the duel (hot seat), it sees the state of the first player.
+	// The other players can join with their own connection (see join URLs),
+	// so each connection only receives the state its player is allowed to see
+	var humans []turnbased.PlayerID
+	for _, pid := range playerIDs {
+		if _, isBot := msg.Bots[string(pid)]; !isBot {
+			humans = append(humans, pid)
+		}
 	}
-
-	// Send initial state to client
-	return h.sendStateUpdate(conn, duel)
+	return h.sendLocked(duel.ID, msg.Game, func() (func() error, error) {
+		h.connectionMgr.AddHotSeatConnection(conn, humans, duel.ID)
+		// Send initial state to client
+		return h.connectionMgr.PrepareState(conn, duel, playerIDs[0])
+	})
 }
 
 func (h *WebSocketHandler) handleJoinDuel(conn *websocket.Conn, msg *ClientMessage) error {
 	if msg.DuelID == "" {
-		return fmt.Errorf("duel_id required")
+		return errorWithCode(ErrorCodeBadRequest, "duel_id required")
 	}
 	if msg.PlayerID == "" {
-		return fmt.Errorf("player_id required")
+		return errorWithCode(ErrorCodeBadRequest, "player_id required")
 	}
 
 	duelID := turnbased.DuelID(msg.DuelID)
 	playerID := turnbased.PlayerID(msg.PlayerID)
 
 	// Find which game this duel belongs to
-	var duel *turnbased.Duel
-	for _, manager := range h.duelsManagers {
-		d := manager.GetDuel(duelID)
-		if d != nil {
-			duel = d
-			break
-		}
-	}
+	duel, game := findDuel(h.duelsManagers, duelID)
 
 	if duel == nil {
-		return fmt.Errorf("duel not found: %s", msg.DuelID)
+		return errorWithCode(ErrorCodeDuelNotFound, "duel not found: %s", msg.DuelID)
 	}
 
 	// Verify player is in the duel
@@ -163,49 +237,55 @@ func (h *WebSocketHandler) handleJoinDuel(conn *websocket.Conn, msg *ClientMessa
 		}
 	}
 	if !found {
-		return fmt.Errorf("player %s is not in duel %s", msg.PlayerID, msg.DuelID)
+		return errorWithCode(ErrorCodePlayerNotInDuel, "player %s is not in duel %s", msg.PlayerID, msg.DuelID)
 	}
 
-	// Register connection
-	h.connectionMgr.AddConnection(conn, playerID, duelID)
-
-	// Send current state
-	return h.sendStateUpdate(conn, duel)
+	return h.sendLocked(duelID, game, func() (func() error, error) {
+		// Register connection
+		h.connectionMgr.AddConnection(conn, playerID, duelID)
+		// Send the missed messages to a resuming client, otherwise the current state
+		if msg.LastSeq > 0 {
+			return h.connectionMgr.PrepareResume(conn, duel, playerID, msg.LastSeq)
+		}
+		return h.connectionMgr.PrepareState(conn, duel, playerID)
+	})
 }
 
-func (h *WebSocketHandler) handleAction(conn *websocket.Conn, msg *ClientMessage) error {
+// handleSpectate registers the connection as a spectator of the duel, see ConnectionManager.AddSpectator
+func (h *WebSocketHandler) handleSpectate(conn *websocket.Conn, msg *ClientMessage) error {
 	if msg.DuelID == "" {
-		return fmt.Errorf("duel_id required")
-	}
-	if msg.PlayerID == "" {
-		return fmt.Errorf("player_id required")
+		return errorWithCode(ErrorCodeBadRequest, "duel_id required")
 	}
-	if msg.Game == "" {
-		return fmt.Errorf("game required")
-	}
-
 	duelID := turnbased.DuelID(msg.DuelID)
-	playerID := turnbased.PlayerID(msg.PlayerID)
-
-	// Find the duel
-	manager, ok := h.duelsManagers[msg.Game]
-	if !ok {
-		return fmt.Errorf("unknown game: %s", msg.Game)
-	}
-
-	duel := manager.GetDuel(duelID)
+	duel, game := findDuel(h.duelsManagers, duelID)
 	if duel == nil {
-		return fmt.Errorf("duel not found: %s", msg.DuelID)
+		return errorWithCode(ErrorCodeDuelNotFound, "duel not found: %s", msg.DuelID)
 	}
+	return h.sendLocked(duelID, game, func() (func() error, error) {
+		if !duel.Settings.AllowSpectators {
+			return nil, errorWithCode(ErrorCodeSpectatorsNotAllowed, "duel %s does not allow spectators", msg.DuelID)
+		}
+		h.connectionMgr.AddSpectator(conn, duelID)
+		if msg.LastSeq > 0 {
+			return h.connectionMgr.PrepareResume(conn, duel, "", msg.LastSeq)
+		}
+		return h.connectionMgr.PrepareState(conn, duel, "")
+	})
+}
 
-	// Parse action based on game type
-	processor, ok := h.actionProcessors[msg.Game]
-	if !ok {
-		return fmt.Errorf("no processor for game: %s", msg.Game)
+func (h *WebSocketHandler) handleAction(conn *websocket.Conn, msg *ClientMessage) error {
+	if h.connectionMgr.IsSpectator(conn) {
+		return errorWithCode(ErrorCodeSpectatorCannotAct, "spectators cannot send actions")
+	}
+	// the actor is a player the connection plays for, not any player the message names
+	if msg.PlayerID != "" && msg.DuelID != "" &&
+		!h.connectionMgr.PlaysFor(conn, turnbased.PlayerID(msg.PlayerID), turnbased.DuelID(msg.DuelID)) {
+		return errorWithCode(ErrorCodePlayerNotInDuel, "the connection does not play for %s in duel %s", msg.PlayerID, msg.DuelID)
 	}
-
 	// Process action (Message In → Persist → Fanout happens in processor)
-	if err := processor.ProcessAction(duelID, playerID, msg.Action); err != nil {
+	err := processAction(h.duelsManagers, h.actionProcessors, h.connectionMgr, msg.Game,
+		turnbased.DuelID(msg.DuelID), turnbased.PlayerID(msg.PlayerID), msg.Action, nil)
+	if err != nil {
 		return err
 	}
 
@@ -213,42 +293,42 @@ func (h *WebSocketHandler) handleAction(conn *websocket.Conn, msg *ClientMessage
 	return nil
 }
 
-func (h *WebSocketHandler) sendStateUpdate(conn *websocket.Conn, duel *turnbased.Duel) error {
-	// Get game-specific state
-	gameStateRaw := duel.Game.GetState()
-
-	// Convert to typed game state if it's a Burn duel
-	var gameState any
-	if burnDuel, ok := duel.Game.(*card_game_burn.BurnDuel); ok {
-		gameState = burnDuel.ToModelBurnGameState()
-	} else {
-		// Fallback to raw state for other games
-		gameState = gameStateRaw
+// handleResync sends the full state of the connection's duel again
+func (h *WebSocketHandler) handleResync(conn *websocket.Conn) error {
+	playerID, duelID, ok := h.connectionMgr.ConnectionPlayer(conn)
+	if !ok {
+		return errorWithCode(ErrorCodeBadRequest, "resync before creating or joining a duel")
 	}
-
-	// Create serializable duel
-	serializableDuel := model.FromDuel(duel)
-
-	msg := ServerMessage{
-		Type:      MessageTypeStateUpdate,
-		Duel:      &serializableDuel,
-		GameState: gameState,
+	duel, game := findDuel(h.duelsManagers, duelID)
+	if duel == nil {
+		return errorWithCode(ErrorCodeDuelNotFound, "duel not found: %s", duelID)
 	}
+	return h.sendLocked(duelID, game, func() (func() error, error) {
+		return h.connectionMgr.PrepareState(conn, duel, playerID)
+	})
+}
 
-	data, err := json.Marshal(msg)
+// sendLocked runs prepare while the duel is locked, so that it reads the duel without racing with actions,
+// then calls the returned send after unlocking, so that a slow connection does not block the actions.
+// The fanout of the duel is held until then: the connection receives what send writes
+// before the messages of the next actions.
+func (h *WebSocketHandler) sendLocked(duelID turnbased.DuelID, game string, prepare func() (send func() error, err error)) error {
+	release := h.connectionMgr.HoldFanout(duelID)
+	defer release()
+	unlock := h.duelsManagers[game].LockDuel(duelID)
+	send, err := prepare()
+	unlock()
 	if err != nil {
 		return err
 	}
+	return send()
+}
 
-	return conn.Write(context.Background(), websocket.MessageText, data)
+// sendError answers the request (requestID can be empty) with an error message, see errorCode
+func (h *WebSocketHandler) sendError(conn *websocket.Conn, requestID string, err error) {
+	h.send(conn, NewErrorMessage(requestID, err))
 }
 
-func (h *WebSocketHandler) sendError(conn *websocket.Conn, errorMsg string) {
-	msg := ServerMessage{
-		Type:    MessageTypeError,
-		Error:   errorMsg,
-		Message: errorMsg,
-	}
-	data, _ := json.Marshal(msg)
-	_ = conn.Write(context.Background(), websocket.MessageText, data)
+func (h *WebSocketHandler) send(conn *websocket.Conn, msg ServerMessage) {
+	_ = h.connectionMgr.Send(conn, msg)
 }
@@ -0,0 +1,102 @@
+package httpsvr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+)
+
+// gameServer is a WebSocket server of one game, for the tests that play it over the /ws protocol
+type gameServer struct {
+	t   *testing.T
+	ctx context.Context
+	url string
+}
+
+// newGameServer starts a WebSocket server of the game, it is closed at the end of the test
+func newGameServer(t *testing.T, game string) *gameServer {
+	handler := NewWebSocketHandler(
+		map[string]turnbased.DuelsManager{game: turnbased.NewInMemoryDuelsManager()},
+		NewConnectionManager(),
+	)
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	t.Cleanup(server.Close)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return &gameServer{t: t, ctx: ctx, url: "ws" + server.URL[4:]}
+}
+
+// dial connects a client, the connection is closed at the end of the test
+func (s *gameServer) dial() *websocket.Conn {
+	s.t.Helper()
+	conn, _, err := websocket.Dial(s.ctx, s.url, nil)
+	if err != nil {
+		s.t.Fatalf("error Dial: %v", err)
+	}
+	s.t.Cleanup(func() { conn.Close(websocket.StatusNormalClosure, "") })
+	return conn
+}
+
+func (s *gameServer) send(conn *websocket.Conn, msg ClientMessage) {
+	s.t.Helper()
+	send(s.t, s.ctx, conn, msg)
+}
+
+func (s *gameServer) read(conn *websocket.Conn) ServerMessage {
+	s.t.Helper()
+	return read(s.t, s.ctx, conn)
+}
+
+// readState reads the next state_update and decodes its game state into gameState, a pointer
+// whose previous value is discarded
+func (s *gameServer) readState(conn *websocket.Conn, gameState any) ServerMessage {
+	s.t.Helper()
+	msg := s.read(conn)
+	if msg.Type != MessageTypeStateUpdate {
+		s.t.Fatalf("expected state_update, got %s %s", msg.Type, msg.Error)
+	}
+	reflect.ValueOf(gameState).Elem().SetZero()
+	raw, _ := json.Marshal(msg.GameState)
+	if err := json.Unmarshal(raw, gameState); err != nil {
+		s.t.Fatalf("error Unmarshal game state: %v", err)
+	}
+	return msg
+}
+
+func send(t *testing.T, ctx context.Context, conn *websocket.Conn, msg ClientMessage) {
+	t.Helper()
+	data, _ := json.Marshal(msg)
+	if err := conn.Write(ctx, websocket.MessageText, data); err != nil {
+		t.Fatalf("error Write: %v", err)
+	}
+}
+
+// read returns the next message that is not a presence or spectators event, see readAny
+func read(t *testing.T, ctx context.Context, conn *websocket.Conn) ServerMessage {
+	t.Helper()
+	for {
+		if msg := readAny(t, ctx, conn); msg.Type != MessageTypePresence && msg.Type != MessageTypeSpectators {
+			return msg
+		}
+	}
+}
+
+func readAny(t *testing.T, ctx context.Context, conn *websocket.Conn) ServerMessage {
+	t.Helper()
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("error Read: %v", err)
+	}
+	var msg ServerMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("error Unmarshal: %v", err)
+	}
+	return msg
+}
@@ -0,0 +1,67 @@
+package wsclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/daominah/turn_based_game/internal/driver/httpsvr"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// PlayCard is a Burn action (option GAIN, INFLICT or CONTINUOUS)
+// or a Spades action (cardID like "QS", option empty)
+func PlayCard(cardID string, option string) model.ActionData {
+	action := model.ActionData{CardID: &cardID}
+	if option != "" {
+		action.Option = &option
+	}
+	return action
+}
+
+// EndTurn is a Burn action
+func EndTurn() model.ActionData {
+	endTurn := true
+	return model.ActionData{EndTurn: &endTurn}
+}
+
+// DropDisc is a Connect Four action, column is 0-based
+func DropDisc(column int) model.ActionData {
+	return model.ActionData{Column: &column}
+}
+
+// Move is a Chess action, in UCI ("e2e4") or SAN ("Nf3")
+func Move(move string) model.ActionData {
+	return model.ActionData{Move: &move}
+}
+
+// PlaceFleet is a Battleship action
+func PlaceFleet(ships []model.BattleshipShipPlacement) model.ActionData {
+	return model.ActionData{Ships: ships}
+}
+
+// Fire is a Battleship action, row and column are 0-based
+func Fire(row int, column int) model.ActionData {
+	return model.ActionData{Row: &row, Column: &column}
+}
+
+// Throw is a rock-paper-scissors action: ROCK, PAPER or SCISSORS
+func Throw(throw string) model.ActionData {
+	return model.ActionData{Throw: &throw}
+}
+
+// DecodeGameState decodes the game-specific state of a state update,
+// T is the game's state model, e.g. model.BurnGameState
+func DecodeGameState[T any](msg httpsvr.ServerMessage) (T, error) {
+	var state T
+	if msg.GameState == nil {
+		return state, fmt.Errorf("message has no game state")
+	}
+	data, err := json.Marshal(msg.GameState)
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("decode game state: %w", err)
+	}
+	return state, nil
+}
@@ -0,0 +1,74 @@
+package wsclient
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/daominah/turn_based_game/internal/driver/httpsvr"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// stateView is the last state received on a connection with the delta feature,
+// state_delta messages are applied to it
+type stateView struct {
+	version   int // 0 if no state was received
+	duel      []byte
+	log       []model.SerializableActionLogEntry
+	gameState []byte
+}
+
+// set replaces the view with a full state_update
+func (v *stateView) set(msg httpsvr.ServerMessage) error {
+	duel := *msg.Duel
+	duel.ActionLog = nil
+	duelData, err := json.Marshal(duel)
+	if err != nil {
+		return err
+	}
+	gameState, err := json.Marshal(msg.GameState)
+	if err != nil {
+		return err
+	}
+	*v = stateView{
+		version:   msg.Version,
+		duel:      duelData,
+		log:       slices.Clone(msg.Duel.ActionLog),
+		gameState: gameState,
+	}
+	return nil
+}
+
+// apply applies the state_delta to the view and returns the resulting state as a state_update,
+// ok is false if the delta is not based on the view version, the view must then be resynced
+func (v *stateView) apply(msg httpsvr.ServerMessage) (update httpsvr.ServerMessage, ok bool, err error) {
+	if v.version == 0 || msg.Delta == nil || msg.BaseVersion != v.version {
+		return update, false, nil
+	}
+	duelData, err := model.ApplyMergePatch(v.duel, msg.Delta.DuelPatch)
+	if err != nil {
+		return update, false, err
+	}
+	gameState, err := model.ApplyMergePatch(v.gameState, msg.Delta.GameStatePatch)
+	if err != nil {
+		return update, false, err
+	}
+	var duel model.SerializableDuel
+	if err := json.Unmarshal(duelData, &duel); err != nil {
+		return update, false, err
+	}
+	var state any
+	if err := json.Unmarshal(gameState, &state); err != nil {
+		return update, false, err
+	}
+	v.version = msg.Version
+	v.duel = duelData
+	v.log = append(v.log, msg.Delta.NewLogEntries...)
+	v.gameState = gameState
+	duel.ActionLog = slices.Clone(v.log)
+	return httpsvr.ServerMessage{
+		Type:      httpsvr.MessageTypeStateUpdate,
+		Duel:      &duel,
+		GameState: state,
+		Version:   msg.Version,
+	}, true, nil
+}
@@ -0,0 +1,544 @@
+// Package wsclient is a Go client of the WebSocket protocol served by httpsvr at "/ws",
+// for bots, load tests and tools that play duels without the browser
+package wsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/driver/httpsvr"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+// ErrClosed is returned when using a client after Close
+var ErrClosed = errors.New("client closed")
+
+// ServerError is an error message from the server
+type ServerError struct {
+	Code      httpsvr.ErrorCode
+	RequestID string // of the rejected request, empty if the server could not tell it
+	Message   string
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("server: %s", e.Message)
+}
+
+func newServerError(msg httpsvr.ServerMessage) *ServerError {
+	return &ServerError{Code: msg.Code, RequestID: msg.RequestID, Message: msg.Error}
+}
+
+// Default values of Options fields left zero
+const (
+	DefaultBufferSize      = 64
+	DefaultReconnectDelay  = 500 * time.Millisecond
+	DefaultMaxReconnectTry = 10
+)
+
+// Options of a client, the zero value is usable
+type Options struct {
+	// OnMessage is called for each message from the server, in the reading goroutine,
+	// it must not block for long
+	OnMessage func(msg httpsvr.ServerMessage)
+	// BufferSize is the capacity of the Messages channel, 0 means DefaultBufferSize.
+	// When the channel is full, new messages are dropped from it (OnMessage still gets them).
+	BufferSize int
+	// Reconnect redials when the connection is lost, then joins the current duel again
+	// and receives the messages missed meanwhile
+	Reconnect bool
+	// ReconnectDelay is the wait before each redial, 0 means DefaultReconnectDelay
+	ReconnectDelay time.Duration
+	// MaxReconnectTry is the number of failed redials before giving up, 0 means DefaultMaxReconnectTry
+	MaxReconnectTry int
+	// DialOptions are passed to websocket.Dial (e.g. HTTP headers)
+	DialOptions *websocket.DialOptions
+	// Encoding of the messages, httpsvr.EncodingJSON (the default) or httpsvr.EncodingCBOR
+	// for smaller binary frames
+	Encoding string
+	// Features is the optional protocol features asked in hello, see httpsvr.HelloInfo.
+	// With httpsvr.FeatureDelta, state_delta messages are applied by the client
+	// and received as full state updates.
+	Features []string
+}
+
+// Session is the duel the client plays in, it is joined again after a reconnect
+type Session struct {
+	Game     string
+	DuelID   string
+	PlayerID string
+}
+
+// Client is a connection to the game server, safe for concurrent use
+type Client struct {
+	url     string
+	options Options
+
+	mu      sync.Mutex
+	conn    *websocket.Conn
+	hello   *httpsvr.HelloInfo // the server's answer to the hello of conn
+	session Session
+	// pendingCreate is set between sending create_duel and receiving its state update,
+	// the first state update then tells the duel ID of the session
+	pendingCreate bool
+	lastRequestID int
+	// waiting is the requests waited by ActAndWait, request ID -> its ack or error
+	waiting map[string]chan httpsvr.ServerMessage
+	view    stateView // the state deltas apply to, if the delta feature is enabled
+	// lastSeq is the seq of the last message received from the session duel,
+	// the missed messages after it are replayed when joining again after a reconnect
+	lastSeq int
+	// resyncing is set when a delta did not apply, until the full state answering the resync,
+	// which can have the seq of that delta, see checkSeq
+	resyncing bool
+
+	messages chan httpsvr.ServerMessage
+	closed   chan struct{}
+	done     chan struct{} // closed when the reading goroutine returns
+	err      error         // why the reading goroutine returned, read after done
+}
+
+// Dial connects to the server WebSocket URL (e.g. "ws://localhost:11995/ws")
+func Dial(ctx context.Context, url string, options Options) (*Client, error) {
+	if options.BufferSize == 0 {
+		options.BufferSize = DefaultBufferSize
+	}
+	if options.ReconnectDelay == 0 {
+		options.ReconnectDelay = DefaultReconnectDelay
+	}
+	if options.MaxReconnectTry == 0 {
+		options.MaxReconnectTry = DefaultMaxReconnectTry
+	}
+	if options.Encoding == "" {
+		options.Encoding = httpsvr.EncodingJSON
+	}
+	c := &Client{
+		url:      url,
+		options:  options,
+		waiting:  make(map[string]chan httpsvr.ServerMessage),
+		messages: make(chan httpsvr.ServerMessage, options.BufferSize),
+		closed:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	conn, hello, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.conn, c.hello = conn, hello
+	go c.readLoop()
+	return c, nil
+}
+
+// dial connects and does the hello handshake
+func (c *Client) dial(ctx context.Context) (*websocket.Conn, *httpsvr.HelloInfo, error) {
+	conn, _, err := websocket.Dial(ctx, c.url, c.options.DialOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("dial %s: %w", c.url, err)
+	}
+	// hello and its answer are in JSON, the negotiated encoding is used after them
+	hello := httpsvr.ClientMessage{
+		Type:            httpsvr.MessageTypeHello,
+		ProtocolVersion: httpsvr.ProtocolVersion,
+		Encoding:        c.options.Encoding,
+		Features:        c.options.Features,
+	}
+	if err := writeMessage(ctx, conn, httpsvr.EncodingJSON, hello); err != nil {
+		conn.Close(websocket.StatusNormalClosure, "")
+		return nil, nil, fmt.Errorf("send hello: %w", err)
+	}
+	frameType, data, err := conn.Read(ctx)
+	if err != nil {
+		conn.Close(websocket.StatusNormalClosure, "")
+		return nil, nil, fmt.Errorf("read hello answer: %w", err)
+	}
+	var msg httpsvr.ServerMessage
+	if err := httpsvr.UnmarshalMessage(frameType, data, &msg); err != nil {
+		conn.Close(websocket.StatusNormalClosure, "")
+		return nil, nil, fmt.Errorf("read hello answer: %w", err)
+	}
+	if msg.Type != httpsvr.MessageTypeWelcome || msg.Hello == nil {
+		conn.Close(websocket.StatusNormalClosure, "")
+		return nil, nil, fmt.Errorf("server rejected hello: %s", msg.Error)
+	}
+	return conn, msg.Hello, nil
+}
+
+// Hello returns the server's answer to the handshake: protocol version, games, features, ...
+func (c *Client) Hello() httpsvr.HelloInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return *c.hello
+}
+
+// Messages returns the channel of messages from the server,
+// it is closed when the client is closed or the connection is lost for good (see Err)
+func (c *Client) Messages() <-chan httpsvr.ServerMessage {
+	return c.messages
+}
+
+// Done is closed when the client stops receiving messages
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the client stopped receiving messages, nil while it is running
+// or if it was closed by Close
+func (c *Client) Err() error {
+	select {
+	case <-c.done:
+		return c.err
+	default:
+		return nil
+	}
+}
+
+// Session returns the duel the client plays in, empty before creating or joining one
+func (c *Client) Session() Session {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session
+}
+
+// Close closes the connection, it does not end the duel
+func (c *Client) Close() error {
+	c.mu.Lock()
+	select {
+	case <-c.closed:
+		c.mu.Unlock()
+		return nil
+	default:
+	}
+	close(c.closed)
+	conn := c.conn
+	c.mu.Unlock()
+	err := conn.Close(websocket.StatusNormalClosure, "")
+	<-c.done
+	return err
+}
+
+// CreateDuel creates a duel where this client plays as the first player,
+// bots (player ID -> bot kind) are optional, see httpsvr.ClientMessage.Bots.
+// The created duel comes in the next state update.
+func (c *Client) CreateDuel(ctx context.Context, game string, players []string, bots map[string]string) error {
+	if len(players) == 0 {
+		return fmt.Errorf("at least one player required")
+	}
+	c.mu.Lock()
+	c.session = Session{Game: game, PlayerID: players[0]}
+	c.pendingCreate = true
+	c.lastSeq = 0
+	c.resyncing = false
+	c.mu.Unlock()
+	return c.send(ctx, httpsvr.ClientMessage{
+		Type:    httpsvr.MessageTypeCreateDuel,
+		Game:    game,
+		Players: players,
+		Bots:    bots,
+	})
+}
+
+// JoinDuel joins an existing duel as the player, the duel state comes in the next state update
+func (c *Client) JoinDuel(ctx context.Context, game string, duelID string, playerID string) error {
+	c.mu.Lock()
+	c.session = Session{Game: game, DuelID: duelID, PlayerID: playerID}
+	c.pendingCreate = false
+	c.lastSeq = 0
+	c.resyncing = false
+	c.mu.Unlock()
+	return c.send(ctx, joinMessage(c.Session(), 0))
+}
+
+// Act sends an action of the session player in the session duel,
+// build the action with PlayCard, EndTurn, DropDisc, ... The result comes in the next state update
+// or an error message.
+func (c *Client) Act(ctx context.Context, action model.ActionData) error {
+	msg, err := c.actionMessage(action)
+	if err != nil {
+		return err
+	}
+	return c.send(ctx, msg)
+}
+
+// ActAndWait is Act then waits for the server's answer to this action:
+// nil if it was accepted (its state updates were received before), a *ServerError if it was rejected.
+// The action is sent with a request ID, so the answer is told apart from errors of other messages,
+// the ack is also received by Messages and OnMessage.
+func (c *Client) ActAndWait(ctx context.Context, action model.ActionData) error {
+	msg, err := c.actionMessage(action)
+	if err != nil {
+		return err
+	}
+	msg.RequestID = c.newRequestID()
+	answer := make(chan httpsvr.ServerMessage, 1)
+	c.mu.Lock()
+	c.waiting[msg.RequestID] = answer
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.waiting, msg.RequestID)
+		c.mu.Unlock()
+	}()
+	if err := c.send(ctx, msg); err != nil {
+		return err
+	}
+	select {
+	case reply := <-answer:
+		if reply.Type == httpsvr.MessageTypeError {
+			return newServerError(reply)
+		}
+		return nil
+	case <-c.done:
+		if err := c.Err(); err != nil {
+			return err
+		}
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) actionMessage(action model.ActionData) (httpsvr.ClientMessage, error) {
+	session := c.Session()
+	if session.DuelID == "" {
+		return httpsvr.ClientMessage{}, fmt.Errorf("not in a duel")
+	}
+	return httpsvr.ClientMessage{
+		Type:     httpsvr.MessageTypeAction,
+		Game:     session.Game,
+		DuelID:   session.DuelID,
+		PlayerID: session.PlayerID,
+		Action:   action,
+	}, nil
+}
+
+// newRequestID returns the next request ID of this client, unique for the client lifetime
+func (c *Client) newRequestID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastRequestID++
+	return strconv.Itoa(c.lastRequestID)
+}
+
+// Next waits for the next message from the server
+func (c *Client) Next(ctx context.Context) (httpsvr.ServerMessage, error) {
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			if err := c.Err(); err != nil {
+				return httpsvr.ServerMessage{}, err
+			}
+			return httpsvr.ServerMessage{}, ErrClosed
+		}
+		return msg, nil
+	case <-ctx.Done():
+		return httpsvr.ServerMessage{}, ctx.Err()
+	}
+}
+
+// NextState waits for the next state update, an error message from the server is returned as a *ServerError
+func (c *Client) NextState(ctx context.Context) (httpsvr.ServerMessage, error) {
+	for {
+		msg, err := c.Next(ctx)
+		if err != nil {
+			return msg, err
+		}
+		switch msg.Type {
+		case httpsvr.MessageTypeStateUpdate:
+			return msg, nil
+		case httpsvr.MessageTypeError:
+			return msg, newServerError(msg)
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, msg httpsvr.ClientMessage) error {
+	c.mu.Lock()
+	conn, encoding := c.conn, c.hello.Encoding
+	c.mu.Unlock()
+	select {
+	case <-c.closed:
+		return ErrClosed
+	default:
+	}
+	return writeMessage(ctx, conn, encoding, msg)
+}
+
+// writeMessage writes the message in the encoding, see httpsvr.MarshalMessage
+func writeMessage(ctx context.Context, conn *websocket.Conn, encoding string, msg httpsvr.ClientMessage) error {
+	frameType, data, err := httpsvr.MarshalMessage(encoding, msg)
+	if err != nil {
+		return err
+	}
+	return conn.Write(ctx, frameType, data)
+}
+
+// joinMessage joins the session duel, lastSeq is 0 to get the full state
+// or the seq of the last received message to get the missed ones
+func joinMessage(session Session, lastSeq int) httpsvr.ClientMessage {
+	return httpsvr.ClientMessage{
+		Type:     httpsvr.MessageTypeJoinDuel,
+		Game:     session.Game,
+		DuelID:   session.DuelID,
+		PlayerID: session.PlayerID,
+		LastSeq:  lastSeq,
+	}
+}
+
+// readLoop receives messages until the client is closed,
+// if the connection is lost it reconnects when enabled
+func (c *Client) readLoop() {
+	defer close(c.done)
+	defer close(c.messages)
+	for {
+		c.mu.Lock()
+		conn := c.conn
+		c.mu.Unlock()
+		err := c.readConn(conn)
+		select {
+		case <-c.closed:
+			return
+		default:
+		}
+		if !c.options.Reconnect {
+			c.err = err
+			return
+		}
+		if err := c.reconnect(); err != nil {
+			c.err = err
+			return
+		}
+	}
+}
+
+func (c *Client) readConn(conn *websocket.Conn) error {
+	for {
+		frameType, data, err := conn.Read(context.Background())
+		if err != nil {
+			return err
+		}
+		var msg httpsvr.ServerMessage
+		if err := httpsvr.UnmarshalMessage(frameType, data, &msg); err != nil {
+			continue // not a message of this protocol
+		}
+		if !c.checkSeq(msg) {
+			continue // already received, replayed while resuming
+		}
+		msg, ok := c.applyDelta(msg)
+		if !ok {
+			c.mu.Lock()
+			encoding := c.hello.Encoding
+			c.mu.Unlock()
+			resync := httpsvr.ClientMessage{Type: httpsvr.MessageTypeResync}
+			if err := writeMessage(context.Background(), conn, encoding, resync); err != nil {
+				return err
+			}
+			continue
+		}
+		c.mu.Lock()
+		if c.pendingCreate && msg.Type == httpsvr.MessageTypeStateUpdate && msg.Duel != nil {
+			c.session.DuelID = msg.Duel.ID
+			c.pendingCreate = false
+		}
+		if answer, ok := c.waiting[msg.RequestID]; ok && msg.RequestID != "" &&
+			(msg.Type == httpsvr.MessageTypeAck || msg.Type == httpsvr.MessageTypeError) {
+			answer <- msg
+			delete(c.waiting, msg.RequestID)
+		}
+		c.mu.Unlock()
+		if c.options.OnMessage != nil {
+			c.options.OnMessage(msg)
+		}
+		select {
+		case c.messages <- msg:
+		default: // the reader is too slow, drop rather than block the connection
+		}
+	}
+}
+
+// checkSeq records the seq of a message of the duel, it returns false if the message was already received.
+// While resyncing, a full state with the last seq is not a duplicate: it is the state the failed delta led to.
+func (c *Client) checkSeq(msg httpsvr.ServerMessage) bool {
+	if msg.Seq == 0 {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resyncAnswer := c.resyncing && msg.Type == httpsvr.MessageTypeStateUpdate && msg.Seq == c.lastSeq
+	if msg.Seq <= c.lastSeq && !resyncAnswer {
+		return false
+	}
+	c.lastSeq = msg.Seq
+	return true
+}
+
+// applyDelta turns a state_delta into the state_update it leads to, and keeps the base
+// of the next delta. It returns false if the delta does not apply, the caller then sends resync,
+// whose answer is the next full state.
+func (c *Client) applyDelta(msg httpsvr.ServerMessage) (httpsvr.ServerMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !slices.Contains(c.hello.Features, httpsvr.FeatureDelta) {
+		return msg, true
+	}
+	switch msg.Type {
+	case httpsvr.MessageTypeStateUpdate:
+		if msg.Duel != nil {
+			c.resyncing = false
+			if c.view.set(msg) != nil {
+				c.view = stateView{}
+			}
+		}
+		return msg, true
+	case httpsvr.MessageTypeStateDelta:
+		update, ok, err := c.view.apply(msg)
+		if ok && err == nil {
+			return update, true
+		}
+		c.view = stateView{}
+		c.resyncing = true
+		return msg, false
+	}
+	return msg, true
+}
+
+// reconnect redials the server and joins the session duel again,
+// resuming after the last received message
+func (c *Client) reconnect() error {
+	var lastErr error
+	for try := 0; try < c.options.MaxReconnectTry; try++ {
+		select {
+		case <-c.closed:
+			return ErrClosed
+		case <-time.After(c.options.ReconnectDelay):
+		}
+		conn, hello, err := c.dial(context.Background())
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		c.mu.Lock()
+		select {
+		case <-c.closed:
+			c.mu.Unlock()
+			conn.Close(websocket.StatusNormalClosure, "")
+			return ErrClosed
+		default:
+		}
+		c.conn, c.hello = conn, hello
+		session, lastSeq := c.session, c.lastSeq
+		c.mu.Unlock()
+		if session.DuelID != "" {
+			if err := writeMessage(context.Background(), conn, hello.Encoding, joinMessage(session, lastSeq)); err != nil {
+				lastErr = err
+				continue
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("reconnect %s failed after %d tries: %w", c.url, c.options.MaxReconnectTry, lastErr)
+}
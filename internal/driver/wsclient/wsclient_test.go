@@ -0,0 +1,307 @@
+package wsclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/daominah/turn_based_game/internal/core/rock_paper_scissors"
+	"github.com/daominah/turn_based_game/internal/core/turnbased"
+	"github.com/daominah/turn_based_game/internal/driver/httpsvr"
+	"github.com/daominah/turn_based_game/internal/model"
+)
+
+func newTestServer(t *testing.T) string {
+	handler := httpsvr.NewWebSocketHandler(
+		map[string]turnbased.DuelsManager{rock_paper_scissors.GameName: turnbased.NewInMemoryDuelsManager()},
+		httpsvr.NewConnectionManager(),
+	)
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	t.Cleanup(server.Close)
+	return "ws" + server.URL[4:]
+}
+
+func TestClient_PlayDuel(t *testing.T) {
+	url := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	callbacks := make(chan httpsvr.MessageType, 16)
+	alice, err := Dial(ctx, url, Options{OnMessage: func(msg httpsvr.ServerMessage) { callbacks <- msg.Type }})
+	if err != nil {
+		t.Fatalf("error Dial: %v", err)
+	}
+	defer alice.Close()
+	if hello := alice.Hello(); hello.ProtocolVersion != httpsvr.ProtocolVersion ||
+		len(hello.Games) != 1 || hello.Games[0] != rock_paper_scissors.GameName {
+		t.Errorf("unexpected hello answer: %+v", hello)
+	}
+	if err := alice.CreateDuel(ctx, rock_paper_scissors.GameName, []string{"alice", "bob"}, nil); err != nil {
+		t.Fatalf("error CreateDuel: %v", err)
+	}
+	created, err := alice.NextState(ctx)
+	if err != nil {
+		t.Fatalf("error NextState: %v", err)
+	}
+	if got := alice.Session(); got.DuelID != created.Duel.ID || got.PlayerID != "alice" {
+		t.Errorf("unexpected session after create: %+v", got)
+	}
+	if got := <-callbacks; got != httpsvr.MessageTypeStateUpdate {
+		t.Errorf("OnMessage got %s, want state_update", got)
+	}
+
+	bob, err := Dial(ctx, url, Options{})
+	if err != nil {
+		t.Fatalf("error Dial: %v", err)
+	}
+	defer bob.Close()
+	if err := bob.JoinDuel(ctx, rock_paper_scissors.GameName, created.Duel.ID, "bob"); err != nil {
+		t.Fatalf("error JoinDuel: %v", err)
+	}
+	if _, err := bob.NextState(ctx); err != nil {
+		t.Fatalf("error NextState: %v", err)
+	}
+
+	if err := alice.Act(ctx, Throw("ROCK")); err != nil {
+		t.Fatalf("error Act: %v", err)
+	}
+	if _, err := alice.NextState(ctx); err != nil {
+		t.Fatalf("error NextState: %v", err)
+	}
+	if _, err := bob.NextState(ctx); err != nil {
+		t.Fatalf("error NextState: %v", err)
+	}
+	if err := bob.Act(ctx, Throw("PAPER")); err != nil {
+		t.Fatalf("error Act: %v", err)
+	}
+	msg, err := bob.NextState(ctx)
+	if err != nil {
+		t.Fatalf("error NextState: %v", err)
+	}
+	state, err := DecodeGameState[model.RockPaperScissorsGameState](msg)
+	if err != nil {
+		t.Fatalf("error DecodeGameState: %v", err)
+	}
+	if len(state.Rounds) != 1 || state.Rounds[0].Winner != "bob" {
+		t.Errorf("expected round 1 won by bob, got %+v", state.Rounds)
+	}
+
+	// an invalid action is answered with an error message
+	if err := bob.Act(ctx, Throw("LIZARD")); err != nil {
+		t.Fatalf("error Act: %v", err)
+	}
+	if _, err := bob.NextState(ctx); err == nil {
+		t.Errorf("expected an error for an invalid throw")
+	}
+}
+
+func TestClient_CBOR(t *testing.T) {
+	url := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := Dial(ctx, url, Options{Encoding: httpsvr.EncodingCBOR, Features: []string{httpsvr.FeatureDelta}})
+	if err != nil {
+		t.Fatalf("error Dial: %v", err)
+	}
+	defer client.Close()
+	if got := client.Hello().Encoding; got != httpsvr.EncodingCBOR {
+		t.Fatalf("negotiated encoding %q, want cbor", got)
+	}
+	if err := client.CreateDuel(ctx, rock_paper_scissors.GameName, []string{"alice", "bob"}, nil); err != nil {
+		t.Fatalf("error CreateDuel: %v", err)
+	}
+	if _, err := client.NextState(ctx); err != nil {
+		t.Fatalf("error NextState: %v", err)
+	}
+	if err := client.ActAndWait(ctx, Throw("ROCK")); err != nil {
+		t.Fatalf("error ActAndWait: %v", err)
+	}
+	msg, err := client.NextState(ctx)
+	if err != nil {
+		t.Fatalf("error NextState: %v", err)
+	}
+	if len(msg.Duel.ActionLog) != 1 {
+		t.Errorf("expected 1 action, got %+v", msg.Duel.ActionLog)
+	}
+}
+
+func TestClient_Reconnect(t *testing.T) {
+	url := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := Dial(ctx, url, Options{Reconnect: true, ReconnectDelay: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("error Dial: %v", err)
+	}
+	defer client.Close()
+	if err := client.CreateDuel(ctx, rock_paper_scissors.GameName, []string{"alice", "bob"}, nil); err != nil {
+		t.Fatalf("error CreateDuel: %v", err)
+	}
+	created, err := client.NextState(ctx)
+	if err != nil {
+		t.Fatalf("error NextState: %v", err)
+	}
+
+	bob, err := Dial(ctx, url, Options{})
+	if err != nil {
+		t.Fatalf("error Dial bob: %v", err)
+	}
+	defer bob.Close()
+	if err := bob.JoinDuel(ctx, rock_paper_scissors.GameName, created.Duel.ID, "bob"); err != nil {
+		t.Fatalf("error JoinDuel bob: %v", err)
+	}
+	if _, err := bob.NextState(ctx); err != nil {
+		t.Fatalf("error NextState bob: %v", err)
+	}
+
+	// drop the connection, bob throws while the client redials,
+	// the client resumes the duel and receives the state it missed, once
+	client.mu.Lock()
+	lost := client.conn
+	client.mu.Unlock()
+	lost.Close(websocket.StatusGoingAway, "")
+	if err := bob.ActAndWait(ctx, Throw("PAPER")); err != nil {
+		t.Fatalf("error ActAndWait bob: %v", err)
+	}
+	missed, err := client.NextState(ctx)
+	if err != nil {
+		t.Fatalf("error NextState after reconnect: %v", err)
+	}
+	if missed.Duel.ID != created.Duel.ID || missed.Seq <= created.Seq || len(missed.Duel.ActionLog) != 1 {
+		t.Errorf("after reconnect got duel %s seq %d with %d log entries, want duel %s after seq %d with bob's throw",
+			missed.Duel.ID, missed.Seq, len(missed.Duel.ActionLog), created.Duel.ID, created.Seq)
+	}
+	if err := client.Act(ctx, Throw("ROCK")); err != nil {
+		t.Fatalf("error Act after reconnect: %v", err)
+	}
+	next, err := client.NextState(ctx)
+	if err != nil {
+		t.Fatalf("error NextState: %v", err)
+	}
+	if next.Seq <= missed.Seq || len(next.Duel.ActionLog) <= len(missed.Duel.ActionLog) {
+		t.Errorf("state after the throw has seq %d with %d log entries, want after seq %d with more entries",
+			next.Seq, len(next.Duel.ActionLog), missed.Seq)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Errorf("error Close: %v", err)
+	}
+	if _, err := client.Next(ctx); err != ErrClosed {
+		t.Errorf("Next after Close: got %v, want ErrClosed", err)
+	}
+}
+
+func TestClient_ActAndWait(t *testing.T) {
+	url := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := Dial(ctx, url, Options{})
+	if err != nil {
+		t.Fatalf("error Dial: %v", err)
+	}
+	defer client.Close()
+	if err := client.CreateDuel(ctx, rock_paper_scissors.GameName, []string{"alice", "bob"}, nil); err != nil {
+		t.Fatalf("error CreateDuel: %v", err)
+	}
+	if _, err := client.NextState(ctx); err != nil {
+		t.Fatalf("error NextState: %v", err)
+	}
+
+	if err := client.ActAndWait(ctx, Throw("ROCK")); err != nil {
+		t.Fatalf("error ActAndWait: %v", err)
+	}
+	err = client.ActAndWait(ctx, Throw("LIZARD"))
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) || serverErr.Code != httpsvr.ErrorCodeInvalidAction || serverErr.RequestID == "" {
+		t.Errorf("expected an INVALID_ACTION server error, got %#v", err)
+	}
+}
+
+func TestClient_Delta(t *testing.T) {
+	url := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	raw := make(chan httpsvr.MessageType, 16)
+	client, err := Dial(ctx, url, Options{
+		Features:  []string{httpsvr.FeatureDelta},
+		OnMessage: func(msg httpsvr.ServerMessage) { raw <- msg.Type },
+	})
+	if err != nil {
+		t.Fatalf("error Dial: %v", err)
+	}
+	defer client.Close()
+	if err := client.CreateDuel(ctx, rock_paper_scissors.GameName, []string{"alice", "bob"}, nil); err != nil {
+		t.Fatalf("error CreateDuel: %v", err)
+	}
+	created, err := client.NextState(ctx)
+	if err != nil {
+		t.Fatalf("error NextState: %v", err)
+	}
+	if err := client.Act(ctx, Throw("ROCK")); err != nil {
+		t.Fatalf("error Act: %v", err)
+	}
+	msg, err := client.NextState(ctx)
+	if err != nil {
+		t.Fatalf("error NextState: %v", err)
+	}
+	// the delta is received as a full state
+	if msg.Version <= created.Version || len(msg.Duel.ActionLog) != 1 || msg.Duel.ActionLog[0].Action != "THROW" ||
+		msg.Duel.Presence["alice"].Status != model.PresenceConnected {
+		t.Errorf("unexpected state rebuilt from the delta: %+v", msg.Duel)
+	}
+	if _, err := DecodeGameState[model.RockPaperScissorsGameState](msg); err != nil {
+		t.Errorf("error DecodeGameState: %v", err)
+	}
+	<-raw
+	if got := <-raw; got != httpsvr.MessageTypeStateUpdate {
+		t.Errorf("OnMessage got %s, want the rebuilt state_update", got)
+	}
+}
+
+// TestClient_DeltaMismatch checks that a delta that does not apply is recovered by resyncing,
+// although the full state answering the resync has the seq of the failed delta
+func TestClient_DeltaMismatch(t *testing.T) {
+	url := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := Dial(ctx, url, Options{Features: []string{httpsvr.FeatureDelta}})
+	if err != nil {
+		t.Fatalf("error Dial: %v", err)
+	}
+	defer client.Close()
+	if err := client.CreateDuel(ctx, rock_paper_scissors.GameName, []string{"alice", "bob"}, nil); err != nil {
+		t.Fatalf("error CreateDuel: %v", err)
+	}
+	created, err := client.NextState(ctx)
+	if err != nil {
+		t.Fatalf("error NextState: %v", err)
+	}
+	client.mu.Lock()
+	client.view.version = created.Version - 1 // the next delta is not based on it
+	client.mu.Unlock()
+
+	if err := client.Act(ctx, Throw("ROCK")); err != nil {
+		t.Fatalf("error Act: %v", err)
+	}
+	msg, err := client.NextState(ctx)
+	if err != nil {
+		t.Fatalf("error NextState: %v", err)
+	}
+	if msg.Type != httpsvr.MessageTypeStateUpdate || msg.Version <= created.Version || len(msg.Duel.ActionLog) != 1 {
+		t.Errorf("unexpected state after the resync: %s %+v", msg.Type, msg.Duel)
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.resyncing || client.view.version != msg.Version {
+		t.Errorf("the next delta does not apply: resyncing %v, view version %d", client.resyncing, client.view.version)
+	}
+}
@@ -4,10 +4,23 @@ package model
 // ActionData represents action data sent from client
 // This is a union type - the actual structure depends on the game and action type
 type ActionData struct {
-	// For Burn game PlayCard action
+	// For Burn game PlayCard action, and Spades PlayCard action (e.g. "QS", "TH")
 	CardID *string `json:"card_id,omitempty"`
 	Option *string `json:"option,omitempty"`
 
 	// For EndTurn action
 	EndTurn *bool `json:"end_turn,omitempty"`
+
+	// For Connect Four DropDisc action and Battleship Fire action, 0-based column
+	Column *int `json:"column,omitempty"`
+	// For Battleship Fire action, 0-based row
+	Row *int `json:"row,omitempty"`
+	// For Battleship PlaceFleet action
+	Ships []BattleshipShipPlacement `json:"ships,omitempty"`
+
+	// For Chess Move action, in UCI ("e2e4", "e7e8q") or SAN ("e4", "Nf3", "O-O")
+	Move *string `json:"move,omitempty"`
+
+	// For rock-paper-scissors Throw action: ROCK, PAPER or SCISSORS
+	Throw *string `json:"throw,omitempty"`
 }
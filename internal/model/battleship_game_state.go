@@ -0,0 +1,47 @@
+// Package model defines shared data models used across packages
+package model
+
+// BattleshipCellShip marks a cell with an unhit ship, only visible to the ship's owner
+const BattleshipCellShip = "SHIP"
+
+// BattleshipShipSpec describes a kind of ship in the fleet
+type BattleshipShipSpec struct {
+	Name   string `json:"name"`
+	Length int    `json:"length"`
+}
+
+// BattleshipShip represents a placed ship, the opponent only sees it once it is sunk
+type BattleshipShip struct {
+	Name       string `json:"name"`
+	Row        int    `json:"row"`
+	Column     int    `json:"column"`
+	Horizontal bool   `json:"horizontal"`
+	Length     int    `json:"length"`
+	Sunk       bool   `json:"sunk"`
+}
+
+// BattleshipBoard represents a player's board as seen by the viewer of the state
+type BattleshipBoard struct {
+	ID          string `json:"id"`
+	FleetPlaced bool   `json:"fleet_placed"`
+	// Cells[row][column] is HIT or MISS for cells the opponent fired at, SHIP for an unhit ship
+	// (only for the owner), empty otherwise
+	Cells          [][]string       `json:"cells"`
+	Ships          []BattleshipShip `json:"ships"`
+	ShipsRemaining int              `json:"ships_remaining"`
+}
+
+// BattleshipGameState represents the game state for Battleship as seen by one viewer
+type BattleshipGameState struct {
+	BoardSize int                        `json:"board_size"`
+	Fleet     []BattleshipShipSpec       `json:"fleet"`
+	Players   map[string]BattleshipBoard `json:"players"`
+}
+
+// BattleshipShipPlacement is sent by a client to place a ship during setup
+type BattleshipShipPlacement struct {
+	Name       string `json:"name"`
+	Row        int    `json:"row"`
+	Column     int    `json:"column"`
+	Horizontal bool   `json:"horizontal"`
+}
@@ -7,13 +7,24 @@ type BurnCard struct {
 	Gain         float64 `json:"gain"`
 	Inflict      float64 `json:"inflict"`
 	PlayedOption string  `json:"played_option,omitempty"`
+	// Continuous is nil for normal cards
+	Continuous *BurnContinuousEffect `json:"continuous,omitempty"`
+	TurnsLeft  int                   `json:"turns_left,omitempty"` // for continuous cards on the field
+}
+
+// BurnContinuousEffect represents the continuous effect of a Burn card
+type BurnContinuousEffect struct {
+	Type     string  `json:"type"` // STANDBY_GAIN, DAMAGE_REDUCTION or HALVE_OPPONENT_GAIN
+	Amount   float64 `json:"amount"`
+	Duration int     `json:"duration"` // number of owner's turns
 }
 
 // BurnPlayerState represents a player's state in the Burn card game
 type BurnPlayerState struct {
 	ID        string     `json:"id"`
 	LifePoint float64    `json:"life_point"`
-	Hand      []BurnCard `json:"hand"`
+	Hand      []BurnCard `json:"hand"`      // empty if the viewer is not allowed to see it
+	HandSize  int        `json:"hand_size"` // number of cards in hand, even if Hand is hidden
 	DeckSize  int        `json:"deck_size"`
 	Field     []BurnCard `json:"field"`
 	Graveyard []BurnCard `json:"graveyard"`
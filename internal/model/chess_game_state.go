@@ -0,0 +1,17 @@
+// Package model defines shared data models used across packages
+package model
+
+// ChessGameState represents the complete game state for chess
+type ChessGameState struct {
+	FEN string `json:"fen"`
+	// Board[rank][file] is the FEN letter of the piece (upper case White, lower case Black),
+	// empty string for an empty square, rank 0 is White's back rank, file 0 is the a-file
+	Board      [][]string        `json:"board"`
+	Colors     map[string]string `json:"colors"`       // Player ID -> WHITE or BLACK
+	SideToMove string            `json:"side_to_move"` // WHITE or BLACK
+	InCheck    bool              `json:"in_check"`
+	LegalMoves []string          `json:"legal_moves"` // UCI moves of the side to move
+	History    []string          `json:"history"`     // played moves in SAN
+	LastMove   string            `json:"last_move,omitempty"`
+	DrawReason string            `json:"draw_reason,omitempty"`
+}
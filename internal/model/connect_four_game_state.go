@@ -0,0 +1,13 @@
+// Package model defines shared data models used across packages
+package model
+
+// ConnectFourGameState represents the complete game state for Connect Four
+type ConnectFourGameState struct {
+	Columns int `json:"columns"`
+	Rows    int `json:"rows"`
+	// Board[row][column] is the player ID owning the disc, empty string for an empty cell,
+	// row 0 is the bottom row
+	Board [][]string `json:"board"`
+	// WinningLine is the [row, column] cells of the winning line, empty if no winner
+	WinningLine [][2]int `json:"winning_line"`
+}
@@ -2,9 +2,15 @@
 package model
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/daominah/turn_based_game/internal/core/turnbased"
 )
 
+// TimestampFormat is the ISO 8601 format of the timestamps in JSON
+const TimestampFormat = "2006-01-02T15:04:05.000"
+
 // SerializableActionLogEntry represents an action log entry in JSON format
 type SerializableActionLogEntry struct {
 	Seq       int                    `json:"seq"`
@@ -25,8 +31,81 @@ type SerializableDuel struct {
 	State        string                       `json:"state"`
 	ActionLog    []SerializableActionLogEntry `json:"action_log"`
 	PlayerColors map[string]string            `json:"player_colors"` // Player ID -> color hex code
+	Teams        []SerializableTeam           `json:"teams,omitempty"`
+	WinnerTeam   string                       `json:"winner_team,omitempty"`
+	EndReason    string                       `json:"end_reason,omitempty"`
+	Settings     DuelSettings                 `json:"settings"`
+	// Presence is set by the server: player ID -> whether the player is connected to the duel,
+	// players who never connected are absent
+	Presence map[string]SerializablePresence `json:"presence,omitempty"`
+	// Spectators is set by the server: the number of connections watching the duel without playing
+	Spectators int `json:"spectators,omitempty"`
+}
+
+// Statuses of SerializablePresence
+const (
+	PresenceConnected    = "CONNECTED"
+	PresenceDisconnected = "DISCONNECTED"
+)
+
+// SerializablePresence is the connection status of a player in a duel
+type SerializablePresence struct {
+	Status string `json:"status"` // CONNECTED or DISCONNECTED
+	Since  string `json:"since"`  // when the status changed, in TimestampFormat
+}
+
+// DuelSettings is turnbased.Settings in JSON format,
+// also used to choose the settings when creating a duel
+type DuelSettings struct {
+	// DisconnectGracePeriodSeconds is turnbased.Settings.DisconnectGracePeriod,
+	// 0 means the default when creating a duel
+	DisconnectGracePeriodSeconds int `json:"disconnect_grace_period_seconds,omitempty"`
+	// DisconnectRule is NONE, PASS or FORFEIT, empty means the default when creating a duel
+	DisconnectRule string `json:"disconnect_rule,omitempty"`
+	// AllowSpectators is turnbased.Settings.AllowSpectators, missing means true when creating a duel
+	AllowSpectators *bool `json:"allow_spectators,omitempty"`
+}
+
+// ToSettings returns the settings of a new duel, missing values are the defaults
+func (s DuelSettings) ToSettings() (turnbased.Settings, error) {
+	settings := turnbased.DefaultSettings()
+	if s.DisconnectGracePeriodSeconds < 0 {
+		return settings, fmt.Errorf("disconnect grace period must not be negative, got %d", s.DisconnectGracePeriodSeconds)
+	}
+	if s.DisconnectGracePeriodSeconds > 0 {
+		settings.DisconnectGracePeriod = time.Duration(s.DisconnectGracePeriodSeconds) * time.Second
+	}
+	rule, err := turnbased.ParseDisconnectRule(s.DisconnectRule)
+	if err != nil {
+		return settings, err
+	}
+	settings.DisconnectRule = rule
+	if s.AllowSpectators != nil {
+		settings.AllowSpectators = *s.AllowSpectators
+	}
+	return settings, nil
+}
+
+// FromSettings converts turnbased.Settings to DuelSettings
+func FromSettings(settings turnbased.Settings) DuelSettings {
+	return DuelSettings{
+		DisconnectGracePeriodSeconds: int(settings.DisconnectGracePeriod / time.Second),
+		DisconnectRule:               string(settings.DisconnectRule),
+		AllowSpectators:              &settings.AllowSpectators,
+	}
 }
 
+// SerializableTeam represents a team of players in JSON format
+type SerializableTeam struct {
+	ID      string   `json:"id"`
+	Players []string `json:"players"`
+}
+
+// PlayerColorPalette is the colors assigned to players by order in the duel:
+// Blue, Purple, Teal, Pink. Green, red, brown and grey are avoided because the UI uses them.
+// Duels with more players reuse the palette from the start.
+var PlayerColorPalette = []string{"#007bff", "#6f42c1", "#17a2b8", "#e83e8c"}
+
 // FromDuel converts a turnbased.Duel to SerializableDuel
 func FromDuel(duel *turnbased.Duel) SerializableDuel {
 	players := make([]string, len(duel.Players))
@@ -38,7 +117,7 @@ func FromDuel(duel *turnbased.Duel) SerializableDuel {
 	actionLog := make([]SerializableActionLogEntry, len(duel.ActionLog))
 	for i, entry := range duel.ActionLog {
 		// Format timestamp as ISO 8601: 2006-01-02T15:04:05.999
-		timestamp := entry.Timestamp.Format("2006-01-02T15:04:05.000")
+		timestamp := entry.Timestamp.Format(TimestampFormat)
 		actionLog[i] = SerializableActionLogEntry{
 			Seq:       entry.Seq,
 			Timestamp: timestamp,
@@ -48,13 +127,19 @@ func FromDuel(duel *turnbased.Duel) SerializableDuel {
 		}
 	}
 
-	// Assign player colors consistently: first player (by order in duel) = Blue, second = Purple
+	// Assign player colors consistently by order in duel: first player = Blue, second = Purple, ...
 	playerColors := make(map[string]string)
-	if len(players) > 0 {
-		playerColors[players[0]] = "#007bff" // Blue
+	for i, pid := range players {
+		playerColors[pid] = PlayerColorPalette[i%len(PlayerColorPalette)]
 	}
-	if len(players) > 1 {
-		playerColors[players[1]] = "#6f42c1" // Purple
+
+	var teams []SerializableTeam
+	for _, team := range duel.Teams {
+		teamPlayers := make([]string, len(team.Players))
+		for i, pid := range team.Players {
+			teamPlayers[i] = string(pid)
+		}
+		teams = append(teams, SerializableTeam{ID: string(team.ID), Players: teamPlayers})
 	}
 
 	return SerializableDuel{
@@ -66,5 +151,9 @@ func FromDuel(duel *turnbased.Duel) SerializableDuel {
 		State:        string(duel.State),
 		ActionLog:    actionLog,
 		PlayerColors: playerColors,
+		Teams:        teams,
+		WinnerTeam:   string(duel.WinnerTeam),
+		EndReason:    duel.EndReason,
+		Settings:     FromSettings(duel.Settings),
 	}
 }
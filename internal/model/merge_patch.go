@@ -0,0 +1,92 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// DiffMergePatch returns the JSON merge patch (RFC 7386) that turns the JSON document
+// before into after: changed object members are patched recursively, removed members are null,
+// other values (including arrays) are replaced whole. A member that becomes null in after
+// is removed by the patch, the two are the same for our models.
+func DiffMergePatch(before, after []byte) ([]byte, error) {
+	b, err := decodeJSON(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := decodeJSON(after)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(diffValue(b, a))
+}
+
+// ApplyMergePatch applies the JSON merge patch (RFC 7386) to the JSON document
+func ApplyMergePatch(doc, patch []byte) ([]byte, error) {
+	d, err := decodeJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decodeJSON(patch)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(applyValue(d, p))
+}
+
+// decodeJSON keeps numbers as json.Number, so they are compared and re-encoded exactly
+func decodeJSON(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func diffValue(before, after any) any {
+	b, bIsObject := before.(map[string]any)
+	a, aIsObject := after.(map[string]any)
+	if !bIsObject || !aIsObject {
+		return after
+	}
+	patch := make(map[string]any)
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			patch[key] = nil
+		}
+	}
+	for key, av := range a {
+		bv, ok := b[key]
+		if ok && reflect.DeepEqual(bv, av) {
+			continue
+		}
+		if !ok {
+			patch[key] = av
+			continue
+		}
+		patch[key] = diffValue(bv, av)
+	}
+	return patch
+}
+
+func applyValue(doc, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	d, ok := doc.(map[string]any)
+	if !ok {
+		d = make(map[string]any)
+	}
+	for key, pv := range p {
+		if pv == nil {
+			delete(d, key)
+			continue
+		}
+		d[key] = applyValue(d[key], pv)
+	}
+	return d
+}
@@ -0,0 +1,20 @@
+// Package model defines shared data models used across packages
+package model
+
+// RockPaperScissorsRound is a revealed round
+type RockPaperScissorsRound struct {
+	Throws map[string]string `json:"throws"` // player ID -> ROCK, PAPER or SCISSORS
+	Winner string            `json:"winner"` // empty if tied
+}
+
+// RockPaperScissorsGameState represents the game state for rock-paper-scissors as seen by one viewer
+type RockPaperScissorsGameState struct {
+	BestOf     int                      `json:"best_of"`
+	WinsNeeded int                      `json:"wins_needed"`
+	Rounds     []RockPaperScissorsRound `json:"rounds"`
+	Wins       map[string]int           `json:"wins"`
+	// Thrown tells which players have committed a throw in the round in progress
+	Thrown map[string]bool `json:"thrown"`
+	// MyThrow is the viewer's own throw in the round in progress, empty if not thrown yet
+	MyThrow string `json:"my_throw,omitempty"`
+}
@@ -0,0 +1,32 @@
+// Package model defines shared data models used across packages
+package model
+
+// SpadesTrickCard is a card played to a trick
+type SpadesTrickCard struct {
+	PlayerID string `json:"player_id"`
+	Card     string `json:"card"`
+}
+
+// SpadesPlayer represents a player as seen by the viewer of the state,
+// Hand is only filled for the viewer themselves, others only show HandSize
+type SpadesPlayer struct {
+	ID       string   `json:"id"`
+	Team     string   `json:"team"`
+	Hand     []string `json:"hand"`
+	HandSize int      `json:"hand_size"`
+	// LegalCards is the cards the viewer can play now, empty if it is not their turn
+	LegalCards []string `json:"legal_cards"`
+}
+
+// SpadesGameState represents the game state for Spades as seen by one viewer
+type SpadesGameState struct {
+	HandNumber   int               `json:"hand_number"`
+	HandsToPlay  int               `json:"hands_to_play"`
+	Dealer       string            `json:"dealer"`
+	Players      []SpadesPlayer    `json:"players"`
+	Trick        []SpadesTrickCard `json:"trick"`
+	LastTrick    []SpadesTrickCard `json:"last_trick"`
+	SpadesBroken bool              `json:"spades_broken"`
+	TricksWon    map[string]int    `json:"tricks_won"` // team ID -> tricks won in the current hand
+	Scores       map[string]int    `json:"scores"`     // team ID -> total points
+}
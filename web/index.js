@@ -63,13 +63,27 @@ async function callHelloApi() {
 }
 
 // WebSocket connection
+// PROTOCOL_VERSION is the WebSocket protocol version this page speaks, sent in hello
+const PROTOCOL_VERSION = 1;
 let ws = null;
+// lastRequestId numbers the sent messages, the server's ack or error carries the request_id
+let lastRequestId = 0;
+// pendingRequests is the sent messages not answered yet, request_id -> message
+const pendingRequests = new Map();
 let isConnecting = false;
 let reconnectTimeout = null;
 let currentDuelId = null;
 let currentPlayerId = null;
+// spectating is true when watching currentDuelId without playing, currentPlayerId is then null
+let spectating = false;
 let currentGameState = null;
 let previousGameState = null;
+// lastSeq is the seq of the last message of the current duel, sent when rejoining after a reconnection
+// so that the server replays the missed messages
+let lastSeq = 0;
+// resyncing is true after a state_delta did not apply, until the full state answering the resync,
+// which can have the seq of that delta
+let resyncing = false;
 
 /**
  * Connects to WebSocket server
@@ -107,16 +121,33 @@ function connectWebSocket() {
 			log(`WebSocket connected in ${connectDuration}ms`);
 			updateConnectionStatus("Connected", WS_URL);
 
+			// Negotiate the protocol before any other message
+			// With "delta", state changes come as state_delta, applied to currentGameState
+			ws.send(JSON.stringify({ type: "hello", protocol_version: PROTOCOL_VERSION, features: ["delta"] }));
+
 			// If we have a duel/player, rejoin automatically
 			if (currentDuelId && currentPlayerId) {
-				log("Rejoining duel after reconnection...");
-				joinDuel(currentDuelId, currentPlayerId);
+				log(`Rejoining duel after reconnection, resuming after seq ${lastSeq}...`);
+				joinDuel(currentDuelId, currentPlayerId, lastSeq);
+			} else if (currentDuelId && spectating) {
+				log(`Spectating again after reconnection, resuming after seq ${lastSeq}...`);
+				spectateDuel(currentDuelId, lastSeq);
 			}
 		};
 
 		ws.onmessage = function (event) {
 			try {
 				const message = JSON.parse(event.data);
+				if (message.seq) {
+					// a message fanned out while resuming can arrive twice,
+					// but the answer to a resync is a new state even with the last seq
+					const resyncAnswer = resyncing && message.type === "state_update" && message.seq === lastSeq;
+					if (message.seq <= lastSeq && !resyncAnswer) {
+						log(`Ignoring already received message seq ${message.seq}`);
+						return;
+					}
+					lastSeq = message.seq;
+				}
 				handleServerMessage(message);
 			} catch (err) {
 				log("Error parsing server message:", err);
@@ -162,7 +193,22 @@ function handleServerMessage(message) {
 	log("Received server message:", message);
 
 	switch (message.type) {
+		case "state_delta": {
+			const update = applyStateDelta(currentGameState, message);
+			if (!update) {
+				// our state is not the base of the delta (e.g. a missed message), ask for the full state
+				log(`State delta ${message.base_version} -> ${message.version} does not apply, resyncing`);
+				resyncing = true;
+				ws.send(JSON.stringify({ type: "resync" }));
+				break;
+			}
+			handleServerMessage(update);
+			break;
+		}
 		case "state_update":
+			if (message.duel) {
+				resyncing = false;
+			}
 			// Detect if a card was just played by checking the last action in the log
 			// Only show animation for PLAY_CARD actions, not END_TURN
 			if (message.duel && message.duel.action_log && message.duel.action_log.length > 0) {
@@ -173,9 +219,17 @@ function handleServerMessage(message) {
 					const playerId = lastAction.player_id;
 					const player = message.game_state.players[playerId];
 
-					if (player && player.graveyard && player.graveyard.length > 0) {
+					// A continuous card stays on the field, other cards go to the graveyard
+					let lastCard = null;
+					if (lastAction.data && lastAction.data.option === 'CONTINUOUS') {
+						if (player && player.field && player.field.length > 0) {
+							lastCard = player.field[player.field.length - 1];
+						}
+					} else if (player && player.graveyard && player.graveyard.length > 0) {
 						// Get the last card from this player's graveyard
-						const lastCard = player.graveyard[player.graveyard.length - 1];
+						lastCard = player.graveyard[player.graveyard.length - 1];
+					}
+					if (lastCard) {
 						lastPlayedCard = lastCard;
 
 						// Clear after 8 seconds
@@ -213,16 +267,81 @@ function handleServerMessage(message) {
 				}
 			}
 			break;
-		case "error":
-			log("Server error:", message.error);
+		case "presence": {
+			// a player connected, disconnected or reconnected, keep the duel presence up to date
+			const event = message.presence;
+			log(`Player ${event.player_id} ${event.event}`);
+			if (currentGameState && currentGameState.duel && currentGameState.duel.id === event.duel_id) {
+				currentGameState.duel.presence = { ...(currentGameState.duel.presence || {}), [event.player_id]: event.presence };
+				updateGameUI(currentGameState);
+			}
+			break;
+		}
+		case "spectators": {
+			// a spectator started or stopped watching the duel
+			const event = message.spectators;
+			if (currentGameState && currentGameState.duel && currentGameState.duel.id === event.duel_id) {
+				currentGameState.duel.spectators = event.count;
+				updateGameUI(currentGameState);
+			}
+			break;
+		}
+		case "welcome":
+			log(`Server speaks protocol version ${message.hello.protocol_version}, games:`, message.hello.games);
+			break;
+		case "ack":
+			pendingRequests.delete(message.request_id);
+			break;
+		case "error": {
+			const request = pendingRequests.get(message.request_id);
+			pendingRequests.delete(message.request_id);
+			log(`Server error ${message.code}:`, message.error, request ? `(request ${message.request_id}: ${request.type})` : "");
 			// Don't use alert for errors, just log them
-			console.error("Server error:", message.error);
+			console.error("Server error:", message.code, message.error, request);
 			break;
+		}
 		default:
 			log("Unknown message type:", message.type);
 	}
 }
 
+/**
+ * Applies a state_delta to the last state_update, returns the new state_update
+ * or null if the delta is not based on that state's version
+ */
+function applyStateDelta(state, message) {
+	if (!state || !state.version || state.version !== message.base_version || !message.delta) {
+		return null;
+	}
+	const { action_log: actionLog, ...duel } = state.duel;
+	const patchedDuel = applyMergePatch(duel, message.delta.duel_patch);
+	patchedDuel.action_log = (actionLog || []).concat(message.delta.new_log_entries || []);
+	return {
+		type: "state_update",
+		version: message.version,
+		duel: patchedDuel,
+		game_state: applyMergePatch(state.game_state, message.delta.game_state_patch),
+	};
+}
+
+/**
+ * Applies a JSON merge patch (RFC 7386), the target is not modified
+ */
+function applyMergePatch(target, patch) {
+	if (patch === null || typeof patch !== "object" || Array.isArray(patch)) {
+		return patch;
+	}
+	const result = (target !== null && typeof target === "object" && !Array.isArray(target)) ? { ...target } : {};
+	for (const [key, value] of Object.entries(patch)) {
+		if (value === null) {
+			delete result[key];
+		} else {
+			result[key] = applyMergePatch(result[key], value);
+		}
+	}
+	return result;
+}
+
 /**
  * Sends a message to the server
  */
@@ -232,6 +351,9 @@ function sendMessage(message) {
 		return;
 	}
 
+	lastRequestId++;
+	message.request_id = String(lastRequestId);
+	pendingRequests.set(message.request_id, message);
 	log("Sending message:", message);
 	ws.send(JSON.stringify(message));
 }
@@ -249,9 +371,17 @@ function createDuel() {
 		game: game,
 		players: [player1, player2]
 	};
+	// Player 2 can be played by a server-side bot, it acts automatically on its turns
+	const botKind = document.getElementById("player2BotSelect").value;
+	if (botKind) {
+		message.bots = { [player2]: botKind };
+	}
 
 	sendMessage(message);
 	currentPlayerId = player1; // Assume first player is this client
+	spectating = false;
+	lastSeq = 0;
+	resyncing = false;
 
 	// Hide Create Duel section after creating
 	hideCreateDuelSection();
@@ -273,9 +403,9 @@ function hideCreateDuelSection() {
 }
 
 /**
- * Joins an existing duel
+ * Joins an existing duel, resumeSeq is the last received seq when rejoining after a reconnection
  */
-function joinDuel(duelId, playerId) {
+function joinDuel(duelId, playerId, resumeSeq) {
 	// Allow passing parameters directly (for URL join) or reading from URL params
 	if (!duelId || !playerId) {
 		const urlParams = new URLSearchParams(window.location.search);
@@ -293,10 +423,39 @@ function joinDuel(duelId, playerId) {
 		duel_id: duelId,
 		player_id: playerId
 	};
+	if (resumeSeq) {
+		message.last_seq = resumeSeq;
+	} else {
+		lastSeq = 0;
+		resyncing = false;
+	}
 
 	sendMessage(message);
 	currentDuelId = duelId;
 	currentPlayerId = playerId;
+	spectating = false;
+}
+
+/**
+ * Watches an existing duel without playing, the hands of all players are hidden,
+ * resumeSeq is the last received seq when spectating again after a reconnection
+ */
+function spectateDuel(duelId, resumeSeq) {
+	const message = {
+		type: "spectate",
+		duel_id: duelId
+	};
+	if (resumeSeq) {
+		message.last_seq = resumeSeq;
+	} else {
+		lastSeq = 0;
+		resyncing = false;
+	}
+
+	sendMessage(message);
+	currentDuelId = duelId;
+	currentPlayerId = null;
+	spectating = true;
 }
 
 /**
@@ -382,12 +541,25 @@ function updateGameUI(stateMessage) {
                     </button>`;
 			});
 		}
+		if (duel.settings && duel.settings.allow_spectators) {
+			const spectateUrl = generateSpectateUrl(duel.id);
+			joinUrlsHTML += `
+                <p style="margin-top: 5px;"><strong>Spectate:</strong></p>
+                <input type="text" id="spectateUrl" readonly value="${spectateUrl}"
+                    style="font-size: 0.7em; padding: 3px 4px; width: 100%; word-break: break-all; overflow-wrap: break-word; border: 1px solid #ccc; border-radius: 3px; background: #fff; color: #000; box-sizing: border-box; margin-bottom: 3px;"
+                    onclick="this.select();">
+                <button onclick="copyToClipboard('spectateUrl')"
+                    style="padding: 3px 6px; font-size: 0.65em; white-space: nowrap; cursor: pointer; background: #6c757d; color: white; border: none; border-radius: 3px; display: flex; align-items: center; gap: 3px; width: 100%; justify-content: center; margin-bottom: 5px;">
+                    <span style="font-size: 0.75em;">📋</span><span>Copy</span>
+                </button>`;
+		}
 
 		duelInfoDiv.innerHTML = `
 			<p><strong>Duel ID:</strong> ${duel.id}</p>
 			<p><strong>Turn:</strong> ${duel.turn}</p>
 			<p><strong>Current Player:</strong> ${duel.turn_player}</p>
 			<p><strong>State:</strong> ${duel.state}</p>
+			<p><strong>Spectators:</strong> ${duel.spectators || 0}${spectating ? " (you are spectating)" : ""}</p>
 			${duel.winner ? `<p><strong>Winner:</strong> ${duel.winner}</p>` : ""}
 			${joinUrlsHTML}
 		`;
@@ -437,6 +609,9 @@ function updateGameUI(stateMessage) {
 	let turnInfoText = `Turn ${duel.turn}`;
 	if (duel.state === 'END' && duel.winner) {
 		turnInfoText = `Duel Ended - Winner: ${duel.winner}`;
+		if (duel.end_reason === 'abandoned') {
+			turnInfoText += ' (by abandonment)';
+		}
 	} else {
 		turnInfoText += ` - Current Player: ${duel.turn_player}`;
 	}
@@ -462,10 +637,12 @@ function updateGameUI(stateMessage) {
 				</div>
 				<div class="player-grid-cell top-mid">
 					<div class="player-hand">
-						${topPlayer.hand.map(() => '<div class="card" style="opacity: 0.3;"><div style="text-align: center; padding-top: 50px;">?</div></div>').join("")}
+						${Array.from({ length: topPlayer.hand_size }, () => '<div class="card" style="opacity: 0.3;"><div style="text-align: center; padding-top: 50px;">?</div></div>').join("")}
 					</div>
 				</div>
-				<div class="player-grid-cell top-right"></div>
+				<div class="player-grid-cell top-right">
+					${renderField(topPlayer.field)}
+				</div>
 				<div class="player-grid-cell bot-left">
 					<div class="graveyard">
 						<div class="graveyard-label">GY: ${topPlayer.graveyard ? topPlayer.graveyard.length : 0}</div>
@@ -480,7 +657,7 @@ function updateGameUI(stateMessage) {
 				<div class="player-grid-cell bot-mid"></div>
 				<div class="player-grid-cell bot-right">
 					<div class="player-info">
-						<span class="player-name">${topPlayerId}${isTopTurn ? ' (Turn)' : ''}</span>
+						<span class="player-name">${topPlayerId}${isTopTurn ? ' (Turn)' : ''}${presenceLabel(duel, topPlayerId)}</span>
 						<br>
 						<span class="player-lp">LP: ${Math.floor(topPlayer.life_point)}</span>
 					</div>
@@ -497,6 +674,7 @@ function updateGameUI(stateMessage) {
 					<p><strong>Card Played</strong></p>
 					<p class="card-option ${lastPlayedCard.played_option === 'GAIN' ? 'chosen gain-option' : ''}">Gain: ${lastPlayedCard.gain}</p>
 					<p class="card-option ${lastPlayedCard.played_option === 'INFLICT' ? 'chosen inflict-option' : ''}">Inflict: ${lastPlayedCard.inflict}</p>
+					${lastPlayedCard.continuous ? `<p class="card-option ${lastPlayedCard.played_option === 'CONTINUOUS' ? 'chosen continuous-option' : ''}">${describeContinuousEffect(lastPlayedCard.continuous)}</p>` : ''}
 				</div>
 			` : '<p style="color: #6c757d;">Play zone: cards appear here when played</p>'}
 		</div>
@@ -508,12 +686,14 @@ function updateGameUI(stateMessage) {
 			<div class="player-grid">
 				<div class="player-grid-cell top-left">
 					<div class="player-info">
-						<span class="player-name">${bottomPlayerId}${isBottomCurrent ? ' (You)' : ''}${isBottomTurn ? ' (Turn)' : ''}</span>
+						<span class="player-name">${bottomPlayerId}${isBottomCurrent ? ' (You)' : ''}${isBottomTurn ? ' (Turn)' : ''}${presenceLabel(duel, bottomPlayerId)}</span>
 						<br>
 						<span class="player-lp">LP: ${Math.floor(bottomPlayer.life_point)}</span>
 					</div>
 				</div>
-				<div class="player-grid-cell top-mid"></div>
+				<div class="player-grid-cell top-mid">
+					${renderField(bottomPlayer.field)}
+				</div>
 				<div class="player-grid-cell top-right">
 					<div class="graveyard">
 						<div class="graveyard-label">GY: ${bottomPlayer.graveyard ? bottomPlayer.graveyard.length : 0}</div>
@@ -548,6 +728,13 @@ function updateGameUI(stateMessage) {
 									${isBottomTurn && isBottomCurrent ? '' : 'disabled'}>
 									Inflict ${card.inflict}
 								</button>
+								${card.continuous ? `
+								<button class="card-button continuous"
+									onclick="playCard('${card.unique_card_id}', 'CONTINUOUS')"
+									${isBottomTurn && isBottomCurrent ? '' : 'disabled'}>
+									${describeContinuousEffect(card.continuous)}
+								</button>
+								` : ''}
 							</div>
 						`).join("")}
 					</div>
@@ -576,6 +763,8 @@ function updateGameUI(stateMessage) {
 	if (duel.id && currentPlayerId) {
 		const newUrl = `${window.location.pathname}?duelId=${encodeURIComponent(duel.id)}&playerId=${encodeURIComponent(currentPlayerId)}`;
 		window.history.replaceState({}, '', newUrl);
+	} else if (duel.id && spectating) {
+		window.history.replaceState({}, '', generateSpectateUrl(duel.id));
 	}
 }
 
@@ -648,6 +837,14 @@ function updateDuelLogDisplay() {
 			const gainColor = option === 'GAIN' ? 'color: #28a745; font-weight: bold;' : '';
 			const inflictColor = option === 'INFLICT' ? 'color: #dc3545; font-weight: bold;' : '';
 			actionText = `[<span style="${gainColor}">Gain ${gain}</span>] [<span style="${inflictColor}">Inflict ${inflict}</span>]`;
+			if (option === 'CONTINUOUS') {
+				const effect = { type: entry.data.effect, amount: entry.data.amount, duration: entry.data.duration };
+				actionText += ` [<span style="color: #fd7e14; font-weight: bold;">${describeContinuousEffect(effect)}</span>]`;
+			}
+		} else if ((entry.action === 'CONTINUOUS_EFFECT' || entry.action === 'EXPIRE_CARD') && entry.data) {
+			const effect = { type: entry.data.effect, amount: entry.data.amount };
+			const verb = entry.action === 'EXPIRE_CARD' ? 'Expired' : 'Effect';
+			actionText = `${verb}: ${describeContinuousEffect(effect)}`;
 		} else {
 			actionText = `Action: ${entry.action}`;
 		}
@@ -664,6 +861,58 @@ function updateDuelLogDisplay() {
 	logDiv.scrollTop = logDiv.scrollHeight;
 }
 
+/**
+ * Describes a continuous effect in short text, e.g. "+200 LP/turn (3T)"
+ */
+function describeContinuousEffect(effect) {
+	let text = '';
+	switch (effect.type) {
+		case 'STANDBY_GAIN':
+			text = `+${effect.amount} LP/turn`;
+			break;
+		case 'DAMAGE_REDUCTION':
+			text = `-${effect.amount} damage`;
+			break;
+		case 'HALVE_OPPONENT_GAIN':
+			text = 'Halve opp. Gain';
+			break;
+		default:
+			text = effect.type;
+	}
+	if (effect.duration) {
+		text += ` (${effect.duration}T)`;
+	}
+	return text;
+}
+
+/**
+ * Returns " (Disconnected)" if the player lost their connection to the duel, players who never
+ * connected (e.g. bots) have no presence
+ */
+function presenceLabel(duel, playerId) {
+	const presence = duel.presence && duel.presence[playerId];
+	return presence && presence.status === 'DISCONNECTED' ? ' (Disconnected)' : '';
+}
+
+/**
+ * Renders continuous cards on a player's field with their remaining turns
+ */
+function renderField(field) {
+	if (!field || field.length === 0) {
+		return '';
+	}
+	return `
+		<div class="field">
+			${field.map(card => `
+				<div class="field-card">
+					<div>${card.continuous ? describeContinuousEffect({ type: card.continuous.type, amount: card.continuous.amount }) : ''}</div>
+					<div>${card.turns_left} turn(s) left</div>
+				</div>
+			`).join("")}
+		</div>
+	`;
+}
+
 /**
  * Generates a join URL for the current duel
  */
@@ -672,6 +921,14 @@ function generateJoinUrl(duelId, playerId) {
 	return `${baseUrl}?duelId=${encodeURIComponent(duelId)}&playerId=${encodeURIComponent(playerId)}`;
 }
 
+/**
+ * Generates the URL to watch a duel without playing
+ */
+function generateSpectateUrl(duelId) {
+	const baseUrl = window.location.origin + window.location.pathname;
+	return `${baseUrl}?duelId=${encodeURIComponent(duelId)}&spectate=1`;
+}
+
 /**
  * Updates connection status display
  * Shows connection status with server address when connected
@@ -713,6 +970,7 @@ window.onload = function () {
 	const urlParams = new URLSearchParams(window.location.search);
 	const duelIdFromUrl = urlParams.get('duelId');
 	const playerIdFromUrl = urlParams.get('playerId');
+	const spectateFromUrl = urlParams.get('spectate') === '1';
 
 	// Set default player IDs with random suffixes
 	const player1Input = document.getElementById("player1Input");
@@ -725,15 +983,19 @@ window.onload = function () {
 	}
 
 	// If join URL parameters exist, hide Create Duel section and auto-join
-	if (duelIdFromUrl && playerIdFromUrl) {
-		// Hide Create Duel section for join users
+	if (duelIdFromUrl && (playerIdFromUrl || spectateFromUrl)) {
+		// Hide Create Duel section for join users and spectators
 		hideCreateDuelSection();
 
-		// Auto-join after WebSocket is connected
+		// Auto-join (or spectate) after WebSocket is connected
 		const checkConnection = setInterval(() => {
 			if (ws && ws.readyState === WebSocket.OPEN) {
 				clearInterval(checkConnection);
-				joinDuel(duelIdFromUrl, playerIdFromUrl);
+				if (playerIdFromUrl) {
+					joinDuel(duelIdFromUrl, playerIdFromUrl);
+				} else {
+					spectateDuel(duelIdFromUrl);
+				}
 			}
 		}, 100);
 